@@ -24,6 +24,63 @@ const (
 	MachineDeletionOnCloudProviderReason     = "MachineDeletionOnCloudProviderCausesNewNodeName"
 	MachineDeletionOnBareMetalProviderReason = "MachineDeletionOnBareMetalProviderKeepsNodeName"
 	MachineDeletionOnUndefinedProviderReason = "MachineDeletionUndefinedNodeNameExpectation"
+
+	// WaitingConditionType is True while the remediation waits for other remediations to complete before deleting
+	// the Machine, because the maximum number of Machines being deleted at the same time is reached
+	WaitingConditionType        = "Waiting"
+	MaxInFlightReachedReason    = "MaxInFlightReached"
+	MaxInFlightNotReachedReason = "MaxInFlightNotReached"
+
+	// DryRunConditionType is True when the remediation ran in dry run mode: all the checks passed, but the Machine
+	// was not deleted
+	DryRunConditionType          = "DryRun"
+	MachineDeletionSkippedReason = "MachineDeletionSkipped"
+
+	// AwaitingApprovalConditionType is True while the remediation waits for the approval of the Machine deletion
+	AwaitingApprovalConditionType = "AwaitingApproval"
+	ApprovalRequestedReason       = "ApprovalRequested"
+	ApprovedReason                = "Approved"
+	AutoApprovedReason            = "AutoApproved"
+
+	// DeferredConditionType is True while the remediation waits for the next maintenance window to delete the Machine
+	DeferredConditionType          = "Deferred"
+	OutsideMaintenanceWindowReason = "OutsideMaintenanceWindow"
+	InsideMaintenanceWindowReason  = "InsideMaintenanceWindow"
+
+	// MachineDeletionStuckConditionType is True while the Machine deletion did not complete within the stuck deletion
+	// timeout
+	MachineDeletionStuckConditionType = "MachineDeletionStuck"
+	MachineDeletionTimedOutReason     = "MachineDeletionTimedOut"
+	MachineDeletionCompletedReason    = "MachineDeletionCompleted"
+)
+
+// RemediationPhase is a brief summary of the remediation progress
+type RemediationPhase string
+
+const (
+	// RemediationPhaseStarted means that the remediation is looking for the Machine to delete
+	RemediationPhaseStarted RemediationPhase = "Started"
+	// RemediationPhaseWaiting means that the remediation waits for other remediations to complete
+	RemediationPhaseWaiting RemediationPhase = "Waiting"
+	// RemediationPhaseAwaitingApproval means that the remediation waits for the approval of the Machine deletion
+	RemediationPhaseAwaitingApproval RemediationPhase = "AwaitingApproval"
+	// RemediationPhaseDeferred means that the remediation waits for the next maintenance window to delete the Machine
+	RemediationPhaseDeferred RemediationPhase = "Deferred"
+	// RemediationPhaseDraining means that MDR is draining the Node before deleting the Machine
+	RemediationPhaseDraining RemediationPhase = "Draining"
+	// RemediationPhaseMachineDeletionRequested means that the Machine deletion was requested, but the Machine still exists
+	RemediationPhaseMachineDeletionRequested RemediationPhase = "MachineDeletionRequested"
+	// RemediationPhaseWaitingForReplacement means that the Machine is gone and the remediation waits for its replacement
+	RemediationPhaseWaitingForReplacement RemediationPhase = "WaitingForReplacement"
+	// RemediationPhaseFallbackRemediation means that the Machine could not be deleted, and the remediation waits for
+	// the remediation created with the fallback remediation template to complete
+	RemediationPhaseFallbackRemediation RemediationPhase = "FallbackRemediation"
+	// RemediationPhaseDryRun means that the remediation completed in dry run mode, without deleting the Machine
+	RemediationPhaseDryRun RemediationPhase = "DryRun"
+	// RemediationPhaseSucceeded means that the remediation completed successfully
+	RemediationPhaseSucceeded RemediationPhase = "Succeeded"
+	// RemediationPhaseFailed means that the remediation failed or was skipped
+	RemediationPhaseFailed RemediationPhase = "Failed"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -31,25 +88,429 @@ const (
 
 // MachineDeletionRemediationSpec defines the desired state of MachineDeletionRemediation
 type MachineDeletionRemediationSpec struct {
-	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	// PollInterval is the interval between two checks of the Machine deletion and Node replacement progress.
+	// If not set, the operator's default poll interval is used (30s unless configured otherwise).
+	// Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$"
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	PollInterval *metav1.Duration `json:"pollInterval,omitempty"`
+
+	// WaitForNodeReplacement defines whether the remediation succeeds only once the Node of the Machine created to
+	// replace the deleted one is Ready (true), or as soon as the Machine is deleted (false).
+	// +kubebuilder:default:=true
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	WaitForNodeReplacement *bool `json:"waitForNodeReplacement,omitempty"`
+
+	// NodeRestorationTimeout is the maximum time to wait for the Nodes to be replaced after the Machine deletion was
+	// requested. When it expires, the remediation fails. If not set, MDR waits until the remediation is stopped by NHC.
+	// Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$"
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	NodeRestorationTimeout *metav1.Duration `json:"nodeRestorationTimeout,omitempty"`
+
+	// DeletionPropagation is the propagation policy used to delete the Machine.
+	// Valid values are "Background", "Foreground" and "Orphan".
+	// +kubebuilder:default:="Background"
+	// +kubebuilder:validation:Enum=Background;Foreground;Orphan
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DeletionPropagation *metav1.DeletionPropagation `json:"deletionPropagation,omitempty"`
 
+	// Drain, if set, makes MDR cordon and drain the Node before deleting the Machine, evicting its Pods while
+	// respecting their PodDisruptionBudgets. Otherwise, draining the Node is left to the Machine controller.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Drain *DrainSpec `json:"drain,omitempty"`
+
+	// PreTerminateHook, if set, makes MDR add its own preTerminate lifecycle hook to the Machine before deleting it,
+	// so that the instance is not terminated until MDR completed its post-drain work.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	PreTerminateHook *PreTerminateHookSpec `json:"preTerminateHook,omitempty"`
+
+	// DryRun, if true, makes MDR run all the checks of the remediation without deleting the Machine: the Node is not
+	// drained, and the remediation completes with the DryRun condition reporting the Machine which would be deleted.
+	// The Succeeded condition stays Unknown, so that NodeHealthCheck does not escalate to its next remediation before
+	// its own timeout.
+	// Dry run can also be enabled for all the remediations with the dryRun field of the operator configuration.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// Approval, if set, makes MDR wait for an approval before draining the Node and deleting the Machine. The deletion
+	// is approved by setting the "machine-deletion-remediation.medik8s.io/approved" annotation on the remediation, or
+	// by creating a MachineDeletionRemediationApproval referencing it.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Approval *ApprovalSpec `json:"approval,omitempty"`
+
+	// MaintenanceWindows, if set, restrict the Machine deletion to the time windows they define. Outside of them, the
+	// remediation is deferred until the next window starts. The windows of the operator configuration selecting the
+	// Machine owner apply as well.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+
+	// StuckDeletion, if set, makes MDR detect a Machine deletion which did not complete within its timeout, report
+	// what holds the Machine with the MachineDeletionStuck condition, and apply the configured escalation steps.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	StuckDeletion *StuckDeletionSpec `json:"stuckDeletion,omitempty"`
+
+	// FallbackRemediationTemplate, if set, references the remediation template of another remediator (e.g. a
+	// SelfNodeRemediationTemplate) used to remediate the Node when its Machine cannot be deleted, because it is not
+	// found, it has no controller owner, or its owner's kind is not enabled. MDR creates a remediation from the
+	// template for the same Node, and the remediation succeeds or fails with it.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	FallbackRemediationTemplate *FallbackRemediationTemplateReference `json:"fallbackRemediationTemplate,omitempty"`
+}
+
+// DrainSpec configures how MDR drains the Node before deleting the Machine
+type DrainSpec struct {
+	// GracePeriod is the termination grace period given to the evicted Pods. If not set, the Pods' own termination
+	// grace period is used.
+	// Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$"
+	// +optional
+	GracePeriod *metav1.Duration `json:"gracePeriod,omitempty"`
+
+	// ForceAfter is the time after the drain start when the remaining Pods, e.g. whose eviction is blocked by a
+	// PodDisruptionBudget, are deleted immediately instead, without grace period. Pods which are still terminating on a
+	// NotReady Node, e.g. because of a finalizer, do not block the drain anymore. If not set, MDR waits until all the
+	// Pods are evicted.
+	// Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$"
+	// +optional
+	ForceAfter *metav1.Duration `json:"forceAfter,omitempty"`
+}
+
+// PreTerminateHookSpec configures the preTerminate lifecycle hook MDR adds to the Machine
+type PreTerminateHookSpec struct {
+	// ReleaseTimeout is the maximum time to hold the Machine termination, from the time the hook is added to the
+	// Machine. Once the Machine is drained, MDR waits for an external system to release the hook by setting the
+	// "machine-deletion-remediation.medik8s.io/release-pre-terminate-hook" annotation on the remediation. When the
+	// timeout expires, MDR removes the hook even if the Machine is not drained. If not set, MDR removes the hook as
+	// soon as the Machine is drained.
+	// Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$"
+	// +optional
+	ReleaseTimeout *metav1.Duration `json:"releaseTimeout,omitempty"`
+}
+
+// ApprovalSpec configures the approval of the Machine deletion
+type ApprovalSpec struct {
+	// AutoApproveAfter is the time after which the Machine deletion is approved automatically, if it was not approved
+	// yet. If not set, MDR waits for the approval until the remediation is stopped by NHC.
+	// Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$"
+	// +optional
+	AutoApproveAfter *metav1.Duration `json:"autoApproveAfter,omitempty"`
+}
+
+// MaintenanceWindow is a recurring time window in which Machines can be deleted
+type MaintenanceWindow struct {
+	// Schedule is the cron expression of the window starts, with the minute, hour, day of month, month and day of week
+	// fields, e.g. "0 2 * * SAT" for every Saturday at 2:00.
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+
+	// Duration is how long the window lasts after each start.
+	// Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$"
+	Duration metav1.Duration `json:"duration"`
+
+	// TimeZone is the IANA time zone of the schedule, e.g. "Europe/Rome". If not set, UTC is used.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// StuckDeletionSpec configures the detection of stuck Machine deletions
+type StuckDeletionSpec struct {
+	// Timeout is the time after the Machine deletion started when the deletion is considered stuck, if the Machine
+	// still exists.
+	// Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$"
+	Timeout metav1.Duration `json:"timeout"`
+
+	// Escalations are the steps MDR applies, in order, once the deletion is stuck. MDR applies the first step when it
+	// detects the stuck deletion, then the next one each time the deletion stays stuck for another Timeout.
+	// "ExcludeNodeDraining" sets the exclude-node-draining annotation on the Machine, so that the Machine controller
+	// skips the Node drain. "RemovePreTerminateHook" removes MDR's own preTerminate hook, without waiting for its
+	// release.
+	// +optional
+	Escalations []DeletionEscalation `json:"escalations,omitempty"`
+}
+
+// DeletionEscalation is a step MDR applies to a Machine whose deletion is stuck
+// +kubebuilder:validation:Enum=ExcludeNodeDraining;RemovePreTerminateHook
+type DeletionEscalation string
+
+const (
+	// DeletionEscalationExcludeNodeDraining makes the Machine controller skip the Node drain
+	DeletionEscalationExcludeNodeDraining DeletionEscalation = "ExcludeNodeDraining"
+	// DeletionEscalationRemovePreTerminateHook removes MDR's preTerminate hook from the Machine
+	DeletionEscalationRemovePreTerminateHook DeletionEscalation = "RemovePreTerminateHook"
+)
+
+// FallbackRemediationTemplateReference references the remediation template of another remediator
+type FallbackRemediationTemplateReference struct {
+	// APIVersion of the template, e.g. "self-node-remediation.medik8s.io/v1alpha1"
+	// +kubebuilder:validation:MinLength=1
+	APIVersion string `json:"apiVersion"`
+	// Kind of the template, e.g. "SelfNodeRemediationTemplate". The Kind of the created remediation is the template's
+	// Kind without the "Template" suffix.
+	// +kubebuilder:validation:Pattern="^.+Template$"
+	Kind string `json:"kind"`
+	// Name of the template, which must be in the remediation's namespace
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
 }
 
 // MachineDeletionRemediationStatus defines the observed state of MachineDeletionRemediation
 type MachineDeletionRemediationStatus struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="conditions",xDescriptors="urn:alm:descriptor:io.kubernetes.conditions"
 	// Represents the observations of a MachineDeletionRemediation's current state.
-	// Known .status.conditions.type are: "Processing", "Succeeded" and "PermanentNodeDeletionExpected"
+	// Known .status.conditions.type are: "Processing", "Succeeded", "PermanentNodeDeletionExpected", "Waiting", "AwaitingApproval",
+	// "Deferred", "DryRun" and "MachineDeletionStuck"
 	// +listType=map
 	// +listMapKey=type
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ReplacementMachineName is the name of the Machine created by the Machine owner to replace the deleted one
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	ReplacementMachineName string `json:"replacementMachineName,omitempty"`
+
+	// ReplacementNodeName is the name of the replacement Machine's Node
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	ReplacementNodeName string `json:"replacementNodeName,omitempty"`
+
+	// Phase is a brief summary of the remediation progress.
+	// One of "Started", "Waiting", "AwaitingApproval", "Deferred", "Draining", "MachineDeletionRequested", "WaitingForReplacement", "FallbackRemediation", "DryRun", "Succeeded" and "Failed".
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	Phase RemediationPhase `json:"phase,omitempty"`
+
+	// Machine is the Machine targeted by the remediation
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	Machine *ObjectReference `json:"machine,omitempty"`
+
+	// MachineOwner is the object responsible for replacing the deleted Machine (e.g. its MachineSet)
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	MachineOwner *ObjectReference `json:"machineOwner,omitempty"`
+
+	// ProviderID is the providerID of the Machine targeted by the remediation
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	ProviderID string `json:"providerID,omitempty"`
+
+	// MachineDeletionRequestedTime is the time the Machine deletion was requested
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	MachineDeletionRequestedTime *metav1.Time `json:"machineDeletionRequestedTime,omitempty"`
+
+	// MachineDeletedTime is the time the remediation observed that the Machine was gone
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	MachineDeletedTime *metav1.Time `json:"machineDeletedTime,omitempty"`
+
+	// ReplacementNodeReadyTime is the time the remediation observed that the replacement Node was Ready
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	ReplacementNodeReadyTime *metav1.Time `json:"replacementNodeReadyTime,omitempty"`
+
+	// Drain reports the progress of the Node drain, when MDR drains the Node
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	Drain *DrainStatus `json:"drain,omitempty"`
+
+	// PreTerminateHook reports the progress of the preTerminate lifecycle hook, when MDR adds it to the Machine
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	PreTerminateHook *PreTerminateHookStatus `json:"preTerminateHook,omitempty"`
+
+	// Approval reports the approval of the Machine deletion, when the remediation requires it
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	Approval *ApprovalStatus `json:"approval,omitempty"`
+
+	// NextMaintenanceWindowStart is the start of the next maintenance window, while the remediation is deferred
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	NextMaintenanceWindowStart *metav1.Time `json:"nextMaintenanceWindowStart,omitempty"`
+
+	// BareMetalHost reports the provisioning of the Metal3 BareMetalHost of the Machine, on bare metal platforms
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	BareMetalHost *BareMetalHostStatus `json:"bareMetalHost,omitempty"`
+
+	// StuckDeletion reports what holds the Machine, once its deletion is detected as stuck
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	StuckDeletion *StuckDeletionStatus `json:"stuckDeletion,omitempty"`
+
+	// FallbackRemediation reports the remediation created with the fallback remediation template, when the Machine
+	// could not be deleted
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	FallbackRemediation *FallbackRemediationStatus `json:"fallbackRemediation,omitempty"`
+}
+
+// DrainStatus reports the progress of the Node drain
+type DrainStatus struct {
+	// NodeName is the name of the drained Node
+	NodeName string `json:"nodeName"`
+	// StartTime is the time the Node was cordoned
+	StartTime *metav1.Time `json:"startTime"`
+	// Cordoned is true if MDR cordoned the Node, i.e. it was schedulable when the drain started
+	// +optional
+	Cordoned bool `json:"cordoned,omitempty"`
+	// CompletionTime is the time all the Pods were evicted from the Node
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// PendingPods is the number of Pods still running on the Node which are to be evicted
+	// +optional
+	PendingPods int32 `json:"pendingPods,omitempty"`
+	// BlockedPods are the Pods, as namespace/name, whose eviction is blocked by a PodDisruptionBudget
+	// +optional
+	BlockedPods []string `json:"blockedPods,omitempty"`
+	// Forced is true once the ForceAfter timeout expired and the remaining Pods are deleted instead of evicted
+	// +optional
+	Forced bool `json:"forced,omitempty"`
+}
+
+// PreTerminateHookStatus reports the progress of the preTerminate lifecycle hook
+type PreTerminateHookStatus struct {
+	// AddedTime is the time the hook was added to the Machine
+	// +optional
+	AddedTime *metav1.Time `json:"addedTime,omitempty"`
+	// MachineDrainedTime is the time the remediation observed that the Machine was drained
+	// +optional
+	MachineDrainedTime *metav1.Time `json:"machineDrainedTime,omitempty"`
+	// RemovedTime is the time the hook was removed from the Machine, allowing its termination
+	// +optional
+	RemovedTime *metav1.Time `json:"removedTime,omitempty"`
+}
+
+// ApprovalStatus reports the approval of the Machine deletion
+type ApprovalStatus struct {
+	// RequestedTime is the time the remediation started waiting for the approval
+	RequestedTime *metav1.Time `json:"requestedTime"`
+	// ApprovedTime is the time the remediation observed the approval
+	// +optional
+	ApprovedTime *metav1.Time `json:"approvedTime,omitempty"`
+	// ApprovedBy is the source of the approval: the approval annotation, a MachineDeletionRemediationApproval, or the
+	// auto approval timeout
+	// +optional
+	ApprovedBy string `json:"approvedBy,omitempty"`
+}
+
+// BareMetalHostStatus reports the provisioning of a Metal3 BareMetalHost
+type BareMetalHostStatus struct {
+	// Name of the BareMetalHost
+	Name string `json:"name"`
+	// Namespace of the BareMetalHost
+	Namespace string `json:"namespace"`
+	// ProvisioningState is the last observed provisioning state of the host, e.g. "deprovisioning" or "provisioned"
+	// +optional
+	ProvisioningState string `json:"provisioningState,omitempty"`
+	// OperationalStatus is the last observed operational status of the host, e.g. "OK" or "error"
+	// +optional
+	OperationalStatus string `json:"operationalStatus,omitempty"`
+	// ErrorType is the type of the host's error, when its operational status is "error"
+	// +optional
+	ErrorType string `json:"errorType,omitempty"`
+	// ErrorMessage is the message of the host's error, when its operational status is "error"
+	// +optional
+	ErrorMessage string `json:"errorMessage,omitempty"`
+	// DeprovisionedTime is the time the remediation observed that the host was deprovisioned
+	// +optional
+	DeprovisionedTime *metav1.Time `json:"deprovisionedTime,omitempty"`
+	// ReprovisionedTime is the time the remediation observed that the host was provisioned again
+	// +optional
+	ReprovisionedTime *metav1.Time `json:"reprovisionedTime,omitempty"`
+}
+
+// StuckDeletionStatus reports what holds a Machine whose deletion is stuck
+type StuckDeletionStatus struct {
+	// DetectedTime is the time the remediation detected that the Machine deletion was stuck
+	DetectedTime *metav1.Time `json:"detectedTime"`
+	// Finalizers are the finalizers of the Machine
+	// +optional
+	Finalizers []string `json:"finalizers,omitempty"`
+	// LifecycleHooks are the lifecycle hooks holding the Machine drain or termination, as phase/name
+	// +optional
+	LifecycleHooks []string `json:"lifecycleHooks,omitempty"`
+	// DrainBlockers are the Pods, as namespace/name, whose eviction is blocked by a PodDisruptionBudget while the
+	// Machine controller drains the Node
+	// +optional
+	DrainBlockers []string `json:"drainBlockers,omitempty"`
+	// Escalations are the escalation steps applied to the Machine
+	// +optional
+	Escalations []DeletionEscalation `json:"escalations,omitempty"`
+	// LastEscalationTime is the time the last escalation step was applied
+	// +optional
+	LastEscalationTime *metav1.Time `json:"lastEscalationTime,omitempty"`
+}
+
+// FallbackRemediationStatus reports the remediation created with the fallback remediation template
+type FallbackRemediationStatus struct {
+	// Remediation is the created remediation
+	Remediation ObjectReference `json:"remediation"`
+	// Reason is the reason why the Machine could not be deleted, e.g. "RemediationSkippedNoControllerOwner"
+	Reason string `json:"reason"`
+	// CreatedTime is the time the remediation was created
+	CreatedTime *metav1.Time `json:"createdTime"`
+	// CompletedTime is the time the remediation was observed completed, according to its Succeeded condition
+	// +optional
+	CompletedTime *metav1.Time `json:"completedTime,omitempty"`
+}
+
+// ObjectReference contains enough information to retrieve the referenced object
+type ObjectReference struct {
+	// APIVersion of the referenced object
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+	// Kind of the referenced object
+	Kind string `json:"kind"`
+	// Name of the referenced object
+	Name string `json:"name"`
+	// Namespace of the referenced object
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:resource:shortName=mdr
+//+kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+//+kubebuilder:printcolumn:name="Machine",type="string",JSONPath=".status.machine.name"
+//+kubebuilder:printcolumn:name="Owner Kind",type="string",JSONPath=".status.machineOwner.kind",priority=1
+//+kubebuilder:printcolumn:name="Owner",type="string",JSONPath=".status.machineOwner.name",priority=1
+//+kubebuilder:printcolumn:name="Provider ID",type="string",JSONPath=".status.providerID",priority=1
+//+kubebuilder:printcolumn:name="Deletion Requested",type="date",JSONPath=".status.machineDeletionRequestedTime",priority=1
+//+kubebuilder:printcolumn:name="Machine Deleted",type="date",JSONPath=".status.machineDeletedTime",priority=1
+//+kubebuilder:printcolumn:name="Replacement Node",type="string",JSONPath=".status.replacementNodeName"
+//+kubebuilder:printcolumn:name="Node Ready",type="date",JSONPath=".status.replacementNodeReadyTime",priority=1
+//+kubebuilder:printcolumn:name="Fallback Remediation",type="string",JSONPath=".status.fallbackRemediation.remediation.kind",priority=1
+//+kubebuilder:printcolumn:name="Host State",type="string",JSONPath=".status.bareMetalHost.provisioningState",priority=1
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // MachineDeletionRemediation is the Schema for the machinedeletionremediations API
 // +operator-sdk:csv:customresourcedefinitions:resources={{"MachineDeletionRemediation","v1alpha1","machinedeletionremediations"}}
@@ -0,0 +1,175 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	commonannotations "github.com/medik8s/common/pkg/annotations"
+	commonconditions "github.com/medik8s/common/pkg/conditions"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	unresolvableMachineMsg        = "cannot resolve the Machine targeted by the remediation: %v"
+	unresolvableMachineWarningMsg = "cannot resolve the Machine targeted by the remediation, it falls back to its fallback remediation: %v"
+	duplicateRemediationMsg       = "Machine %s is already being remediated by MachineDeletionRemediation %s"
+)
+
+// remediationLog is for logging in this package.
+var remediationLog = logf.Log.WithName("machinedeletionremediation-resource")
+
+// MachineKey identifies a Machine across the Machine APIs, which use the same Kind in different API groups
+// +kubebuilder:object:generate=false
+type MachineKey struct {
+	Group string
+	client.ObjectKey
+}
+
+// MachineResolver resolves the Machine targeted by a MachineDeletionRemediation
+// +kubebuilder:object:generate=false
+type MachineResolver interface {
+	// ResolveMachine returns the key of the Machine targeted by the remediation, or an error describing why it cannot
+	// be found
+	ResolveMachine(ctx context.Context, remediation *MachineDeletionRemediation) (MachineKey, error)
+}
+
+// SetupWebhookWithManager registers the validating webhook of MachineDeletionRemediation. The resolver is used to
+// find the Machine targeted by the remediations.
+func (r *MachineDeletionRemediation) SetupWebhookWithManager(mgr ctrl.Manager, resolver MachineResolver) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&remediationValidator{reader: mgr.GetAPIReader(), resolver: resolver}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-machine-deletion-remediation-medik8s-io-v1alpha1-machinedeletionremediation,mutating=false,failurePolicy=fail,sideEffects=None,groups=machine-deletion-remediation.medik8s.io,resources=machinedeletionremediations,verbs=create,versions=v1alpha1,name=vmachinedeletionremediation.kb.io,admissionReviewVersions=v1
+
+// remediationValidator validates MachineDeletionRemediations
+// +kubebuilder:object:generate=false
+type remediationValidator struct {
+	// reader is not cached, so that remediations created at the same time are detected as duplicates. The Machines of
+	// the listed remediations are not resolved, see isRemediatingMachine.
+	reader   client.Reader
+	resolver MachineResolver
+}
+
+var _ admission.CustomValidator = &remediationValidator{}
+
+// ValidateCreate rejects remediations whose Machine cannot be resolved, unless they have a fallback remediation
+// template, which the controller uses instead, and remediations of a Machine which is already being remediated.
+func (v *remediationValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	remediation, ok := obj.(*MachineDeletionRemediation)
+	if !ok {
+		return nil, fmt.Errorf("expected a MachineDeletionRemediation but got %T", obj)
+	}
+	remediationLog.Info("validate create", "name", remediation.Name, "namespace", remediation.Namespace)
+
+	machine, err := v.resolver.ResolveMachine(ctx, remediation)
+	if err != nil {
+		if remediation.Spec.FallbackRemediationTemplate != nil {
+			return admission.Warnings{fmt.Sprintf(unresolvableMachineWarningMsg, err)}, nil
+		}
+		return nil, fmt.Errorf(unresolvableMachineMsg, err)
+	}
+
+	remediations := &MachineDeletionRemediationList{}
+	if err := v.reader.List(ctx, remediations); err != nil {
+		return nil, err
+	}
+	for i := range remediations.Items {
+		other := &remediations.Items[i]
+		if (other.Name == remediation.Name && other.Namespace == remediation.Namespace) ||
+			meta.IsStatusConditionPresentAndEqual(other.Status.Conditions, commonconditions.ProcessingType, metav1.ConditionFalse) {
+			continue
+		}
+		if isRemediatingMachine(other, machine, getRemediationNodeName(remediation)) {
+			return nil, fmt.Errorf(duplicateRemediationMsg, machine, client.ObjectKeyFromObject(other))
+		}
+	}
+	return nil, nil
+}
+
+// ValidateUpdate does not validate remediation updates
+func (v *remediationValidator) ValidateUpdate(_ context.Context, _, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// ValidateDelete does not validate remediation deletions
+func (v *remediationValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// isRemediatingMachine checks if an existing remediation targets the given Machine, or the given Node when not empty,
+// without resolving its Machine, which would cost API calls for every remediation. Once found by the controller, the
+// Machine is reported in the remediation's status. Until then, the remediations created by MachineHealthCheck are owned
+// by their Machine, and the other ones reference their Node. Duplicates which are not detected here are skipped by the
+// controller.
+func isRemediatingMachine(remediation *MachineDeletionRemediation, machine MachineKey, nodeName string) bool {
+	if reported := remediation.Status.Machine; reported != nil {
+		return getAPIGroup(reported.APIVersion) == machine.Group &&
+			reported.Name == machine.Name && reported.Namespace == machine.Namespace
+	}
+	if owner := getOwnerMachine(remediation); owner != nil {
+		return getAPIGroup(owner.APIVersion) == machine.Group &&
+			owner.Name == machine.Name && remediation.Namespace == machine.Namespace
+	}
+	return nodeName != "" && getRemediationNodeName(remediation) == nodeName
+}
+
+// getOwnerMachine returns the reference to the Machine owning the remediation, if it was created by
+// MachineHealthCheck
+func getOwnerMachine(remediation *MachineDeletionRemediation) *metav1.OwnerReference {
+	owners := remediation.GetOwnerReferences()
+	for i := range owners {
+		if owners[i].Kind == "Machine" {
+			return &owners[i]
+		}
+	}
+	return nil
+}
+
+// getAPIGroup returns the API group of the given apiVersion, or an empty string if it is invalid
+func getAPIGroup(apiVersion string) string {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return ""
+	}
+	return gv.Group
+}
+
+// getRemediationNodeName returns the name of the Node targeted by the remediation, unless it was created by
+// MachineHealthCheck. NHC sets the NodeNameAnnotation on the remediations with a generated name, otherwise the
+// remediation's name is the Node's name.
+func getRemediationNodeName(remediation *MachineDeletionRemediation) string {
+	if getOwnerMachine(remediation) != nil {
+		return ""
+	}
+	if nodeName := remediation.GetAnnotations()[commonannotations.NodeNameAnnotation]; nodeName != "" {
+		return nodeName
+	}
+	return remediation.GetName()
+}
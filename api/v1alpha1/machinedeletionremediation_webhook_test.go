@@ -0,0 +1,205 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"time"
+
+	commonannotations "github.com/medik8s/common/pkg/annotations"
+	commonconditions "github.com/medik8s/common/pkg/conditions"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("MachineDeletionRemediation Webhook", func() {
+	const namespace = "default"
+	const openshiftMachineGroup, capiMachineGroup = "machine.openshift.io", "cluster.x-k8s.io"
+	machine := MachineKey{Group: openshiftMachineGroup, ObjectKey: client.ObjectKey{Name: "worker-machine", Namespace: "openshift-machine-api"}}
+	newMachine := func(group, name, namespace string) MachineKey {
+		return MachineKey{Group: group, ObjectKey: client.ObjectKey{Name: name, Namespace: namespace}}
+	}
+
+	newRemediation := func(name string) *MachineDeletionRemediation {
+		return &MachineDeletionRemediation{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	}
+
+	When("the Machine cannot be resolved", func() {
+		It("rejects the remediation", func() {
+			err := k8sClient.Create(ctx, newRemediation("unknown-node"))
+			Expect(err).To(MatchError(ContainSubstring("cannot resolve the Machine targeted by the remediation: node unknown-node not found")))
+		})
+
+		It("accepts the remediation with a fallback remediation template with a warning", func() {
+			remediation := newRemediation("unknown-node-with-fallback")
+			remediation.Spec.FallbackRemediationTemplate = &FallbackRemediationTemplateReference{
+				APIVersion: "self-node-remediation.medik8s.io/v1alpha1",
+				Kind:       "SelfNodeRemediationTemplate",
+				Name:       "self-node-remediation-automatic-strategy-template",
+			}
+			validator := &remediationValidator{reader: k8sClient, resolver: resolver}
+			warnings, err := validator.ValidateCreate(ctx, remediation)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(warnings).To(ConsistOf(ContainSubstring("it falls back to its fallback remediation")))
+
+			Expect(k8sClient.Create(ctx, remediation)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, remediation)).To(Succeed())
+		})
+	})
+
+	When("the Machine can be resolved", func() {
+		var first *MachineDeletionRemediation
+
+		BeforeEach(func() {
+			resolver.set("worker-node", machine)
+			first = newRemediation("worker-node")
+			Expect(k8sClient.Create(ctx, first)).To(Succeed())
+			DeferCleanup(k8sClient.Delete, first)
+		})
+
+		It("rejects another remediation of the same Node", func() {
+			resolver.set("worker-node-duplicate", machine)
+			duplicate := newRemediation("worker-node-duplicate")
+			duplicate.Annotations = map[string]string{commonannotations.NodeNameAnnotation: "worker-node"}
+			err := k8sClient.Create(ctx, duplicate)
+			Expect(err).To(MatchError(ContainSubstring("Machine openshift-machine-api/worker-machine is already being remediated by MachineDeletionRemediation default/worker-node")))
+		})
+
+		It("rejects another remediation of the Machine owning an existing remediation", func() {
+			ownedMachine := newMachine(openshiftMachineGroup, "owner-machine", namespace)
+			resolver.set("owner-machine", ownedMachine)
+			owned := newRemediation("owner-machine")
+			owned.OwnerReferences = []metav1.OwnerReference{
+				{APIVersion: "machine.openshift.io/v1beta1", Kind: "Machine", Name: ownedMachine.Name, UID: "1234"},
+			}
+			Expect(k8sClient.Create(ctx, owned)).To(Succeed())
+			DeferCleanup(k8sClient.Delete, owned)
+
+			resolver.set("owner-machine-node", ownedMachine)
+			err := k8sClient.Create(ctx, newRemediation("owner-machine-node"))
+			Expect(err).To(MatchError(ContainSubstring("already being remediated by MachineDeletionRemediation default/owner-machine")))
+		})
+
+		It("accepts a remediation of another Machine", func() {
+			resolver.set("other-node", newMachine(openshiftMachineGroup, "other-machine", machine.Namespace))
+			other := newRemediation("other-node")
+			Expect(k8sClient.Create(ctx, other)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, other)).To(Succeed())
+		})
+
+		It("accepts a remediation of the same Machine once the first one is completed", func() {
+			meta.SetStatusCondition(&first.Status.Conditions, metav1.Condition{
+				Type:   commonconditions.ProcessingType,
+				Status: metav1.ConditionFalse,
+				Reason: "RemediationFinished",
+			})
+			Expect(k8sClient.Status().Update(ctx, first)).To(Succeed())
+
+			resolver.set("worker-node-next", machine)
+			next := newRemediation("worker-node-next")
+			Expect(k8sClient.Create(ctx, next)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, next)).To(Succeed())
+		})
+
+		It("uses the Machine reported in the status of the existing remediations", func() {
+			first.Status.Machine = &ObjectReference{APIVersion: "machine.openshift.io/v1beta1", Kind: "Machine", Name: "reported-machine", Namespace: machine.Namespace}
+			Expect(k8sClient.Status().Update(ctx, first)).To(Succeed())
+
+			resolver.set("reported-node", newMachine(openshiftMachineGroup, "reported-machine", machine.Namespace))
+			err := k8sClient.Create(ctx, newRemediation("reported-node"))
+			Expect(err).To(MatchError(ContainSubstring("already being remediated")))
+		})
+
+		It("accepts a remediation of a Cluster API Machine with the same name as a reported OpenShift Machine", func() {
+			first.Status.Machine = &ObjectReference{APIVersion: "machine.openshift.io/v1beta1", Kind: "Machine", Name: machine.Name, Namespace: machine.Namespace}
+			Expect(k8sClient.Status().Update(ctx, first)).To(Succeed())
+
+			resolver.set("capi-node", newMachine(capiMachineGroup, machine.Name, machine.Namespace))
+			capiRemediation := newRemediation("capi-node")
+			Expect(k8sClient.Create(ctx, capiRemediation)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, capiRemediation)).To(Succeed())
+		})
+
+		It("accepts a remediation of a Cluster API Machine with the same name as the Machine owning a remediation", func() {
+			resolver.set("openshift-owner-machine", newMachine(openshiftMachineGroup, "same-name-machine", namespace))
+			owned := newRemediation("openshift-owner-machine")
+			owned.OwnerReferences = []metav1.OwnerReference{
+				{APIVersion: "machine.openshift.io/v1beta1", Kind: "Machine", Name: "same-name-machine", UID: "5678"},
+			}
+			Expect(k8sClient.Create(ctx, owned)).To(Succeed())
+			DeferCleanup(k8sClient.Delete, owned)
+
+			resolver.set("capi-same-name-node", newMachine(capiMachineGroup, "same-name-machine", namespace))
+			capiRemediation := newRemediation("capi-same-name-node")
+			Expect(k8sClient.Create(ctx, capiRemediation)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, capiRemediation)).To(Succeed())
+		})
+	})
+})
+
+var _ = Describe("MachineDeletionRemediationTemplate Webhook", func() {
+	var template *MachineDeletionRemediationTemplate
+
+	BeforeEach(func() {
+		template = &MachineDeletionRemediationTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "template", Namespace: "default"},
+		}
+		Expect(k8sClient.Create(ctx, template)).To(Succeed())
+		DeferCleanup(k8sClient.Delete, template)
+	})
+
+	It("accepts updates of mutable fields", func() {
+		template.Spec.Template.Spec.PollInterval = &metav1.Duration{Duration: 10 * time.Second}
+		Expect(k8sClient.Update(ctx, template)).To(Succeed())
+	})
+
+	It("rejects updates of immutable fields", func() {
+		propagation := metav1.DeletePropagationForeground
+		waitForNodeReplacement := false
+		template.Spec.Template.Spec.DeletionPropagation = &propagation
+		template.Spec.Template.Spec.WaitForNodeReplacement = &waitForNodeReplacement
+		Expect(k8sClient.Update(ctx, template)).To(MatchError(ContainSubstring(
+			"the following fields are immutable, create a new template to change them: " +
+				"spec.template.spec.deletionPropagation, spec.template.spec.waitForNodeReplacement")))
+	})
+})
+
+var _ = Describe("MachineDeletionRemediationConfig Webhook", func() {
+	var config *MachineDeletionRemediationConfig
+
+	BeforeEach(func() {
+		config = &MachineDeletionRemediationConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: ConfigCRName, Namespace: "default"},
+		}
+		Expect(k8sClient.Create(ctx, config)).To(Succeed())
+		DeferCleanup(k8sClient.Delete, config)
+	})
+
+	It("accepts updates of mutable fields", func() {
+		config.Spec.DefaultPollInterval = &metav1.Duration{Duration: 10 * time.Second}
+		Expect(k8sClient.Update(ctx, config)).To(Succeed())
+	})
+
+	It("rejects updates of the leader election ID", func() {
+		Expect(config.Spec.LeaderElectionID).To(Equal("285d4098.example.com"))
+		config.Spec.LeaderElectionID = "changed.example.com"
+		Expect(k8sClient.Update(ctx, config)).To(MatchError(ContainSubstring("spec.leaderElectionID is immutable")))
+	})
+})
@@ -0,0 +1,58 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MachineDeletionRemediationApprovalSpec defines the desired state of MachineDeletionRemediationApproval
+type MachineDeletionRemediationApprovalSpec struct {
+	// RemediationName is the name of the MachineDeletionRemediation, in the same namespace, whose Machine deletion is
+	// approved. Approvals created before the remediation are ignored, as they refer to a previous remediation with the
+	// same name.
+	// +kubebuilder:validation:MinLength=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	RemediationName string `json:"remediationName"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:shortName=mdra
+//+kubebuilder:printcolumn:name="Remediation",type="string",JSONPath=".spec.remediationName"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// MachineDeletionRemediationApproval approves the Machine deletion of a MachineDeletionRemediation requiring an approval
+// +operator-sdk:csv:customresourcedefinitions:resources={{"MachineDeletionRemediationApproval","v1alpha1","machinedeletionremediationapprovals"}}
+type MachineDeletionRemediationApproval struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec MachineDeletionRemediationApprovalSpec `json:"spec"`
+}
+
+//+kubebuilder:object:root=true
+
+// MachineDeletionRemediationApprovalList contains a list of MachineDeletionRemediationApproval
+type MachineDeletionRemediationApprovalList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MachineDeletionRemediationApproval `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MachineDeletionRemediationApproval{}, &MachineDeletionRemediationApprovalList{})
+}
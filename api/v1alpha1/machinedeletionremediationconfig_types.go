@@ -0,0 +1,183 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// ConfigCRName is the name of the MachineDeletionRemediationConfig used by the operator. Only the CR with this name
+	// in the operator's namespace is taken into account.
+	ConfigCRName = "machine-deletion-remediation-config"
+)
+
+// MachineDeletionRemediationConfigSpec defines the desired state of MachineDeletionRemediationConfig
+type MachineDeletionRemediationConfigSpec struct {
+	// DefaultPollInterval is the interval between two checks of the remediation progress, used by the remediations
+	// which do not set their own pollInterval.
+	// Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".
+	// +kubebuilder:default:="30s"
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$"
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DefaultPollInterval *metav1.Duration `json:"defaultPollInterval,omitempty"`
+
+	// StatusUpdateRequeueInterval is the interval between an update of the remediation's status and its next check.
+	// Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".
+	// +kubebuilder:default:="1s"
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$"
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	StatusUpdateRequeueInterval *metav1.Duration `json:"statusUpdateRequeueInterval,omitempty"`
+
+	// LeaderElectionID is the name of the resource used for the leader election of the operator's replicas.
+	// It is read once when the operator starts, and is immutable: changing it while replicas are running would allow
+	// two leaders. Delete and recreate the configuration, and restart the operator, to change it.
+	// +kubebuilder:default:="285d4098.example.com"
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	LeaderElectionID string `json:"leaderElectionID,omitempty"`
+
+	// MaxInFlight is the maximum number of Machines being deleted at the same time, either an absolute number or a
+	// percentage of the cluster's Nodes (e.g. "10%"). There is no limit if not set.
+	// +kubebuilder:validation:XIntOrString
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	MaxInFlight *intstr.IntOrString `json:"maxInFlight,omitempty"`
+
+	// EnabledOwnerKinds are the Kinds of the Machine owners whose Machines can be remediated. Remediations of Machines
+	// owned by other Kinds are skipped. Supported Kinds are "MachineSet", "ControlPlaneMachineSet",
+	// "MachineDeployment", "KubeadmControlPlane" and the Kinds described in ownerKinds.
+	// +kubebuilder:default:={"MachineSet","ControlPlaneMachineSet","MachineDeployment","KubeadmControlPlane"}
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	EnabledOwnerKinds []string `json:"enabledOwnerKinds,omitempty"`
+
+	// OwnerKinds describe additional Kinds of Machine owners, e.g. the resources of custom scaling controllers, so that
+	// their Machines can be remediated once their Kind is enabled in enabledOwnerKinds. They take precedence over the
+	// built-in Kinds with the same Machine API group and Kind.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	OwnerKinds []MachineOwnerKind `json:"ownerKinds,omitempty"`
+
+	// BareMetalProviderIDPrefixes are the prefixes of the Machines' providerID identifying bare metal providers, whose
+	// Nodes keep their name when the Machine is re-provisioned. Nodes of other providers are expected to get a new name.
+	// +kubebuilder:default:={"baremetal"}
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	BareMetalProviderIDPrefixes []string `json:"bareMetalProviderIDPrefixes,omitempty"`
+
+	// DryRun, if true, makes all the remediations run their checks without deleting the Machines, regardless of their
+	// own dryRun field.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// MaintenanceWindows restrict the deletion of the Machines whose owner (e.g. their MachineSet) is selected by the
+	// window's selector to the time windows they define, in addition to the remediation's own maintenance windows.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	MaintenanceWindows []MachineOwnerMaintenanceWindow `json:"maintenanceWindows,omitempty"`
+}
+
+// MachineOwnerKind describes how MDR finds the Machine owners of a Kind, their desired number of replicas and their
+// Machines
+type MachineOwnerKind struct {
+	// MachineAPIGroup is the API group of the Machines owned by this Kind.
+	// +kubebuilder:default:="machine.openshift.io"
+	// +kubebuilder:validation:Enum=machine.openshift.io;cluster.x-k8s.io
+	// +optional
+	MachineAPIGroup string `json:"machineAPIGroup,omitempty"`
+
+	// APIVersion is the apiVersion of the Machine owners, e.g. "example.com/v1"
+	// +kubebuilder:validation:MinLength=1
+	APIVersion string `json:"apiVersion"`
+
+	// Kind is the Kind of the Machine owners, as set in the Machines' ownerReference
+	// +kubebuilder:validation:MinLength=1
+	Kind string `json:"kind"`
+
+	// ReplicasPath is the JSONPath of the owner's desired number of replicas, e.g. "{.spec.replicas}"
+	// +kubebuilder:default:="{.spec.replicas}"
+	// +optional
+	ReplicasPath string `json:"replicasPath,omitempty"`
+
+	// MachineSelectorPath is the JSONPath of the owner's label selector matching its Machines, e.g.
+	// "{.spec.selector}". If not set, the owner's Machines are the ones it controls by ownerReference.
+	// +optional
+	MachineSelectorPath string `json:"machineSelectorPath,omitempty"`
+}
+
+// MachineOwnerMaintenanceWindow is a maintenance window of the Machines whose owner is selected by a label selector
+type MachineOwnerMaintenanceWindow struct {
+	MaintenanceWindow `json:",inline"`
+
+	// MachineOwnerSelector selects the Machine owners (e.g. the MachineSets) by label. If not set, the window applies
+	// to all the Machines.
+	// +optional
+	MachineOwnerSelector *metav1.LabelSelector `json:"machineOwnerSelector,omitempty"`
+}
+
+// MachineDeletionRemediationConfigStatus defines the observed state of MachineDeletionRemediationConfig
+type MachineDeletionRemediationConfigStatus struct {
+	// EffectiveConfig is the configuration used by the operator, with the defaults applied to missing and invalid
+	// fields
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	EffectiveConfig MachineDeletionRemediationConfigSpec `json:"effectiveConfig,omitempty"`
+
+	// ValidationErrors lists the invalid fields of the spec, which were replaced by their defaults
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	ValidationErrors []string `json:"validationErrors,omitempty"`
+
+	// ObservedGeneration is the generation of the spec the effective config was computed from
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=mdrc
+
+// MachineDeletionRemediationConfig is the Schema for the machinedeletionremediationconfigs API
+// +operator-sdk:csv:customresourcedefinitions:resources={{"MachineDeletionRemediationConfig","v1alpha1","machinedeletionremediationconfigs"}}
+type MachineDeletionRemediationConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MachineDeletionRemediationConfigSpec   `json:"spec,omitempty"`
+	Status MachineDeletionRemediationConfigStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// MachineDeletionRemediationConfigList contains a list of MachineDeletionRemediationConfig
+type MachineDeletionRemediationConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MachineDeletionRemediationConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MachineDeletionRemediationConfig{}, &MachineDeletionRemediationConfigList{})
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	leaderElectionIDImmutableErrorMsg = "spec.leaderElectionID is immutable: changing it while the operator is running would allow two leaders, delete and recreate the configuration and restart the operator to change it"
+)
+
+// configLog is for logging in this package.
+var configLog = logf.Log.WithName("machinedeletionremediationconfig-resource")
+
+// SetupWebhookWithManager registers the validating webhook of MachineDeletionRemediationConfig
+func (r *MachineDeletionRemediationConfig) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&configValidator{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-machine-deletion-remediation-medik8s-io-v1alpha1-machinedeletionremediationconfig,mutating=false,failurePolicy=fail,sideEffects=None,groups=machine-deletion-remediation.medik8s.io,resources=machinedeletionremediationconfigs,verbs=update,versions=v1alpha1,name=vmachinedeletionremediationconfig.kb.io,admissionReviewVersions=v1
+
+// configValidator validates MachineDeletionRemediationConfigs
+// +kubebuilder:object:generate=false
+type configValidator struct{}
+
+var _ admission.CustomValidator = &configValidator{}
+
+// ValidateCreate does not validate configuration creations
+func (v *configValidator) ValidateCreate(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// ValidateUpdate rejects configuration updates changing the leader election ID. The operator reads it once when it
+// starts, so a change would make the replicas started before and after it use different leases, and be leaders at the
+// same time.
+func (v *configValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldConfig, ok := oldObj.(*MachineDeletionRemediationConfig)
+	if !ok {
+		return nil, fmt.Errorf("expected a MachineDeletionRemediationConfig but got %T", oldObj)
+	}
+	newConfig, ok := newObj.(*MachineDeletionRemediationConfig)
+	if !ok {
+		return nil, fmt.Errorf("expected a MachineDeletionRemediationConfig but got %T", newObj)
+	}
+	configLog.Info("validate update", "name", newConfig.Name, "namespace", newConfig.Namespace)
+
+	if oldConfig.Spec.LeaderElectionID != newConfig.Spec.LeaderElectionID {
+		return nil, errors.New(leaderElectionIDImmutableErrorMsg)
+	}
+	return nil, nil
+}
+
+// ValidateDelete does not validate configuration deletions
+func (v *configValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	immutableFieldsErrorMsg = "the following fields are immutable, create a new template to change them: %s"
+)
+
+// templateLog is for logging in this package.
+var templateLog = logf.Log.WithName("machinedeletionremediationtemplate-resource")
+
+// templateImmutableFields returns the immutable fields of the template's remediation spec, by path. They define how
+// the Machines are deleted, which the NodeHealthCheck configurations using the template rely on.
+var templateImmutableFields = map[string]func(spec *MachineDeletionRemediationSpec) any{
+	"spec.template.spec.deletionPropagation":    func(spec *MachineDeletionRemediationSpec) any { return spec.DeletionPropagation },
+	"spec.template.spec.waitForNodeReplacement": func(spec *MachineDeletionRemediationSpec) any { return spec.WaitForNodeReplacement },
+}
+
+// SetupWebhookWithManager registers the validating webhook of MachineDeletionRemediationTemplate
+func (r *MachineDeletionRemediationTemplate) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&templateValidator{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-machine-deletion-remediation-medik8s-io-v1alpha1-machinedeletionremediationtemplate,mutating=false,failurePolicy=fail,sideEffects=None,groups=machine-deletion-remediation.medik8s.io,resources=machinedeletionremediationtemplates,verbs=update,versions=v1alpha1,name=vmachinedeletionremediationtemplate.kb.io,admissionReviewVersions=v1
+
+// templateValidator validates MachineDeletionRemediationTemplates
+// +kubebuilder:object:generate=false
+type templateValidator struct{}
+
+var _ admission.CustomValidator = &templateValidator{}
+
+// ValidateCreate does not validate template creations
+func (v *templateValidator) ValidateCreate(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// ValidateUpdate rejects template updates changing immutable fields
+func (v *templateValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldTemplate, ok := oldObj.(*MachineDeletionRemediationTemplate)
+	if !ok {
+		return nil, fmt.Errorf("expected a MachineDeletionRemediationTemplate but got %T", oldObj)
+	}
+	newTemplate, ok := newObj.(*MachineDeletionRemediationTemplate)
+	if !ok {
+		return nil, fmt.Errorf("expected a MachineDeletionRemediationTemplate but got %T", newObj)
+	}
+	templateLog.Info("validate update", "name", newTemplate.Name, "namespace", newTemplate.Namespace)
+
+	var changed []string
+	for path, field := range templateImmutableFields {
+		if !reflect.DeepEqual(field(&oldTemplate.Spec.Template.Spec), field(&newTemplate.Spec.Template.Spec)) {
+			changed = append(changed, path)
+		}
+	}
+	if len(changed) > 0 {
+		slices.Sort(changed)
+		return nil, fmt.Errorf(immutableFieldsErrorMsg, strings.Join(changed, ", "))
+	}
+	return nil, nil
+}
+
+// ValidateDelete does not validate template deletions
+func (v *templateValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
@@ -23,14 +23,173 @@ package v1alpha1
 import (
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApprovalSpec) DeepCopyInto(out *ApprovalSpec) {
+	*out = *in
+	if in.AutoApproveAfter != nil {
+		in, out := &in.AutoApproveAfter, &out.AutoApproveAfter
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApprovalSpec.
+func (in *ApprovalSpec) DeepCopy() *ApprovalSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ApprovalSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApprovalStatus) DeepCopyInto(out *ApprovalStatus) {
+	*out = *in
+	if in.RequestedTime != nil {
+		in, out := &in.RequestedTime, &out.RequestedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ApprovedTime != nil {
+		in, out := &in.ApprovedTime, &out.ApprovedTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApprovalStatus.
+func (in *ApprovalStatus) DeepCopy() *ApprovalStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ApprovalStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BareMetalHostStatus) DeepCopyInto(out *BareMetalHostStatus) {
+	*out = *in
+	if in.DeprovisionedTime != nil {
+		in, out := &in.DeprovisionedTime, &out.DeprovisionedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ReprovisionedTime != nil {
+		in, out := &in.ReprovisionedTime, &out.ReprovisionedTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BareMetalHostStatus.
+func (in *BareMetalHostStatus) DeepCopy() *BareMetalHostStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BareMetalHostStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DrainSpec) DeepCopyInto(out *DrainSpec) {
+	*out = *in
+	if in.GracePeriod != nil {
+		in, out := &in.GracePeriod, &out.GracePeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ForceAfter != nil {
+		in, out := &in.ForceAfter, &out.ForceAfter
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DrainSpec.
+func (in *DrainSpec) DeepCopy() *DrainSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DrainSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DrainStatus) DeepCopyInto(out *DrainStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.BlockedPods != nil {
+		in, out := &in.BlockedPods, &out.BlockedPods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DrainStatus.
+func (in *DrainStatus) DeepCopy() *DrainStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DrainStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FallbackRemediationStatus) DeepCopyInto(out *FallbackRemediationStatus) {
+	*out = *in
+	out.Remediation = in.Remediation
+	if in.CreatedTime != nil {
+		in, out := &in.CreatedTime, &out.CreatedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletedTime != nil {
+		in, out := &in.CompletedTime, &out.CompletedTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FallbackRemediationStatus.
+func (in *FallbackRemediationStatus) DeepCopy() *FallbackRemediationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FallbackRemediationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FallbackRemediationTemplateReference) DeepCopyInto(out *FallbackRemediationTemplateReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FallbackRemediationTemplateReference.
+func (in *FallbackRemediationTemplateReference) DeepCopy() *FallbackRemediationTemplateReference {
+	if in == nil {
+		return nil
+	}
+	out := new(FallbackRemediationTemplateReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MachineDeletionRemediation) DeepCopyInto(out *MachineDeletionRemediation) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -52,6 +211,211 @@ func (in *MachineDeletionRemediation) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineDeletionRemediationApproval) DeepCopyInto(out *MachineDeletionRemediationApproval) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineDeletionRemediationApproval.
+func (in *MachineDeletionRemediationApproval) DeepCopy() *MachineDeletionRemediationApproval {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineDeletionRemediationApproval)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MachineDeletionRemediationApproval) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineDeletionRemediationApprovalList) DeepCopyInto(out *MachineDeletionRemediationApprovalList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MachineDeletionRemediationApproval, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineDeletionRemediationApprovalList.
+func (in *MachineDeletionRemediationApprovalList) DeepCopy() *MachineDeletionRemediationApprovalList {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineDeletionRemediationApprovalList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MachineDeletionRemediationApprovalList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineDeletionRemediationApprovalSpec) DeepCopyInto(out *MachineDeletionRemediationApprovalSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineDeletionRemediationApprovalSpec.
+func (in *MachineDeletionRemediationApprovalSpec) DeepCopy() *MachineDeletionRemediationApprovalSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineDeletionRemediationApprovalSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineDeletionRemediationConfig) DeepCopyInto(out *MachineDeletionRemediationConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineDeletionRemediationConfig.
+func (in *MachineDeletionRemediationConfig) DeepCopy() *MachineDeletionRemediationConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineDeletionRemediationConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MachineDeletionRemediationConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineDeletionRemediationConfigList) DeepCopyInto(out *MachineDeletionRemediationConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MachineDeletionRemediationConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineDeletionRemediationConfigList.
+func (in *MachineDeletionRemediationConfigList) DeepCopy() *MachineDeletionRemediationConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineDeletionRemediationConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MachineDeletionRemediationConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineDeletionRemediationConfigSpec) DeepCopyInto(out *MachineDeletionRemediationConfigSpec) {
+	*out = *in
+	if in.DefaultPollInterval != nil {
+		in, out := &in.DefaultPollInterval, &out.DefaultPollInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.StatusUpdateRequeueInterval != nil {
+		in, out := &in.StatusUpdateRequeueInterval, &out.StatusUpdateRequeueInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxInFlight != nil {
+		in, out := &in.MaxInFlight, &out.MaxInFlight
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.EnabledOwnerKinds != nil {
+		in, out := &in.EnabledOwnerKinds, &out.EnabledOwnerKinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.OwnerKinds != nil {
+		in, out := &in.OwnerKinds, &out.OwnerKinds
+		*out = make([]MachineOwnerKind, len(*in))
+		copy(*out, *in)
+	}
+	if in.BareMetalProviderIDPrefixes != nil {
+		in, out := &in.BareMetalProviderIDPrefixes, &out.BareMetalProviderIDPrefixes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]MachineOwnerMaintenanceWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineDeletionRemediationConfigSpec.
+func (in *MachineDeletionRemediationConfigSpec) DeepCopy() *MachineDeletionRemediationConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineDeletionRemediationConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineDeletionRemediationConfigStatus) DeepCopyInto(out *MachineDeletionRemediationConfigStatus) {
+	*out = *in
+	in.EffectiveConfig.DeepCopyInto(&out.EffectiveConfig)
+	if in.ValidationErrors != nil {
+		in, out := &in.ValidationErrors, &out.ValidationErrors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineDeletionRemediationConfigStatus.
+func (in *MachineDeletionRemediationConfigStatus) DeepCopy() *MachineDeletionRemediationConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineDeletionRemediationConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MachineDeletionRemediationList) DeepCopyInto(out *MachineDeletionRemediationList) {
 	*out = *in
@@ -87,6 +451,56 @@ func (in *MachineDeletionRemediationList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MachineDeletionRemediationSpec) DeepCopyInto(out *MachineDeletionRemediationSpec) {
 	*out = *in
+	if in.PollInterval != nil {
+		in, out := &in.PollInterval, &out.PollInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.WaitForNodeReplacement != nil {
+		in, out := &in.WaitForNodeReplacement, &out.WaitForNodeReplacement
+		*out = new(bool)
+		**out = **in
+	}
+	if in.NodeRestorationTimeout != nil {
+		in, out := &in.NodeRestorationTimeout, &out.NodeRestorationTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.DeletionPropagation != nil {
+		in, out := &in.DeletionPropagation, &out.DeletionPropagation
+		*out = new(v1.DeletionPropagation)
+		**out = **in
+	}
+	if in.Drain != nil {
+		in, out := &in.Drain, &out.Drain
+		*out = new(DrainSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PreTerminateHook != nil {
+		in, out := &in.PreTerminateHook, &out.PreTerminateHook
+		*out = new(PreTerminateHookSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Approval != nil {
+		in, out := &in.Approval, &out.Approval
+		*out = new(ApprovalSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]MaintenanceWindow, len(*in))
+		copy(*out, *in)
+	}
+	if in.StuckDeletion != nil {
+		in, out := &in.StuckDeletion, &out.StuckDeletion
+		*out = new(StuckDeletionSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FallbackRemediationTemplate != nil {
+		in, out := &in.FallbackRemediationTemplate, &out.FallbackRemediationTemplate
+		*out = new(FallbackRemediationTemplateReference)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineDeletionRemediationSpec.
@@ -109,6 +523,62 @@ func (in *MachineDeletionRemediationStatus) DeepCopyInto(out *MachineDeletionRem
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Machine != nil {
+		in, out := &in.Machine, &out.Machine
+		*out = new(ObjectReference)
+		**out = **in
+	}
+	if in.MachineOwner != nil {
+		in, out := &in.MachineOwner, &out.MachineOwner
+		*out = new(ObjectReference)
+		**out = **in
+	}
+	if in.MachineDeletionRequestedTime != nil {
+		in, out := &in.MachineDeletionRequestedTime, &out.MachineDeletionRequestedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.MachineDeletedTime != nil {
+		in, out := &in.MachineDeletedTime, &out.MachineDeletedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ReplacementNodeReadyTime != nil {
+		in, out := &in.ReplacementNodeReadyTime, &out.ReplacementNodeReadyTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Drain != nil {
+		in, out := &in.Drain, &out.Drain
+		*out = new(DrainStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PreTerminateHook != nil {
+		in, out := &in.PreTerminateHook, &out.PreTerminateHook
+		*out = new(PreTerminateHookStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Approval != nil {
+		in, out := &in.Approval, &out.Approval
+		*out = new(ApprovalStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NextMaintenanceWindowStart != nil {
+		in, out := &in.NextMaintenanceWindowStart, &out.NextMaintenanceWindowStart
+		*out = (*in).DeepCopy()
+	}
+	if in.BareMetalHost != nil {
+		in, out := &in.BareMetalHost, &out.BareMetalHost
+		*out = new(BareMetalHostStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StuckDeletion != nil {
+		in, out := &in.StuckDeletion, &out.StuckDeletion
+		*out = new(StuckDeletionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FallbackRemediation != nil {
+		in, out := &in.FallbackRemediation, &out.FallbackRemediation
+		*out = new(FallbackRemediationStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineDeletionRemediationStatus.
@@ -126,7 +596,7 @@ func (in *MachineDeletionRemediationTemplate) DeepCopyInto(out *MachineDeletionR
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	out.Status = in.Status
 }
 
@@ -183,7 +653,7 @@ func (in *MachineDeletionRemediationTemplateList) DeepCopyObject() runtime.Objec
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MachineDeletionRemediationTemplateResource) DeepCopyInto(out *MachineDeletionRemediationTemplateResource) {
 	*out = *in
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineDeletionRemediationTemplateResource.
@@ -199,7 +669,7 @@ func (in *MachineDeletionRemediationTemplateResource) DeepCopy() *MachineDeletio
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MachineDeletionRemediationTemplateSpec) DeepCopyInto(out *MachineDeletionRemediationTemplateSpec) {
 	*out = *in
-	out.Template = in.Template
+	in.Template.DeepCopyInto(&out.Template)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineDeletionRemediationTemplateSpec.
@@ -226,3 +696,181 @@ func (in *MachineDeletionRemediationTemplateStatus) DeepCopy() *MachineDeletionR
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineOwnerKind) DeepCopyInto(out *MachineOwnerKind) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineOwnerKind.
+func (in *MachineOwnerKind) DeepCopy() *MachineOwnerKind {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineOwnerKind)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineOwnerMaintenanceWindow) DeepCopyInto(out *MachineOwnerMaintenanceWindow) {
+	*out = *in
+	out.MaintenanceWindow = in.MaintenanceWindow
+	if in.MachineOwnerSelector != nil {
+		in, out := &in.MachineOwnerSelector, &out.MachineOwnerSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineOwnerMaintenanceWindow.
+func (in *MachineOwnerMaintenanceWindow) DeepCopy() *MachineOwnerMaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineOwnerMaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectReference) DeepCopyInto(out *ObjectReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectReference.
+func (in *ObjectReference) DeepCopy() *ObjectReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreTerminateHookSpec) DeepCopyInto(out *PreTerminateHookSpec) {
+	*out = *in
+	if in.ReleaseTimeout != nil {
+		in, out := &in.ReleaseTimeout, &out.ReleaseTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreTerminateHookSpec.
+func (in *PreTerminateHookSpec) DeepCopy() *PreTerminateHookSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PreTerminateHookSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreTerminateHookStatus) DeepCopyInto(out *PreTerminateHookStatus) {
+	*out = *in
+	if in.AddedTime != nil {
+		in, out := &in.AddedTime, &out.AddedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.MachineDrainedTime != nil {
+		in, out := &in.MachineDrainedTime, &out.MachineDrainedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.RemovedTime != nil {
+		in, out := &in.RemovedTime, &out.RemovedTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreTerminateHookStatus.
+func (in *PreTerminateHookStatus) DeepCopy() *PreTerminateHookStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PreTerminateHookStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StuckDeletionSpec) DeepCopyInto(out *StuckDeletionSpec) {
+	*out = *in
+	out.Timeout = in.Timeout
+	if in.Escalations != nil {
+		in, out := &in.Escalations, &out.Escalations
+		*out = make([]DeletionEscalation, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StuckDeletionSpec.
+func (in *StuckDeletionSpec) DeepCopy() *StuckDeletionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StuckDeletionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StuckDeletionStatus) DeepCopyInto(out *StuckDeletionStatus) {
+	*out = *in
+	if in.DetectedTime != nil {
+		in, out := &in.DetectedTime, &out.DetectedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Finalizers != nil {
+		in, out := &in.Finalizers, &out.Finalizers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LifecycleHooks != nil {
+		in, out := &in.LifecycleHooks, &out.LifecycleHooks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DrainBlockers != nil {
+		in, out := &in.DrainBlockers, &out.DrainBlockers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Escalations != nil {
+		in, out := &in.Escalations, &out.Escalations
+		*out = make([]DeletionEscalation, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastEscalationTime != nil {
+		in, out := &in.LastEscalationTime, &out.LastEscalationTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StuckDeletionStatus.
+func (in *StuckDeletionStatus) DeepCopy() *StuckDeletionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StuckDeletionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	commonevents "github.com/medik8s/common/pkg/events"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/medik8s/machine-deletion-remediation/api/v1alpha1"
+)
+
+const (
+	// ApprovedAnnotation can be set on a remediation requiring an approval to approve the deletion of its Machine
+	ApprovedAnnotation = "machine-deletion-remediation.medik8s.io/approved"
+
+	approvalRequestedEventReason  = "ApprovalRequested"
+	machineDeletionApprovedReason = "MachineDeletionApproved"
+
+	approvalRequestedMsg        = "waiting for the approval of the deletion of machine %s"
+	machineDeletionApprovedMsg  = "deletion of machine %s approved by %s"
+	autoApprovalSource          = "the auto approval timeout"
+	approvedByAnnotationSource  = "the " + ApprovedAnnotation + " annotation"
+	approvedByApprovalSourceFmt = "MachineDeletionRemediationApproval %s"
+)
+
+// isApprovalRequired checks if MDR has to wait for an approval before deleting the Machine
+func isApprovalRequired(remediation *v1alpha1.MachineDeletionRemediation) bool {
+	return remediation.Spec.Approval != nil
+}
+
+// waitForApproval requests the approval of the Machine deletion, and checks if it was given, either by the
+// ApprovedAnnotation, by a MachineDeletionRemediationApproval, or by the expiration of the AutoApproveAfter timeout.
+// It returns true once the deletion is approved, and otherwise the time to wait before checking again.
+func (r *MachineDeletionRemediationReconciler) waitForApproval(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation, machine client.Object) (bool, time.Duration, error) {
+	status := remediation.Status.Approval
+	if status != nil && status.ApprovedTime != nil {
+		return true, 0, nil
+	}
+	if status == nil {
+		now := metav1.Now()
+		status = &v1alpha1.ApprovalStatus{RequestedTime: &now}
+		remediation.Status.Approval = status
+		msg := fmt.Sprintf(approvalRequestedMsg, machine.GetName())
+		setAwaitingApprovalCondition(remediation, metav1.ConditionTrue, v1alpha1.ApprovalRequestedReason, msg)
+		r.Log.Info(msg, "annotation", ApprovedAnnotation)
+		commonevents.NormalEvent(r.Recorder, remediation, approvalRequestedEventReason, msg)
+	}
+
+	approvedBy, err := r.getApprovalSource(ctx, remediation)
+	if err != nil {
+		r.Log.Error(err, "could not verify if the machine deletion was approved", "machine", machine.GetName())
+		return false, 0, err
+	}
+	reason := v1alpha1.ApprovedReason
+	if approvedBy == "" {
+		autoApproved, remaining := isAutoApproved(remediation)
+		if !autoApproved {
+			r.Log.Info("waiting for the approval of the machine deletion", "machine", machine.GetName())
+			requeueAfter := r.getPollInterval(remediation)
+			if remaining > 0 && remaining < requeueAfter {
+				requeueAfter = remaining
+			}
+			return false, requeueAfter, nil
+		}
+		approvedBy, reason = autoApprovalSource, v1alpha1.AutoApprovedReason
+	}
+
+	now := metav1.Now()
+	status.ApprovedTime = &now
+	status.ApprovedBy = approvedBy
+	msg := fmt.Sprintf(machineDeletionApprovedMsg, machine.GetName(), approvedBy)
+	setAwaitingApprovalCondition(remediation, metav1.ConditionFalse, reason, msg)
+	r.Log.Info(msg)
+	commonevents.NormalEvent(r.Recorder, remediation, machineDeletionApprovedReason, msg)
+	return true, 0, nil
+}
+
+// getApprovalSource returns the source of the Machine deletion approval, or an empty string if it was not approved.
+// Approvals created before the remediation are ignored, as they refer to a previous remediation with the same name.
+func (r *MachineDeletionRemediationReconciler) getApprovalSource(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation) (string, error) {
+	if _, approved := remediation.GetAnnotations()[ApprovedAnnotation]; approved {
+		return approvedByAnnotationSource, nil
+	}
+
+	approvals := &v1alpha1.MachineDeletionRemediationApprovalList{}
+	if err := r.List(ctx, approvals, client.InNamespace(remediation.GetNamespace())); err != nil {
+		return "", err
+	}
+	created := remediation.GetCreationTimestamp()
+	for i := range approvals.Items {
+		approval := &approvals.Items[i]
+		if approval.Spec.RemediationName != remediation.GetName() ||
+			!approval.GetDeletionTimestamp().IsZero() ||
+			approval.CreationTimestamp.Before(&created) {
+			continue
+		}
+		return fmt.Sprintf(approvedByApprovalSourceFmt, approval.GetName()), nil
+	}
+	return "", nil
+}
+
+// isAutoApproved returns whether the approval's AutoApproveAfter timeout expired, and the time left before it expires
+func isAutoApproved(remediation *v1alpha1.MachineDeletionRemediation) (bool, time.Duration) {
+	autoApproveAfter := remediation.Spec.Approval.AutoApproveAfter
+	if autoApproveAfter == nil || remediation.Status.Approval == nil || remediation.Status.Approval.RequestedTime == nil {
+		return false, 0
+	}
+	remaining := autoApproveAfter.Duration - time.Since(remediation.Status.Approval.RequestedTime.Time)
+	return remaining <= 0, remaining
+}
+
+// isAwaitingApproval checks if the remediation waits for the approval of the Machine deletion
+func isAwaitingApproval(remediation *v1alpha1.MachineDeletionRemediation) bool {
+	return meta.IsStatusConditionTrue(remediation.Status.Conditions, v1alpha1.AwaitingApprovalConditionType)
+}
+
+// setAwaitingApprovalCondition sets the AwaitingApproval condition
+func setAwaitingApprovalCondition(remediation *v1alpha1.MachineDeletionRemediation, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&remediation.Status.Conditions, metav1.Condition{
+		Type:    v1alpha1.AwaitingApprovalConditionType,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+}
+
+// getApprovedRemediation maps a MachineDeletionRemediationApproval to the remediation it approves
+func getApprovedRemediation(_ context.Context, obj client.Object) []reconcile.Request {
+	approval, ok := obj.(*v1alpha1.MachineDeletionRemediationApproval)
+	if !ok || approval.Spec.RemediationName == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: approval.Spec.RemediationName, Namespace: approval.GetNamespace()}}}
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	commonconditions "github.com/medik8s/common/pkg/conditions"
+	commonevents "github.com/medik8s/common/pkg/events"
+
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/medik8s/machine-deletion-remediation/api/v1alpha1"
+)
+
+const (
+	// bareMetalHostAnnotation is the annotation of the Machines on Metal3 referencing their BareMetalHost, as
+	// namespace/name
+	bareMetalHostAnnotation = "metal3.io/BareMetalHost"
+
+	bareMetalHostProvisionedState        = "provisioned"
+	bareMetalHostErrorOperationalStatus  = "error"
+	bareMetalHostStateChangedReason      = "BareMetalHostStateChanged"
+	bareMetalHostStateChangedMsg         = "BareMetalHost %s provisioning state changed from %q to %q"
+	bareMetalHostErrorMsg                = "BareMetalHost %s entered an error state: %s: %s"
+	invalidBareMetalHostAnnotationErrMsg = "invalid BareMetalHost annotation of machine %s: %q"
+)
+
+var (
+	bareMetalHostGVK = schema.GroupVersionKind{Group: "metal3.io", Version: "v1alpha1", Kind: "BareMetalHost"}
+	// bareMetalHostDeprovisionedStates are the provisioning states of a host which is not provisioned, or is being
+	// provisioned again
+	bareMetalHostDeprovisionedStates = []string{"available", "ready", "preparing", "provisioning"}
+)
+
+// setBareMetalHost saves the BareMetalHost referenced by the Machine's annotation in the remediation's status, to
+// follow it through the deprovisioning and the reprovisioning once the Machine is deleted
+func (r *MachineDeletionRemediationReconciler) setBareMetalHost(remediation *v1alpha1.MachineDeletionRemediation, machine client.Object) {
+	value, exists := machine.GetAnnotations()[bareMetalHostAnnotation]
+	if !exists {
+		remediation.Status.BareMetalHost = nil
+		return
+	}
+	namespace, name, found := strings.Cut(value, "/")
+	if !found || namespace == "" || name == "" {
+		r.Log.Info(fmt.Sprintf(invalidBareMetalHostAnnotationErrMsg, machine.GetName(), value))
+		remediation.Status.BareMetalHost = nil
+		return
+	}
+	if host := remediation.Status.BareMetalHost; host != nil && host.Name == name && host.Namespace == namespace {
+		return
+	}
+	remediation.Status.BareMetalHost = &v1alpha1.BareMetalHostStatus{Name: name, Namespace: namespace}
+}
+
+// isBareMetalHostExternallyProvisioned checks if the BareMetalHost referenced by the Machine's annotation is provisioned
+// outside of Metal3. Hosts which cannot be found are not.
+func (r *MachineDeletionRemediationReconciler) isBareMetalHostExternallyProvisioned(ctx context.Context, machine client.Object) (bool, error) {
+	namespace, name, found := strings.Cut(machine.GetAnnotations()[bareMetalHostAnnotation], "/")
+	if !found || namespace == "" || name == "" {
+		return false, nil
+	}
+
+	host := &unstructured.Unstructured{}
+	host.SetGroupVersionKind(bareMetalHostGVK)
+	key := client.ObjectKey{Name: name, Namespace: namespace}
+	if err := r.Get(ctx, key, host); err != nil {
+		if meta.IsNoMatchError(err) || apiErrors.IsNotFound(err) {
+			return false, nil
+		}
+		r.Log.Error(err, "could not get BareMetalHost", "BareMetalHost", key)
+		return false, err
+	}
+	externallyProvisioned, _, _ := unstructured.NestedBool(host.Object, "spec", "externallyProvisioned")
+	return externallyProvisioned, nil
+}
+
+// followBareMetalHost reports the provisioning state of the remediation's BareMetalHost in its status, and fails the
+// remediation if the host enters an error state. It returns true if the remediation failed because of the host.
+func (r *MachineDeletionRemediationReconciler) followBareMetalHost(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation) (bool, error) {
+	status := remediation.Status.BareMetalHost
+	if status == nil {
+		return false, nil
+	}
+	if isConditionReason(remediation, commonconditions.SucceededType, remediationBareMetalHostError) {
+		return true, nil
+	}
+	// the outcome of a completed remediation is final
+	if !meta.IsStatusConditionTrue(remediation.Status.Conditions, commonconditions.ProcessingType) {
+		return false, nil
+	}
+
+	host := &unstructured.Unstructured{}
+	host.SetGroupVersionKind(bareMetalHostGVK)
+	key := client.ObjectKey{Name: status.Name, Namespace: status.Namespace}
+	if err := r.Get(ctx, key, host); err != nil {
+		if meta.IsNoMatchError(err) || apiErrors.IsNotFound(err) {
+			r.Log.Info("BareMetalHost not found, not following its provisioning", "BareMetalHost", key)
+			return false, nil
+		}
+		r.Log.Error(err, "could not get BareMetalHost", "BareMetalHost", key)
+		return false, err
+	}
+
+	state, _, _ := unstructured.NestedString(host.Object, "status", "provisioning", "state")
+	if state != status.ProvisioningState {
+		msg := fmt.Sprintf(bareMetalHostStateChangedMsg, key, status.ProvisioningState, state)
+		r.Log.Info(msg)
+		commonevents.NormalEvent(r.Recorder, remediation, bareMetalHostStateChangedReason, msg)
+		status.ProvisioningState = state
+	}
+	if status.DeprovisionedTime == nil && remediation.Status.MachineDeletionRequestedTime != nil &&
+		slices.Contains(bareMetalHostDeprovisionedStates, state) {
+		now := metav1.Now()
+		status.DeprovisionedTime = &now
+	}
+	if status.ReprovisionedTime == nil && status.DeprovisionedTime != nil && state == bareMetalHostProvisionedState {
+		now := metav1.Now()
+		status.ReprovisionedTime = &now
+	}
+
+	status.OperationalStatus, _, _ = unstructured.NestedString(host.Object, "status", "operationalStatus")
+	status.ErrorType, _, _ = unstructured.NestedString(host.Object, "status", "errorType")
+	status.ErrorMessage, _, _ = unstructured.NestedString(host.Object, "status", "errorMessage")
+	if status.OperationalStatus != bareMetalHostErrorOperationalStatus {
+		return false, nil
+	}
+
+	msg := fmt.Sprintf(bareMetalHostErrorMsg, key, status.ErrorType, status.ErrorMessage)
+	if updateRequired, err := r.updateConditions(remediationBareMetalHostError, remediation); err != nil {
+		return false, err
+	} else if updateRequired {
+		setConditionMessage(remediation, commonconditions.SucceededType, msg)
+		r.Log.Info(msg)
+		commonevents.WarningEvent(r.Recorder, remediation, string(remediationBareMetalHostError), msg)
+	}
+	return true, nil
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	commonconditions "github.com/medik8s/common/pkg/conditions"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/medik8s/machine-deletion-remediation/api/v1alpha1"
+)
+
+const (
+	quorumAtRiskErrorMsg = "deleting the control plane machine would put the etcd quorum at risk: %d out of %d control plane members would be healthy, while %d are required"
+)
+
+// controlPlaneOwnerKinds are the Kinds of the Machine owners handling control plane Machines
+var controlPlaneOwnerKinds = map[string]bool{
+	"ControlPlaneMachineSet": true,
+	"KubeadmControlPlane":    true,
+}
+
+// isControlPlaneQuorumAtRisk checks if deleting the given Machine would leave less than a quorum of healthy control
+// plane members. Members are not considered healthy if their Machine is being deleted, if they are targeted by another
+// ongoing remediation, or if their Node is not Ready. Machines not belonging to the control plane are never at risk.
+// A message describing the risk is returned together with the result.
+func (r *MachineDeletionRemediationReconciler) isControlPlaneQuorumAtRisk(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation, backend machineBackend, machine client.Object) (bool, string, error) {
+	ownerName, ownerKind, err := backend.getMachineOwnerNameKind(ctx, machine)
+	if err != nil || !controlPlaneOwnerKinds[ownerKind] {
+		return false, "", err
+	}
+
+	replicas, err := r.getMachineOwnerSpecReplicas(ctx, backend, ownerKind, ownerName, machine.GetNamespace())
+	if err != nil {
+		return false, "", err
+	}
+
+	machines, err := backend.listMachines(ctx, machine.GetNamespace())
+	if err != nil {
+		return false, "", err
+	}
+
+	remediatedMachines, remediatedNodes, err := r.getOngoingRemediationTargets(ctx, remediation)
+	if err != nil {
+		return false, "", err
+	}
+
+	isOwned, err := r.getOwnedMachineFilter(ctx, backend, ownerKind, ownerName, machine.GetNamespace())
+	if err != nil {
+		return false, "", err
+	}
+
+	healthy := 0
+	for _, member := range machines {
+		key := client.ObjectKeyFromObject(member)
+		if member.GetName() == machine.GetName() ||
+			!member.GetDeletionTimestamp().IsZero() ||
+			!isOwned(member) ||
+			remediatedMachines[key] {
+			continue
+		}
+		node, err := r.getMachineNode(ctx, backend, member)
+		if err != nil {
+			return false, "", err
+		}
+		if node == nil || remediatedNodes[node.GetName()] || !isNodeReady(node) {
+			continue
+		}
+		healthy++
+	}
+
+	quorum := replicas/2 + 1
+	r.Log.Info("verifying control plane quorum", "owner kind", ownerKind, "owner name", ownerName,
+		"replicas", replicas, "healthy members after deletion", healthy, "quorum", quorum)
+	if healthy < quorum {
+		return true, fmt.Sprintf(quorumAtRiskErrorMsg, healthy, replicas, quorum), nil
+	}
+	return false, "", nil
+}
+
+// getOngoingRemediationTargets returns the Machines and the Nodes targeted by the remediations, other than the given
+// one, which are not completed yet
+func (r *MachineDeletionRemediationReconciler) getOngoingRemediationTargets(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation) (map[client.ObjectKey]bool, map[string]bool, error) {
+	remediations := &v1alpha1.MachineDeletionRemediationList{}
+	if err := r.List(ctx, remediations); err != nil {
+		return nil, nil, err
+	}
+
+	machines, nodes := make(map[client.ObjectKey]bool), make(map[string]bool)
+	for i := range remediations.Items {
+		other := &remediations.Items[i]
+		if other.GetUID() == remediation.GetUID() ||
+			meta.IsStatusConditionPresentAndEqual(other.Status.Conditions, commonconditions.ProcessingType, metav1.ConditionFalse) {
+			continue
+		}
+		if other.Status.Machine != nil {
+			machines[client.ObjectKey{Name: other.Status.Machine.Name, Namespace: other.Status.Machine.Namespace}] = true
+		}
+		nodes[getNodeName(other)] = true
+	}
+	return machines, nodes, nil
+}
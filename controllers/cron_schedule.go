@@ -0,0 +1,174 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronScheduleSearchLimit is how far in the future the next time matching a cron schedule is searched
+const cronScheduleSearchLimit = 5 * 365 * 24 * time.Hour
+
+// cronSchedule is a parsed cron expression, with the standard minute, hour, day of month, month and day of week fields.
+// Each field is stored as a bitset of the matching values.
+type cronSchedule struct {
+	minute, hour, dayOfMonth, month, dayOfWeek uint64
+	// when both the day of month and the day of week are restricted, a day matching either of them matches
+	dayOfMonthRestricted, dayOfWeekRestricted bool
+}
+
+// cronField describes the valid values of a cron expression field
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	cronMinute     = cronField{name: "minute", min: 0, max: 59}
+	cronHour       = cronField{name: "hour", min: 0, max: 23}
+	cronDayOfMonth = cronField{name: "day of month", min: 1, max: 31}
+	cronMonth      = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6, "JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+	}}
+	// both 0 and 7 are Sunday
+	cronDayOfWeek = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+	}}
+)
+
+// parseCronSchedule parses a cron expression with 5 fields. Each field is either "*", a value, a range ("1-5"), or a
+// comma separated list of them, optionally with a step ("*/15", "0-30/10"). Months and days of week can also be set by
+// their 3 letters English name.
+func parseCronSchedule(expression string) (*cronSchedule, error) {
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q: expected 5 fields (minute, hour, day of month, month, day of week), got %d", expression, len(fields))
+	}
+
+	var bitsets [5]uint64
+	for i, field := range []cronField{cronMinute, cronHour, cronDayOfMonth, cronMonth, cronDayOfWeek} {
+		bits, err := field.parse(fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %v", expression, err)
+		}
+		bitsets[i] = bits
+	}
+	if bitsets[4]&(1<<7) != 0 {
+		bitsets[4] = bitsets[4]&^(1<<7) | 1
+	}
+
+	return &cronSchedule{
+		minute:               bitsets[0],
+		hour:                 bitsets[1],
+		dayOfMonth:           bitsets[2],
+		month:                bitsets[3],
+		dayOfWeek:            bitsets[4],
+		dayOfMonthRestricted: !strings.HasPrefix(fields[2], "*"),
+		dayOfWeekRestricted:  !strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+// parse returns the bitset of the values matching the field's expression
+func (f cronField) parse(expression string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expression, ",") {
+		valueRange, stepValue, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepValue); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid %s step %q: must be a positive number", f.name, stepValue)
+			}
+		}
+
+		start, end := f.min, f.max
+		if valueRange != "*" {
+			first, last, isRange := strings.Cut(valueRange, "-")
+			var err error
+			if start, err = f.value(first); err != nil {
+				return 0, err
+			}
+			if isRange {
+				if end, err = f.value(last); err != nil {
+					return 0, err
+				}
+			} else if !hasStep {
+				end = start
+			}
+		}
+		if start > end {
+			return 0, fmt.Errorf("invalid %s range %q: the start is after the end", f.name, valueRange)
+		}
+
+		for value := start; value <= end; value += step {
+			bits |= 1 << value
+		}
+	}
+	return bits, nil
+}
+
+// value parses a single value of the field, either a number or a name
+func (f cronField) value(value string) (int, error) {
+	if named, exists := f.names[strings.ToUpper(value)]; exists {
+		return named, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < f.min || number > f.max {
+		return 0, fmt.Errorf("invalid %s %q: must be between %d and %d", f.name, value, f.min, f.max)
+	}
+	return number, nil
+}
+
+// next returns the first time matching the schedule strictly after the given time, in its location. It returns the
+// zero time if no time matches within the cronScheduleSearchLimit, e.g. for "0 0 30 2 *".
+func (s *cronSchedule) next(after time.Time) time.Time {
+	location := after.Location()
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(cronScheduleSearchLimit)
+	for t.Before(limit) {
+		switch {
+		case !hasBit(s.month, int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, location)
+		case !s.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, location)
+		case !hasBit(s.hour, t.Hour()):
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, location)
+		case !hasBit(s.minute, t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// matchesDay checks if the day of the given time matches the day of month and day of week fields
+func (s *cronSchedule) matchesDay(t time.Time) bool {
+	dayOfMonth, dayOfWeek := hasBit(s.dayOfMonth, t.Day()), hasBit(s.dayOfWeek, int(t.Weekday()))
+	if s.dayOfMonthRestricted && s.dayOfWeekRestricted {
+		return dayOfMonth || dayOfWeek
+	}
+	return dayOfMonth && dayOfWeek
+}
+
+func hasBit(bits uint64, value int) bool {
+	return bits&(1<<value) != 0
+}
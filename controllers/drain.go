@@ -0,0 +1,234 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	commonevents "github.com/medik8s/common/pkg/events"
+
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/medik8s/machine-deletion-remediation/api/v1alpha1"
+)
+
+const (
+	// podNodeNameField is the field index of the Pods by the name of their Node
+	podNodeNameField = "spec.nodeName"
+	// mirrorPodAnnotation is set by the kubelet on the mirror Pods of static Pods, which cannot be evicted
+	mirrorPodAnnotation = "kubernetes.io/config.mirror"
+
+	nodeDrainStartedReason   = "NodeDrainStarted"
+	nodeDrainForcedReason    = "NodeDrainForced"
+	nodeDrainCompletedReason = "NodeDrainCompleted"
+
+	nodeDrainStartedMsg   = "cordoned node %s, evicting its pods"
+	nodeDrainForcedMsg    = "pods of node %s were not evicted within %s, deleting the remaining pods without grace period"
+	nodeDrainCompletedMsg = "all the pods of node %s were evicted"
+)
+
+// isDrainEnabled checks if MDR has to drain the Node before deleting the Machine
+func isDrainEnabled(remediation *v1alpha1.MachineDeletionRemediation) bool {
+	return remediation.Spec.Drain != nil
+}
+
+// drainNode cordons the Node and evicts its Pods, respecting their PodDisruptionBudgets until the drain's ForceAfter
+// timeout expires. The drain progress is saved in the remediation's status. It returns true once all the Pods are
+// gone, and otherwise the time to wait before checking the drain progress again.
+func (r *MachineDeletionRemediationReconciler) drainNode(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation, node *v1.Node) (bool, time.Duration, error) {
+	status := remediation.Status.Drain
+	if status == nil || status.NodeName != node.Name {
+		now := metav1.Now()
+		status = &v1alpha1.DrainStatus{NodeName: node.Name, StartTime: &now}
+		remediation.Status.Drain = status
+		msg := fmt.Sprintf(nodeDrainStartedMsg, node.Name)
+		r.Log.Info(msg)
+		commonevents.NormalEvent(r.Recorder, remediation, nodeDrainStartedReason, msg)
+	}
+	if status.CompletionTime != nil {
+		return true, 0, nil
+	}
+
+	if cordoned, err := r.cordonNode(ctx, node); err != nil {
+		return false, 0, err
+	} else if cordoned {
+		status.Cordoned = true
+	}
+
+	pods, err := r.getPodsToEvict(ctx, node)
+	if err != nil {
+		return false, 0, err
+	}
+
+	forced, remaining := isDrainForced(remediation)
+	if forced && !status.Forced {
+		status.Forced = true
+		msg := fmt.Sprintf(nodeDrainForcedMsg, node.Name, remediation.Spec.Drain.ForceAfter.Duration)
+		r.Log.Info(msg)
+		commonevents.WarningEvent(r.Recorder, remediation, nodeDrainForcedReason, msg)
+	}
+
+	gracePeriodSeconds := getDrainGracePeriodSeconds(remediation)
+	var blockedPods []string
+	var pendingPods int
+	for i := range pods {
+		pod := &pods[i]
+		terminating := !pod.GetDeletionTimestamp().IsZero()
+		if forced {
+			// Pods are deleted immediately, including the terminating ones, whose termination cannot complete if the
+			// kubelet is unreachable
+			err = r.Delete(ctx, pod, &client.DeleteOptions{GracePeriodSeconds: ptr.To[int64](0)})
+			if err == nil && terminating {
+				// the termination is not waited for, as it may never complete, e.g. when the kubelet is unreachable or
+				// a finalizer holds the Pod, which the kubelet does not remove
+				continue
+			}
+		} else if terminating {
+			pendingPods++
+			continue
+		} else {
+			err = r.SubResource("eviction").Create(ctx, pod, &policyv1.Eviction{
+				ObjectMeta:    metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+				DeleteOptions: &metav1.DeleteOptions{GracePeriodSeconds: gracePeriodSeconds},
+			})
+		}
+		if apiErrors.IsNotFound(err) {
+			continue
+		} else if apiErrors.IsTooManyRequests(err) {
+			// the eviction would violate a PodDisruptionBudget
+			blockedPods = append(blockedPods, client.ObjectKeyFromObject(pod).String())
+		} else if err != nil {
+			r.Log.Error(err, "could not evict or delete pod", "pod", pod.Name, "namespace", pod.Namespace, "node", node.Name)
+			return false, 0, err
+		}
+		pendingPods++
+	}
+
+	status.PendingPods = int32(pendingPods)
+	status.BlockedPods = blockedPods
+	if pendingPods > 0 {
+		r.Log.Info("waiting for the node to be drained", "node", node.Name, "pending pods", pendingPods, "blocked pods", len(blockedPods))
+		requeueAfter := r.getPollInterval(remediation)
+		if !forced && remaining > 0 && remaining < requeueAfter {
+			requeueAfter = remaining
+		}
+		return false, requeueAfter, nil
+	}
+
+	now := metav1.Now()
+	status.CompletionTime = &now
+	msg := fmt.Sprintf(nodeDrainCompletedMsg, node.Name)
+	r.Log.Info(msg)
+	commonevents.NormalEvent(r.Recorder, remediation, nodeDrainCompletedReason, msg)
+	return true, 0, nil
+}
+
+// cordonNode marks the Node as unschedulable, and returns true if it was schedulable
+func (r *MachineDeletionRemediationReconciler) cordonNode(ctx context.Context, node *v1.Node) (bool, error) {
+	if node.Spec.Unschedulable {
+		return false, nil
+	}
+	patch := client.MergeFrom(node.DeepCopy())
+	node.Spec.Unschedulable = true
+	if err := r.Patch(ctx, node, patch); err != nil {
+		r.Log.Error(err, "could not cordon node", "node", node.Name)
+		return false, err
+	}
+	return true, nil
+}
+
+// uncordonNode marks the Node as schedulable again, and returns true if it was unschedulable
+func (r *MachineDeletionRemediationReconciler) uncordonNode(ctx context.Context, nodeName string) (bool, error) {
+	node := &v1.Node{}
+	if err := r.Get(ctx, client.ObjectKey{Name: nodeName}, node); err != nil {
+		if apiErrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if !node.Spec.Unschedulable {
+		return false, nil
+	}
+	patch := client.MergeFrom(node.DeepCopy())
+	node.Spec.Unschedulable = false
+	if err := r.Patch(ctx, node, patch); err != nil {
+		r.Log.Error(err, "could not uncordon node", "node", node.Name)
+		return false, err
+	}
+	return true, nil
+}
+
+// getPodsToEvict returns the Pods of the Node which have to be evicted. DaemonSet Pods, mirror Pods and completed
+// Pods are ignored.
+func (r *MachineDeletionRemediationReconciler) getPodsToEvict(ctx context.Context, node *v1.Node) ([]v1.Pod, error) {
+	allPods := &v1.PodList{}
+	if err := r.List(ctx, allPods, client.MatchingFields{podNodeNameField: node.Name}); err != nil {
+		return nil, err
+	}
+
+	var pods []v1.Pod
+	for _, pod := range allPods.Items {
+		if _, isMirror := pod.Annotations[mirrorPodAnnotation]; isMirror ||
+			isDaemonSetPod(&pod) ||
+			pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		pods = append(pods, pod)
+	}
+	return pods, nil
+}
+
+// isDaemonSetPod checks if the Pod is controlled by a DaemonSet, which would recreate it on the cordoned Node anyway
+func isDaemonSetPod(pod *v1.Pod) bool {
+	controller := metav1.GetControllerOf(pod)
+	return controller != nil && controller.Kind == "DaemonSet"
+}
+
+// isDrainForced returns whether the drain's ForceAfter timeout expired, and the time left before it expires
+func isDrainForced(remediation *v1alpha1.MachineDeletionRemediation) (bool, time.Duration) {
+	forceAfter := remediation.Spec.Drain.ForceAfter
+	if forceAfter == nil || remediation.Status.Drain == nil || remediation.Status.Drain.StartTime == nil {
+		return false, 0
+	}
+	remaining := forceAfter.Duration - time.Since(remediation.Status.Drain.StartTime.Time)
+	return remaining <= 0, remaining
+}
+
+// getDrainGracePeriodSeconds returns the termination grace period of the drained Pods, or nil to use the Pods' own
+func getDrainGracePeriodSeconds(remediation *v1alpha1.MachineDeletionRemediation) *int64 {
+	if gracePeriod := remediation.Spec.Drain.GracePeriod; gracePeriod != nil {
+		seconds := int64(gracePeriod.Duration.Seconds())
+		return &seconds
+	}
+	return nil
+}
+
+// indexPodsByNodeName allows listing the Pods of a Node from the cache
+func indexPodsByNodeName(obj client.Object) []string {
+	pod, ok := obj.(*v1.Pod)
+	if !ok || pod.Spec.NodeName == "" {
+		return nil
+	}
+	return []string{pod.Spec.NodeName}
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	commonevents "github.com/medik8s/common/pkg/events"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/medik8s/machine-deletion-remediation/api/v1alpha1"
+)
+
+const (
+	wouldDeleteMachineReason = "WouldDeleteMachine"
+	wouldDeleteMachineMsg    = "dry run: would delete machine %s of node %s"
+)
+
+// isDryRunEnabled checks if the remediation has to skip the Machine deletion, because of its own DryRun field or of
+// the operator configuration
+func isDryRunEnabled(config v1alpha1.MachineDeletionRemediationConfigSpec, remediation *v1alpha1.MachineDeletionRemediation) bool {
+	return config.DryRun || remediation.Spec.DryRun || isDryRunCompleted(remediation)
+}
+
+// isDryRunCompleted checks if the remediation already completed in dry run mode. The DryRun condition records the
+// decision, so that the Machine is not deleted if dry run is disabled in the operator configuration afterwards.
+func isDryRunCompleted(remediation *v1alpha1.MachineDeletionRemediation) bool {
+	return meta.IsStatusConditionTrue(remediation.Status.Conditions, v1alpha1.DryRunConditionType)
+}
+
+// completeDryRun completes the remediation without deleting the Machine. The DryRun condition and an event report the
+// Machine which would have been deleted, while the Succeeded condition stays Unknown, so that NHC does not escalate to
+// its next remediation.
+func (r *MachineDeletionRemediationReconciler) completeDryRun(remediation *v1alpha1.MachineDeletionRemediation, machine client.Object) error {
+	updateRequired, err := r.updateConditions(remediationSkippedDryRun, remediation)
+	if err != nil || !updateRequired {
+		return err
+	}
+
+	msg := fmt.Sprintf(wouldDeleteMachineMsg, client.ObjectKeyFromObject(machine), getNodeName(remediation))
+	meta.SetStatusCondition(&remediation.Status.Conditions, metav1.Condition{
+		Type:    v1alpha1.DryRunConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  v1alpha1.MachineDeletionSkippedReason,
+		Message: msg,
+	})
+	r.Log.Info(msg)
+	commonevents.NormalEvent(r.Recorder, remediation, wouldDeleteMachineReason, msg)
+	return nil
+}
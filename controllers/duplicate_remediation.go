@@ -0,0 +1,107 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	commonconditions "github.com/medik8s/common/pkg/conditions"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"github.com/medik8s/machine-deletion-remediation/api/v1alpha1"
+)
+
+const (
+	duplicateRemediationErrorMsg = "ignoring remediation of the machine: it is already being remediated by MachineDeletionRemediation %s"
+)
+
+// getOwningRemediation returns the remediation owning the deletion of the given Machine, if it is another ongoing
+// remediation than the given one. Remediations can target the same Machine when created both by MachineHealthCheck,
+// through the remediation's ownerReference, and by NodeHealthCheck, through the Machine's Node. The remediation which
+// requested the Machine deletion owns it, otherwise the first created one does.
+func (r *MachineDeletionRemediationReconciler) getOwningRemediation(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation, machine client.Object) (*v1alpha1.MachineDeletionRemediation, error) {
+	remediations := &v1alpha1.MachineDeletionRemediationList{}
+	if err := r.List(ctx, remediations); err != nil {
+		return nil, err
+	}
+
+	machineKey, err := r.getMachineKey(machine)
+	if err != nil {
+		return nil, err
+	}
+	var owner *v1alpha1.MachineDeletionRemediation
+	for i := range remediations.Items {
+		other := &remediations.Items[i]
+		if other.GetUID() == remediation.GetUID() ||
+			meta.IsStatusConditionPresentAndEqual(other.Status.Conditions, commonconditions.ProcessingType, metav1.ConditionFalse) {
+			continue
+		}
+
+		if otherMachineKey, err := r.getRemediationMachineKey(ctx, other); err != nil || otherMachineKey != machineKey {
+			// remediations whose Machine cannot be found do not own any Machine
+			continue
+		}
+
+		if other.Status.MachineDeletionRequestedTime != nil {
+			return other, nil
+		}
+		if isCreatedBefore(other, remediation) && (owner == nil || isCreatedBefore(other, owner)) {
+			owner = other
+		}
+	}
+	return owner, nil
+}
+
+// machineKey identifies a Machine across the Machine APIs, which use the same Kind in different API groups
+type machineKey struct {
+	schema.GroupKind
+	client.ObjectKey
+}
+
+// getMachineKey returns the key of the given Machine
+func (r *MachineDeletionRemediationReconciler) getMachineKey(machine client.Object) (machineKey, error) {
+	gvk, err := apiutil.GVKForObject(machine, r.Client.Scheme())
+	if err != nil {
+		return machineKey{}, err
+	}
+	return machineKey{GroupKind: gvk.GroupKind(), ObjectKey: client.ObjectKeyFromObject(machine)}, nil
+}
+
+// getRemediationMachineKey returns the key of the Machine targeted by the remediation, as reported in its status once
+// found, or as resolved from its ownerReference or Node otherwise
+func (r *MachineDeletionRemediationReconciler) getRemediationMachineKey(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation) (machineKey, error) {
+	if machine := remediation.Status.Machine; machine != nil {
+		gv, err := schema.ParseGroupVersion(machine.APIVersion)
+		if err != nil {
+			return machineKey{}, err
+		}
+		return machineKey{
+			GroupKind: schema.GroupKind{Group: gv.Group, Kind: machine.Kind},
+			ObjectKey: client.ObjectKey{Name: machine.Name, Namespace: machine.Namespace},
+		}, nil
+	}
+	machine, err := r.resolveMachine(ctx, remediation)
+	if err != nil {
+		return machineKey{}, err
+	}
+	return r.getMachineKey(machine)
+}
@@ -0,0 +1,277 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	commonannotations "github.com/medik8s/common/pkg/annotations"
+	commonconditions "github.com/medik8s/common/pkg/conditions"
+	commonevents "github.com/medik8s/common/pkg/events"
+	"github.com/pkg/errors"
+
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/medik8s/machine-deletion-remediation/api/v1alpha1"
+)
+
+const (
+	templateKindSuffix = "Template"
+
+	fallbackRemediationStartedMsg          = "%s; remediating the node with %s %s"
+	fallbackRemediationSucceededMsg        = "%s %s succeeded"
+	fallbackRemediationFailedMsg           = "%s %s failed: %s"
+	fallbackRemediationDeletedMsg          = "%s %s was deleted before completing"
+	fallbackRemediationExistsMsg           = "%s; %s %s already exists and is not owned by the remediation"
+	fallbackRemediationTemplateNotFoundMsg = "%s; fallback remediation template %s %s not found"
+	fallbackRemediationForbiddenMsg        = "%s; not allowed to %s %s %s, grant the fallback remediation permissions to the operator: %v"
+	invalidFallbackRemediationTemplateMsg  = "%s; invalid fallback remediation template %s %s: %s"
+)
+
+// isFallbackRemediationEnabled checks if MDR has to create a remediation with the fallback remediation template when
+// the Machine cannot be deleted. No remediation is created in dry run mode.
+func isFallbackRemediationEnabled(config v1alpha1.MachineDeletionRemediationConfigSpec, remediation *v1alpha1.MachineDeletionRemediation) bool {
+	return remediation.Spec.FallbackRemediationTemplate != nil && !isDryRunEnabled(config, remediation)
+}
+
+// isFallbackRemediationStarted checks if the remediation fell back to the fallback remediation template, regardless
+// of whether the fallback remediation could be created
+func isFallbackRemediationStarted(remediation *v1alpha1.MachineDeletionRemediation) bool {
+	return remediation.Status.FallbackRemediation != nil ||
+		isConditionReason(remediation, commonconditions.SucceededType, remediationFallbackFailed)
+}
+
+// startFallbackRemediation creates a remediation of the Node with the fallback remediation template, because the
+// Machine cannot be deleted for the given reason. The remediation then succeeds or fails with the created one.
+func (r *MachineDeletionRemediationReconciler) startFallbackRemediation(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation, reason conditionChangeReason, reasonMsg string) error {
+	ref := remediation.Spec.FallbackRemediationTemplate
+	template := &unstructured.Unstructured{}
+	template.SetAPIVersion(ref.APIVersion)
+	template.SetKind(ref.Kind)
+	key := client.ObjectKey{Name: ref.Name, Namespace: remediation.GetNamespace()}
+	if err := r.Get(ctx, key, template); err != nil {
+		if meta.IsNoMatchError(err) || apiErrors.IsNotFound(err) {
+			return r.failFallbackRemediation(remediation, fmt.Sprintf(fallbackRemediationTemplateNotFoundMsg, reasonMsg, ref.Kind, key))
+		} else if apiErrors.IsForbidden(err) {
+			return r.failFallbackRemediation(remediation, fmt.Sprintf(fallbackRemediationForbiddenMsg, reasonMsg, "get", ref.Kind, key, err))
+		}
+		r.Log.Error(err, "could not get the fallback remediation template", "kind", ref.Kind, "template", key)
+		return err
+	}
+
+	fallback, err := r.newFallbackRemediation(remediation, template)
+	if err != nil {
+		return r.failFallbackRemediation(remediation, fmt.Sprintf(invalidFallbackRemediationTemplateMsg, reasonMsg, ref.Kind, key, err))
+	}
+	if err := r.Create(ctx, fallback); err != nil {
+		if apiErrors.IsForbidden(err) {
+			return r.failFallbackRemediation(remediation, fmt.Sprintf(fallbackRemediationForbiddenMsg, reasonMsg, "create", fallback.GetKind(), client.ObjectKeyFromObject(fallback), err))
+		} else if !apiErrors.IsAlreadyExists(err) {
+			r.Log.Error(err, "could not create the fallback remediation", "kind", fallback.GetKind(), "name", fallback.GetName())
+			return err
+		}
+		// created by a previous reconciliation whose status update failed
+		if err := r.Get(ctx, client.ObjectKeyFromObject(fallback), fallback); err != nil {
+			return err
+		}
+		if !metav1.IsControlledBy(fallback, remediation) {
+			return r.failFallbackRemediation(remediation, fmt.Sprintf(fallbackRemediationExistsMsg, reasonMsg, fallback.GetKind(), client.ObjectKeyFromObject(fallback)))
+		}
+	}
+
+	now := metav1.Now()
+	remediation.Status.FallbackRemediation = &v1alpha1.FallbackRemediationStatus{
+		Remediation: v1alpha1.ObjectReference{
+			APIVersion: fallback.GetAPIVersion(),
+			Kind:       fallback.GetKind(),
+			Name:       fallback.GetName(),
+			Namespace:  fallback.GetNamespace(),
+		},
+		Reason:      string(reason),
+		CreatedTime: &now,
+	}
+	msg := fmt.Sprintf(fallbackRemediationStartedMsg, reasonMsg, fallback.GetKind(), client.ObjectKeyFromObject(fallback))
+	if updateRequired, err := r.updateConditions(remediationFallbackStarted, remediation); err != nil {
+		return err
+	} else if updateRequired {
+		setConditionMessage(remediation, commonconditions.ProcessingType, msg)
+		r.Log.Info(msg)
+		commonevents.NormalEvent(r.Recorder, remediation, string(remediationFallbackStarted), msg)
+	}
+	return nil
+}
+
+// newFallbackRemediation returns the remediation of the Node created from the fallback remediation template, the
+// same way NodeHealthCheck creates remediations from templates
+func (r *MachineDeletionRemediationReconciler) newFallbackRemediation(remediation *v1alpha1.MachineDeletionRemediation, template *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	spec, found, err := unstructured.NestedMap(template.Object, "spec", "template", "spec")
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid spec.template.spec")
+	}
+	fallback := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	if found {
+		fallback.Object["spec"] = spec
+	}
+	fallback.SetAPIVersion(template.GetAPIVersion())
+	fallback.SetKind(strings.TrimSuffix(template.GetKind(), templateKindSuffix))
+	fallback.SetNamespace(remediation.GetNamespace())
+
+	// remediators supporting multiple templates read the Node's name from the annotation, the other ones from the
+	// remediation's name
+	nodeName := getNodeName(remediation)
+	fallback.SetName(nodeName)
+	if template.GetAnnotations()[commonannotations.MultipleTemplatesSupportedAnnotation] == "true" {
+		fallback.SetName(remediation.GetName())
+	}
+	fallback.SetAnnotations(map[string]string{commonannotations.NodeNameAnnotation: nodeName})
+
+	if err := controllerutil.SetControllerReference(remediation, fallback, r.Client.Scheme()); err != nil {
+		return nil, err
+	}
+	return fallback, nil
+}
+
+// followFallbackRemediation reports the outcome of the fallback remediation, according to its Succeeded condition.
+// It returns the time to wait before checking it again, if it is not completed yet.
+func (r *MachineDeletionRemediationReconciler) followFallbackRemediation(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation) (time.Duration, error) {
+	status := remediation.Status.FallbackRemediation
+	// the outcome of a completed fallback remediation is final
+	if status == nil || status.CompletedTime != nil {
+		return 0, nil
+	}
+
+	ref := status.Remediation
+	key := client.ObjectKey{Name: ref.Name, Namespace: ref.Namespace}
+	fallback, err := r.getFallbackRemediation(ctx, ref)
+	if apiErrors.IsForbidden(err) {
+		now := metav1.Now()
+		status.CompletedTime = &now
+		return 0, r.failFallbackRemediation(remediation, fmt.Sprintf(fallbackRemediationForbiddenMsg, "could not follow the fallback remediation", "get", ref.Kind, key, err))
+	} else if err != nil {
+		r.Log.Error(err, "could not get the fallback remediation", "kind", ref.Kind, "name", key)
+		return 0, err
+	} else if fallback == nil {
+		now := metav1.Now()
+		status.CompletedTime = &now
+		return 0, r.failFallbackRemediation(remediation, fmt.Sprintf(fallbackRemediationDeletedMsg, ref.Kind, key))
+	}
+
+	succeeded, message := getUnstructuredCondition(fallback, commonconditions.SucceededType)
+	switch succeeded {
+	case metav1.ConditionTrue:
+		now := metav1.Now()
+		status.CompletedTime = &now
+		if updateRequired, err := r.updateConditions(remediationFallbackSucceeded, remediation); err != nil {
+			return 0, err
+		} else if updateRequired {
+			msg := fmt.Sprintf(fallbackRemediationSucceededMsg, ref.Kind, key)
+			setConditionMessage(remediation, commonconditions.SucceededType, msg)
+			r.Log.Info(msg)
+			commonevents.NormalEvent(r.Recorder, remediation, string(remediationFallbackSucceeded), msg)
+		}
+		return 0, nil
+	case metav1.ConditionFalse:
+		now := metav1.Now()
+		status.CompletedTime = &now
+		return 0, r.failFallbackRemediation(remediation, fmt.Sprintf(fallbackRemediationFailedMsg, ref.Kind, key, message))
+	default:
+		r.Log.Info("waiting for the fallback remediation to complete", "kind", ref.Kind, "name", key)
+		return r.getPollInterval(remediation), nil
+	}
+}
+
+// failFallbackRemediation fails the remediation because the fallback remediation could not be created or failed
+func (r *MachineDeletionRemediationReconciler) failFallbackRemediation(remediation *v1alpha1.MachineDeletionRemediation, msg string) error {
+	if updateRequired, err := r.updateConditions(remediationFallbackFailed, remediation); err != nil {
+		return err
+	} else if updateRequired {
+		setConditionMessage(remediation, commonconditions.SucceededType, msg)
+		r.Log.Info(msg)
+		commonevents.WarningEvent(r.Recorder, remediation, string(remediationFallbackFailed), msg)
+	}
+	return nil
+}
+
+// stopFallbackRemediation propagates the NHC timeout to the fallback remediation which is not completed yet, by
+// setting the same timeout annotation on it. Missing permissions do not prevent the remediation from being stopped.
+func (r *MachineDeletionRemediationReconciler) stopFallbackRemediation(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation) error {
+	status := remediation.Status.FallbackRemediation
+	if status == nil || status.CompletedTime != nil {
+		return nil
+	}
+	fallback, err := r.getFallbackRemediation(ctx, status.Remediation)
+	if apiErrors.IsForbidden(err) {
+		r.Log.Error(err, "not allowed to stop the fallback remediation", "kind", status.Remediation.Kind, "name", status.Remediation.Name)
+		return nil
+	} else if err != nil || fallback == nil {
+		return err
+	}
+	if _, exists := fallback.GetAnnotations()[commonannotations.NhcTimedOut]; exists {
+		return nil
+	}
+	patch := client.MergeFrom(fallback.DeepCopy())
+	annotations := fallback.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[commonannotations.NhcTimedOut] = time.Now().Format(time.RFC3339)
+	fallback.SetAnnotations(annotations)
+	if err := r.Patch(ctx, fallback, patch); err != nil {
+		if apiErrors.IsForbidden(err) {
+			r.Log.Error(err, "not allowed to stop the fallback remediation", "kind", fallback.GetKind(), "name", fallback.GetName())
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// getFallbackRemediation returns the fallback remediation, or nil if it does not exist anymore
+func (r *MachineDeletionRemediationReconciler) getFallbackRemediation(ctx context.Context, ref v1alpha1.ObjectReference) (*unstructured.Unstructured, error) {
+	fallback := &unstructured.Unstructured{}
+	fallback.SetAPIVersion(ref.APIVersion)
+	fallback.SetKind(ref.Kind)
+	if err := r.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: ref.Namespace}, fallback); err != nil {
+		if meta.IsNoMatchError(err) || apiErrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return fallback, nil
+}
+
+// getUnstructuredCondition returns the status and the message of the given condition of the object, or an empty status
+// if the condition is not set
+func getUnstructuredCondition(obj *unstructured.Unstructured, conditionType string) (metav1.ConditionStatus, string) {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		if condition, ok := c.(map[string]interface{}); ok && condition["type"] == conditionType {
+			status, _ := condition["status"].(string)
+			message, _ := condition["message"].(string)
+			return metav1.ConditionStatus(status), message
+		}
+	}
+	return "", ""
+}
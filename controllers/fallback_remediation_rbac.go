@@ -0,0 +1,146 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	// FallbackRemediationClusterRole is the aggregated ClusterRole granting the permissions needed by the fallback
+	// remediations, see config/rbac/fallback_remediation_clusterrole.yaml
+	FallbackRemediationClusterRole = "machine-deletion-remediation-fallback-remediation"
+)
+
+// fallbackRemediationBindBackoff retries binding the fallback remediation ClusterRole until it succeeds
+var fallbackRemediationBindBackoff = wait.Backoff{
+	Duration: time.Second,
+	Factor:   2,
+	Jitter:   0.1,
+	Steps:    math.MaxInt32,
+	Cap:      5 * time.Minute,
+}
+
+// FallbackRemediationBinder binds the aggregated fallback remediation ClusterRole to the operator's service account.
+// The ClusterRoleBinding is created by the operator, since its subject's namespace is the one the operator is installed
+// in, which is not known when the manifests are built, e.g. when installed with OLM.
+type FallbackRemediationBinder struct {
+	client.Client
+	// Reader is not cached, so that the ClusterRoles and ClusterRoleBindings are not watched
+	Reader client.Reader
+	Log    logr.Logger
+	// Namespace and ServiceAccount are the namespace and the name of the operator's service account
+	Namespace      string
+	ServiceAccount string
+}
+
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,verbs=get;bind,resourceNames=machine-deletion-remediation-fallback-remediation
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterrolebindings,verbs=create
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterrolebindings,verbs=get;update,resourceNames=machine-deletion-remediation-fallback-remediation
+
+// SetupWithManager binds the fallback remediation ClusterRole once the manager is started
+func (b *FallbackRemediationBinder) SetupWithManager(mgr ctrl.Manager) error {
+	return mgr.Add(manager.RunnableFunc(b.run))
+}
+
+// run binds the fallback remediation ClusterRole, retrying with backoff until the binding is created or updated, or
+// the manager stops
+func (b *FallbackRemediationBinder) run(ctx context.Context) error {
+	err := wait.ExponentialBackoffWithContext(ctx, fallbackRemediationBindBackoff, func(ctx context.Context) (bool, error) {
+		if err := b.bind(ctx); err != nil {
+			b.Log.Error(err, "could not bind the fallback remediation ClusterRole, retrying", "ClusterRole", FallbackRemediationClusterRole)
+			return false, nil
+		}
+		return true, nil
+	})
+	if ctx.Err() != nil {
+		// the manager is stopping
+		return nil
+	}
+	return err
+}
+
+// bind creates the ClusterRoleBinding of the fallback remediation ClusterRole, or updates its subject. It is owned by
+// the ClusterRole, so that it is deleted with it when the operator is uninstalled. A missing ClusterRole is not an
+// error, since there is nothing to bind then.
+func (b *FallbackRemediationBinder) bind(ctx context.Context) error {
+	role := &rbacv1.ClusterRole{}
+	if err := b.Reader.Get(ctx, client.ObjectKey{Name: FallbackRemediationClusterRole}, role); err != nil {
+		if apiErrors.IsNotFound(err) {
+			b.Log.Info("fallback remediation ClusterRole not found, the fallback remediations are not granted any permission", "ClusterRole", FallbackRemediationClusterRole)
+			return nil
+		}
+		return err
+	}
+
+	binding := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: FallbackRemediationClusterRole,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: rbacv1.SchemeGroupVersion.String(),
+				Kind:       "ClusterRole",
+				Name:       role.GetName(),
+				UID:        role.GetUID(),
+			}},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     FallbackRemediationClusterRole,
+		},
+		Subjects: []rbacv1.Subject{{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      b.ServiceAccount,
+			Namespace: b.Namespace,
+		}},
+	}
+	err := b.Create(ctx, binding)
+	if err == nil {
+		b.Log.Info("fallback remediation ClusterRole bound to the operator's service account", "ClusterRoleBinding", binding.GetName())
+		return nil
+	} else if !apiErrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	existing := &rbacv1.ClusterRoleBinding{}
+	if err = b.Reader.Get(ctx, client.ObjectKeyFromObject(binding), existing); err != nil {
+		return err
+	}
+	if reflect.DeepEqual(existing.Subjects, binding.Subjects) && reflect.DeepEqual(existing.OwnerReferences, binding.OwnerReferences) {
+		return nil
+	}
+	existing.Subjects = binding.Subjects
+	existing.OwnerReferences = binding.OwnerReferences
+	if err = b.Update(ctx, existing); err != nil {
+		return err
+	}
+	b.Log.Info("fallback remediation ClusterRoleBinding subject updated", "ClusterRoleBinding", binding.GetName())
+	return nil
+}
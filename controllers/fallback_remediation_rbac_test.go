@@ -0,0 +1,136 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Fallback remediation RBAC", func() {
+	var (
+		binder  *FallbackRemediationBinder
+		role    *rbacv1.ClusterRole
+		binding *rbacv1.ClusterRoleBinding
+	)
+
+	BeforeEach(func() {
+		binder = &FallbackRemediationBinder{
+			Client:         k8sClient,
+			Reader:         k8sClient,
+			Log:            ctrl.Log.WithName("test").WithName("FallbackRemediationBinder"),
+			Namespace:      operatorNamespace,
+			ServiceAccount: "machine-deletion-remediation-controller-manager",
+		}
+		role = &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: FallbackRemediationClusterRole}}
+		binding = &rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: FallbackRemediationClusterRole}}
+	})
+
+	When("the fallback remediation ClusterRole does not exist", func() {
+		It("does not bind it", func() {
+			Expect(binder.bind(context.Background())).To(Succeed())
+			Consistently(func() error {
+				return k8sClient.Get(context.Background(), client.ObjectKeyFromObject(binding), binding)
+			}, "2s", "1s").ShouldNot(Succeed())
+		})
+	})
+
+	When("the fallback remediation ClusterRole exists", func() {
+		BeforeEach(func() {
+			Expect(k8sClient.Create(context.Background(), role)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(context.Background(), role)).To(Succeed())
+				// envtest does not run the garbage collector
+				Expect(client.IgnoreNotFound(k8sClient.Delete(context.Background(), binding))).To(Succeed())
+			})
+		})
+
+		It("binds it to the operator's service account", func() {
+			verifyFallbackRemediationBinding(binding, role, binder)
+		})
+
+		It("updates the subject of an existing binding", func() {
+			binder.Namespace = "previous-namespace"
+			verifyFallbackRemediationBinding(binding, role, binder)
+
+			binder.Namespace = operatorNamespace
+			verifyFallbackRemediationBinding(binding, role, binder)
+		})
+
+		When("the API server fails", func() {
+			BeforeEach(func() {
+				binder.Reader = &failingReader{Reader: k8sClient, failures: 1}
+			})
+
+			It("returns the error", func() {
+				Expect(binder.bind(context.Background())).To(HaveOccurred())
+			})
+
+			It("retries until the ClusterRole is bound", func() {
+				Expect(binder.run(context.Background())).To(Succeed())
+				verifyFallbackRemediationBinding(binding, role, binder)
+			})
+
+			It("stops retrying when the manager stops", func() {
+				binder.Reader = &failingReader{Reader: k8sClient, failures: -1}
+				ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+				defer cancel()
+				Expect(binder.run(ctx)).To(Succeed())
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(binding), binding)).ToNot(Succeed())
+			})
+		})
+	})
+})
+
+// failingReader fails its first Gets, or all of them if failures is negative
+type failingReader struct {
+	client.Reader
+	failures int
+}
+
+func (r *failingReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if r.failures != 0 {
+		r.failures--
+		return errors.New("test error")
+	}
+	return r.Reader.Get(ctx, key, obj, opts...)
+}
+
+// verifyFallbackRemediationBinding binds the ClusterRole until the cached client returns the expected binding
+func verifyFallbackRemediationBinding(binding *rbacv1.ClusterRoleBinding, role *rbacv1.ClusterRole, binder *FallbackRemediationBinder) {
+	Eventually(func(g Gomega) {
+		g.Expect(binder.bind(context.Background())).To(Succeed())
+		g.Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(binding), binding)).To(Succeed())
+		g.Expect(binding.RoleRef.Name).To(Equal(FallbackRemediationClusterRole))
+		g.Expect(binding.Subjects).To(ConsistOf(rbacv1.Subject{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      binder.ServiceAccount,
+			Namespace: binder.Namespace,
+		}))
+		g.Expect(binding.OwnerReferences).To(HaveLen(1))
+		g.Expect(binding.OwnerReferences[0].UID).To(Equal(role.UID))
+	}, "10s", "1s").Should(Succeed())
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	commonevents "github.com/medik8s/common/pkg/events"
+
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/medik8s/machine-deletion-remediation/api/v1alpha1"
+)
+
+const (
+	// CleanupFinalizer lets MDR clean up what it set on the Machine and the Node before the remediation is deleted
+	CleanupFinalizer = "machine-deletion-remediation.medik8s.io/cleanup"
+
+	remediationDeletedReason = "RemediationDeleted"
+	remediationDeletedMsg    = "remediation of node %s deleted in phase %s after %s"
+)
+
+// addCleanupFinalizer adds the CleanupFinalizer to the remediation. Only the finalizers and the resourceVersion are
+// copied from the updated remediation, so that the pending status changes are kept.
+func (r *MachineDeletionRemediationReconciler) addCleanupFinalizer(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation) error {
+	if controllerutil.ContainsFinalizer(remediation, CleanupFinalizer) {
+		return nil
+	}
+	updated := remediation.DeepCopy()
+	patch := client.MergeFrom(remediation)
+	controllerutil.AddFinalizer(updated, CleanupFinalizer)
+	if err := r.Patch(ctx, updated, patch); err != nil {
+		r.Log.Error(err, "could not add finalizer", "finalizer", CleanupFinalizer)
+		return err
+	}
+	remediation.SetFinalizers(updated.GetFinalizers())
+	remediation.SetResourceVersion(updated.GetResourceVersion())
+	return nil
+}
+
+// finalizeRemediation cleans up before letting the remediation be deleted: MDR's preTerminate hook is removed from the
+// Machine, and the Node is uncordoned if MDR cordoned it but the Machine deletion was not requested yet. An event
+// summarizes the remediation and the cleanup.
+func (r *MachineDeletionRemediationReconciler) finalizeRemediation(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation) error {
+	if !controllerutil.ContainsFinalizer(remediation, CleanupFinalizer) {
+		return nil
+	}
+
+	var cleanups []string
+	if removed, err := r.cleanupPreTerminateHook(ctx, remediation); err != nil {
+		return err
+	} else if removed {
+		cleanups = append(cleanups, "removed the preTerminate hook from machine "+remediation.Status.Machine.Name)
+	}
+
+	if drain := remediation.Status.Drain; drain != nil && drain.Cordoned && remediation.Status.MachineDeletionRequestedTime == nil {
+		if uncordoned, err := r.uncordonNode(ctx, drain.NodeName); err != nil {
+			return err
+		} else if uncordoned {
+			cleanups = append(cleanups, "uncordoned node "+drain.NodeName)
+		}
+	}
+
+	msg := fmt.Sprintf(remediationDeletedMsg, getNodeName(remediation), getRemediationPhase(remediation),
+		time.Since(remediation.GetCreationTimestamp().Time).Round(time.Second))
+	if len(cleanups) > 0 {
+		msg += ": " + strings.Join(cleanups, ", ")
+	}
+	r.Log.Info(msg)
+	commonevents.NormalEvent(r.Recorder, remediation, remediationDeletedReason, msg)
+
+	patch := client.MergeFrom(remediation.DeepCopy())
+	controllerutil.RemoveFinalizer(remediation, CleanupFinalizer)
+	if err := r.Patch(ctx, remediation, patch); err != nil && !apiErrors.IsNotFound(err) {
+		r.Log.Error(err, "could not remove finalizer", "finalizer", CleanupFinalizer)
+		return err
+	}
+	return nil
+}
+
+// cleanupPreTerminateHook removes MDR's preTerminate hook from the remediation's Machine, if it is still set, and
+// returns true if it was removed
+func (r *MachineDeletionRemediationReconciler) cleanupPreTerminateHook(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation) (bool, error) {
+	if remediation.Status.Machine == nil {
+		return false, nil
+	}
+
+	backend, err := r.getMachineBackendFromStatus(remediation)
+	if err != nil {
+		r.Log.Error(err, "could not get Machine API from remediation, skipping preTerminate hook cleanup", "apiVersion", remediation.Status.Machine.APIVersion)
+		return false, nil
+	}
+	machine := backend.newMachine()
+	key := client.ObjectKey{Name: remediation.Status.Machine.Name, Namespace: remediation.Status.Machine.Namespace}
+	if err := r.Get(ctx, key, machine); err != nil {
+		if apiErrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if !backend.hasPreTerminateHook(machine) {
+		return false, nil
+	}
+	return true, r.removePreTerminateHook(ctx, remediation, backend, machine)
+}
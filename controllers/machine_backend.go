@@ -0,0 +1,546 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+
+	"github.com/medik8s/machine-deletion-remediation/api/v1alpha1"
+)
+
+// machineBackend hides the differences between the Machine APIs supported by MDR: the OpenShift Machine API
+// (machine.openshift.io) and the Cluster API (cluster.x-k8s.io).
+type machineBackend interface {
+	// apiGroup returns the API group of the Machines handled by the backend
+	apiGroup() string
+	// newMachine returns an empty Machine object of the backend's API
+	newMachine() client.Object
+	// listMachines returns the Machines in the given namespace
+	listMachines(ctx context.Context, namespace string) ([]client.Object, error)
+	// getMachineNameNsFromNode returns the Name and Namespace of the Machine associated to the Node.
+	// Empty values and no error are returned if the Node is not managed by the backend.
+	getMachineNameNsFromNode(ctx context.Context, node *v1.Node) (name, namespace string, err error)
+	// getProviderID returns the Machine's providerID, or an empty string if not set
+	getProviderID(machine client.Object) string
+	// getPhase returns the Machine's status phase, or an empty string if not set
+	getPhase(machine client.Object) string
+	// getProviderSpecKind returns the Kind of the Machine's provider specific configuration, or an empty string if
+	// not set
+	getProviderSpecKind(machine client.Object) string
+	// getMachineOwnerNameKind returns name and Kind of the object responsible for the Machine's replicas, found through
+	// the Machine's controller ownerReference
+	getMachineOwnerNameKind(ctx context.Context, machine client.Object) (name, kind string, err error)
+	// hasPreTerminateHook checks if MDR's preTerminate lifecycle hook is set on the Machine
+	hasPreTerminateHook(machine client.Object) bool
+	// setPreTerminateHook adds MDR's preTerminate lifecycle hook to the Machine, or removes it. The Machine is modified
+	// in place, and true is returned if it changed.
+	setPreTerminateHook(machine client.Object, set bool) bool
+	// isDrained checks if the Machine controller drained the Machine's Node
+	isDrained(machine client.Object) bool
+	// getLifecycleHooks returns the lifecycle hooks holding the Machine's drain or termination, as phase/name
+	getLifecycleHooks(machine client.Object) []string
+	// excludeNodeDrainingAnnotation returns the Machine annotation making the Machine controller skip the Node drain
+	excludeNodeDrainingAnnotation() string
+}
+
+const (
+	// openshiftExcludeNodeDrainingAnnotation makes the OpenShift Machine controller skip the Node drain
+	openshiftExcludeNodeDrainingAnnotation = "machine.openshift.io/exclude-node-draining"
+)
+
+// openshiftMachineBackend handles machine.openshift.io Machines
+type openshiftMachineBackend struct {
+	client.Client
+}
+
+func (b *openshiftMachineBackend) apiGroup() string {
+	return machinev1beta1.GroupName
+}
+
+func (b *openshiftMachineBackend) newMachine() client.Object {
+	return &machinev1beta1.Machine{}
+}
+
+func (b *openshiftMachineBackend) listMachines(ctx context.Context, namespace string) ([]client.Object, error) {
+	machines := &machinev1beta1.MachineList{}
+	if err := b.List(ctx, machines, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+
+	objects := make([]client.Object, 0, len(machines.Items))
+	for i := range machines.Items {
+		objects = append(objects, &machines.Items[i])
+	}
+	return objects, nil
+}
+
+func (b *openshiftMachineBackend) getMachineNameNsFromNode(_ context.Context, node *v1.Node) (string, string, error) {
+	return getMachineNameNsFromNode(node)
+}
+
+func (b *openshiftMachineBackend) getProviderID(machine client.Object) string {
+	if m, ok := machine.(*machinev1beta1.Machine); ok && m.Spec.ProviderID != nil {
+		return *m.Spec.ProviderID
+	}
+	return ""
+}
+
+func (b *openshiftMachineBackend) getPhase(machine client.Object) string {
+	if m, ok := machine.(*machinev1beta1.Machine); ok && m.Status.Phase != nil {
+		return *m.Status.Phase
+	}
+	return ""
+}
+
+func (b *openshiftMachineBackend) getProviderSpecKind(machine client.Object) string {
+	m, ok := machine.(*machinev1beta1.Machine)
+	if !ok || m.Spec.ProviderSpec.Value == nil {
+		return ""
+	}
+	typeMeta := &metav1.TypeMeta{}
+	if err := json.Unmarshal(m.Spec.ProviderSpec.Value.Raw, typeMeta); err != nil {
+		return ""
+	}
+	return typeMeta.Kind
+}
+
+func (b *openshiftMachineBackend) getMachineOwnerNameKind(_ context.Context, machine client.Object) (string, string, error) {
+	name, kind := getMachineOwnerNameKind(machine)
+	return name, kind, nil
+}
+
+func (b *openshiftMachineBackend) hasPreTerminateHook(machine client.Object) bool {
+	m, ok := machine.(*machinev1beta1.Machine)
+	return ok && slices.ContainsFunc(m.Spec.LifecycleHooks.PreTerminate, isPreTerminateHook)
+}
+
+func (b *openshiftMachineBackend) setPreTerminateHook(machine client.Object, set bool) bool {
+	m, ok := machine.(*machinev1beta1.Machine)
+	if !ok || b.hasPreTerminateHook(machine) == set {
+		return false
+	}
+	if set {
+		m.Spec.LifecycleHooks.PreTerminate = append(m.Spec.LifecycleHooks.PreTerminate,
+			machinev1beta1.LifecycleHook{Name: PreTerminateHookName, Owner: preTerminateHookOwner})
+	} else {
+		m.Spec.LifecycleHooks.PreTerminate = slices.DeleteFunc(m.Spec.LifecycleHooks.PreTerminate, isPreTerminateHook)
+	}
+	return true
+}
+
+func (b *openshiftMachineBackend) isDrained(machine client.Object) bool {
+	m, ok := machine.(*machinev1beta1.Machine)
+	if !ok {
+		return false
+	}
+	for _, condition := range m.Status.Conditions {
+		if condition.Type == machinev1beta1.MachineDrained {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func (b *openshiftMachineBackend) getLifecycleHooks(machine client.Object) []string {
+	m, ok := machine.(*machinev1beta1.Machine)
+	if !ok {
+		return nil
+	}
+	var hooks []string
+	for _, hook := range m.Spec.LifecycleHooks.PreDrain {
+		hooks = append(hooks, "preDrain/"+hook.Name)
+	}
+	for _, hook := range m.Spec.LifecycleHooks.PreTerminate {
+		hooks = append(hooks, "preTerminate/"+hook.Name)
+	}
+	return hooks
+}
+
+func (b *openshiftMachineBackend) excludeNodeDrainingAnnotation() string {
+	return openshiftExcludeNodeDrainingAnnotation
+}
+
+func isPreTerminateHook(hook machinev1beta1.LifecycleHook) bool {
+	return hook.Name == PreTerminateHookName
+}
+
+const (
+	capiGroup             = "cluster.x-k8s.io"
+	capiControlPlaneGroup = "controlplane.cluster.x-k8s.io"
+	// capiMachineAnnotation and capiClusterNamespaceAnnotation are set on the Node by the Cluster API Machine controller
+	capiMachineAnnotation          = "cluster.x-k8s.io/machine"
+	capiClusterNamespaceAnnotation = "cluster.x-k8s.io/cluster-namespace"
+	// capiDeploymentNameLabel is set on the Machines belonging to a MachineDeployment
+	capiDeploymentNameLabel = "cluster.x-k8s.io/deployment-name"
+	// capiPreDrainHookAnnotationPrefix is the prefix of the annotations holding the Machine drain
+	capiPreDrainHookAnnotationPrefix = "pre-drain.delete.hook.machine.cluster.x-k8s.io/"
+	// capiPreTerminateHookAnnotationPrefix is the prefix of the annotations holding the Machine termination
+	capiPreTerminateHookAnnotationPrefix = "pre-terminate.delete.hook.machine.cluster.x-k8s.io/"
+	// capiExcludeNodeDrainingAnnotation makes the Cluster API Machine controller skip the Node drain
+	capiExcludeNodeDrainingAnnotation = "machine.cluster.x-k8s.io/exclude-node-draining"
+	// capiDrainingSucceededCondition is set by the Cluster API Machine controller once the Node is drained
+	capiDrainingSucceededCondition = "DrainingSucceeded"
+	// capiMachineNodeRefField and capiMachineProviderIDField are the field indexes of the Machines by the name of
+	// their Node and by their providerID
+	capiMachineNodeRefField    = "status.nodeRef.name"
+	capiMachineProviderIDField = "spec.providerID"
+)
+
+var (
+	capiGroupVersion             = schema.GroupVersion{Group: capiGroup, Version: "v1beta1"}
+	capiControlPlaneGroupVersion = schema.GroupVersion{Group: capiControlPlaneGroup, Version: "v1beta1"}
+)
+
+// capiMachineBackend handles cluster.x-k8s.io Machines. Cluster API objects are handled as unstructured, so that
+// MDR does not need to vendor the Cluster API types and keeps working on clusters where they are not installed.
+type capiMachineBackend struct {
+	client.Client
+	// machines, if set, is the cache indexing the Machines by their nodeRef and providerID, see
+	// setupCapiMachineIndexes
+	machines client.Reader
+}
+
+func (b *capiMachineBackend) apiGroup() string {
+	return capiGroup
+}
+
+func (b *capiMachineBackend) newMachine() client.Object {
+	machine := &unstructured.Unstructured{}
+	machine.SetGroupVersionKind(capiGroupVersion.WithKind("Machine"))
+	return machine
+}
+
+func (b *capiMachineBackend) listMachines(ctx context.Context, namespace string) ([]client.Object, error) {
+	machines := &unstructured.UnstructuredList{}
+	machines.SetGroupVersionKind(capiGroupVersion.WithKind("MachineList"))
+	if err := b.List(ctx, machines, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+
+	objects := make([]client.Object, 0, len(machines.Items))
+	for i := range machines.Items {
+		objects = append(objects, &machines.Items[i])
+	}
+	return objects, nil
+}
+
+// getMachineNameNsFromNode looks for the Machine in the Node's annotations first, and, if they are not available,
+// for a Machine whose nodeRef or providerID match the Node
+func (b *capiMachineBackend) getMachineNameNsFromNode(ctx context.Context, node *v1.Node) (string, string, error) {
+	annotations := node.GetAnnotations()
+	if name, namespace := annotations[capiMachineAnnotation], annotations[capiClusterNamespaceAnnotation]; name != "" && namespace != "" {
+		return name, namespace, nil
+	}
+	if b.machines == nil {
+		// Cluster API was not installed when the operator started
+		return "", "", nil
+	}
+
+	key, err := b.findMachine(ctx, capiMachineNodeRefField, node.Name)
+	if err != nil || key.Name != "" || node.Spec.ProviderID == "" {
+		return key.Name, key.Namespace, err
+	}
+	key, err = b.findMachine(ctx, capiMachineProviderIDField, node.Spec.ProviderID)
+	return key.Name, key.Namespace, err
+}
+
+// findMachine returns the first Machine of all the namespaces with the given value of the given index, or an empty key
+// if none has it
+func (b *capiMachineBackend) findMachine(ctx context.Context, field, value string) (client.ObjectKey, error) {
+	machines := &unstructured.UnstructuredList{}
+	machines.SetGroupVersionKind(capiGroupVersion.WithKind("MachineList"))
+	if err := b.machines.List(ctx, machines, client.MatchingFields{field: value}); err != nil {
+		return client.ObjectKey{}, err
+	}
+	if len(machines.Items) == 0 {
+		return client.ObjectKey{}, nil
+	}
+	return client.ObjectKeyFromObject(&machines.Items[0]), nil
+}
+
+// setupCapiMachineIndexes indexes the Machines by their nodeRef and providerID in the manager's cache, to find the
+// Machine of a Node without listing all the Machines. It returns the cache, or nil if Cluster API is not installed.
+func setupCapiMachineIndexes(mgr ctrl.Manager) (client.Reader, error) {
+	if _, err := mgr.GetRESTMapper().RESTMapping(capiGroupVersion.WithKind("Machine").GroupKind(), capiGroupVersion.Version); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	machine := (&capiMachineBackend{}).newMachine()
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), machine, capiMachineNodeRefField, func(obj client.Object) []string {
+		if nodeName := getUnstructuredString(obj, "status", "nodeRef", "name"); nodeName != "" {
+			return []string{nodeName}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), machine, capiMachineProviderIDField, func(obj client.Object) []string {
+		if providerID := getUnstructuredString(obj, "spec", "providerID"); providerID != "" {
+			return []string{providerID}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	// the client reads the unstructured objects from the API server, the indexes are available in the cache only
+	return mgr.GetCache(), nil
+}
+
+func (b *capiMachineBackend) getProviderID(machine client.Object) string {
+	return getUnstructuredString(machine, "spec", "providerID")
+}
+
+func (b *capiMachineBackend) getPhase(machine client.Object) string {
+	return getUnstructuredString(machine, "status", "phase")
+}
+
+// getProviderSpecKind returns the Kind of the Machine's infrastructure reference, e.g. "AWSMachine"
+func (b *capiMachineBackend) getProviderSpecKind(machine client.Object) string {
+	return getUnstructuredString(machine, "spec", "infrastructureRef", "kind")
+}
+
+// getMachineOwnerNameKind returns the Machine's controller ownerReference name and Kind, unless the owner is a
+// MachineSet controlled by a MachineDeployment. In that case the MachineDeployment is returned, since it is the one
+// whose replicas are going to be restored, possibly by a different MachineSet (e.g. during a rollout).
+func (b *capiMachineBackend) getMachineOwnerNameKind(ctx context.Context, machine client.Object) (string, string, error) {
+	name, kind := getMachineOwnerNameKind(machine)
+	if kind != "MachineSet" {
+		return name, kind, nil
+	}
+
+	machineSet := &unstructured.Unstructured{}
+	machineSet.SetGroupVersionKind(capiGroupVersion.WithKind("MachineSet"))
+	if err := b.Get(ctx, client.ObjectKey{Name: name, Namespace: machine.GetNamespace()}, machineSet); err != nil {
+		return "", "", err
+	}
+	if ref := metav1.GetControllerOf(machineSet); ref != nil && ref.Kind == "MachineDeployment" {
+		return ref.Name, ref.Kind, nil
+	}
+	return name, kind, nil
+}
+
+func (b *capiMachineBackend) hasPreTerminateHook(machine client.Object) bool {
+	_, exists := machine.GetAnnotations()[capiPreTerminateHookAnnotationPrefix+PreTerminateHookName]
+	return exists
+}
+
+func (b *capiMachineBackend) setPreTerminateHook(machine client.Object, set bool) bool {
+	if b.hasPreTerminateHook(machine) == set {
+		return false
+	}
+	annotations := machine.GetAnnotations()
+	if set {
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[capiPreTerminateHookAnnotationPrefix+PreTerminateHookName] = preTerminateHookOwner
+	} else {
+		delete(annotations, capiPreTerminateHookAnnotationPrefix+PreTerminateHookName)
+	}
+	machine.SetAnnotations(annotations)
+	return true
+}
+
+func (b *capiMachineBackend) isDrained(machine client.Object) bool {
+	u, ok := machine.(*unstructured.Unstructured)
+	if !ok {
+		return false
+	}
+	conditions, _, _ := unstructured.NestedSlice(u.Object, "status", "conditions")
+	for _, c := range conditions {
+		if condition, ok := c.(map[string]interface{}); ok && condition["type"] == capiDrainingSucceededCondition {
+			return condition["status"] == string(metav1.ConditionTrue)
+		}
+	}
+	return false
+}
+
+func (b *capiMachineBackend) getLifecycleHooks(machine client.Object) []string {
+	var hooks []string
+	for annotation := range machine.GetAnnotations() {
+		if name, found := strings.CutPrefix(annotation, capiPreDrainHookAnnotationPrefix); found {
+			hooks = append(hooks, "preDrain/"+name)
+		} else if name, found := strings.CutPrefix(annotation, capiPreTerminateHookAnnotationPrefix); found {
+			hooks = append(hooks, "preTerminate/"+name)
+		}
+	}
+	slices.Sort(hooks)
+	return hooks
+}
+
+func (b *capiMachineBackend) excludeNodeDrainingAnnotation() string {
+	return capiExcludeNodeDrainingAnnotation
+}
+
+// getMachineBackend returns the backend handling Machines of the given API group
+func (r *MachineDeletionRemediationReconciler) getMachineBackend(group string) (machineBackend, error) {
+	switch group {
+	case "", machinev1beta1.GroupName:
+		// machine.openshift.io is the default for backward compatibility
+		return &openshiftMachineBackend{Client: r.Client}, nil
+	case capiGroup:
+		return &capiMachineBackend{Client: r.Client, machines: r.capiMachines}, nil
+	default:
+		return nil, fmt.Errorf("unsupported Machine API group %s", group)
+	}
+}
+
+// getMachineBackendFromStatus returns the backend handling the Machine saved in the remediation's status
+func (r *MachineDeletionRemediationReconciler) getMachineBackendFromStatus(remediation *v1alpha1.MachineDeletionRemediation) (machineBackend, error) {
+	gv, err := schema.ParseGroupVersion(remediation.Status.Machine.APIVersion)
+	if err != nil {
+		return nil, err
+	}
+	return r.getMachineBackend(gv.Group)
+}
+
+const (
+	// nodeMachineField is the field index of the Nodes by the Machine referenced in their annotations
+	nodeMachineField = "machine"
+	// nodeProviderIDField is the field index of the Nodes by their providerID
+	nodeProviderIDField = "spec.providerID"
+)
+
+// getMachineBackendForNode returns the backend managing the Node together with the Name and Namespace of
+// the Node's Machine
+func (r *MachineDeletionRemediationReconciler) getMachineBackendForNode(ctx context.Context, node *v1.Node) (machineBackend, string, string, error) {
+	openshiftBackend, capiBackend := &openshiftMachineBackend{Client: r.Client}, &capiMachineBackend{Client: r.Client, machines: r.capiMachines}
+	// The OpenShift Machine annotation takes precedence, and it is the fallback when no backend claims the Node, so
+	// that its errors are reported
+	if _, exists := node.GetAnnotations()[machineAnnotationOpenshift]; !exists {
+		name, namespace, err := capiBackend.getMachineNameNsFromNode(ctx, node)
+		if err != nil {
+			return nil, "", "", err
+		}
+		if name != "" {
+			return capiBackend, name, namespace, nil
+		}
+	}
+
+	name, namespace, err := openshiftBackend.getMachineNameNsFromNode(ctx, node)
+	return openshiftBackend, name, namespace, err
+}
+
+// getMachineNode returns the Node associated to the given Machine, or nil if it does not exist. The Nodes are looked
+// up in the cache by the Machine of their annotations, and, for the Cluster API Machines, by the Machine's nodeRef and
+// providerID, as getMachineBackendForNode does in the other direction.
+func (r *MachineDeletionRemediationReconciler) getMachineNode(ctx context.Context, backend machineBackend, machine client.Object) (*v1.Node, error) {
+	nodes := &v1.NodeList{}
+	machineKey := getNodeMachineIndexValue(backend.apiGroup(), client.ObjectKeyFromObject(machine))
+	if err := r.List(ctx, nodes, client.MatchingFields{nodeMachineField: machineKey}); err != nil {
+		return nil, err
+	}
+	if len(nodes.Items) > 0 {
+		return &nodes.Items[0], nil
+	}
+	if backend.apiGroup() != capiGroup {
+		return nil, nil
+	}
+
+	if nodeName := getUnstructuredString(machine, "status", "nodeRef", "name"); nodeName != "" {
+		node := &v1.Node{}
+		if err := r.Get(ctx, client.ObjectKey{Name: nodeName}, node); err == nil {
+			if !hasMachineAnnotation(node) {
+				return node, nil
+			}
+		} else if !apiErrors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	if providerID := backend.getProviderID(machine); providerID != "" {
+		if err := r.List(ctx, nodes, client.MatchingFields{nodeProviderIDField: providerID}); err != nil {
+			return nil, err
+		}
+		for i := range nodes.Items {
+			if node := &nodes.Items[i]; !hasMachineAnnotation(node) {
+				return node, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// hasMachineAnnotation checks if the Node references its Machine in its annotations, rather than being matched by
+// the nodeRef or providerID of a Cluster API Machine
+func hasMachineAnnotation(node *v1.Node) bool {
+	annotations := node.GetAnnotations()
+	if _, exists := annotations[machineAnnotationOpenshift]; exists {
+		return true
+	}
+	return annotations[capiMachineAnnotation] != "" && annotations[capiClusterNamespaceAnnotation] != ""
+}
+
+// indexNodesByMachine allows finding the Node of a Machine from the cache, through the Machine annotations of the Node
+func indexNodesByMachine(obj client.Object) []string {
+	node, ok := obj.(*v1.Node)
+	if !ok {
+		return nil
+	}
+	annotations := node.GetAnnotations()
+	if _, exists := annotations[machineAnnotationOpenshift]; exists {
+		name, namespace, err := getMachineNameNsFromNode(node)
+		if err != nil {
+			return nil
+		}
+		return []string{getNodeMachineIndexValue(machinev1beta1.GroupName, client.ObjectKey{Name: name, Namespace: namespace})}
+	}
+	if name, namespace := annotations[capiMachineAnnotation], annotations[capiClusterNamespaceAnnotation]; name != "" && namespace != "" {
+		return []string{getNodeMachineIndexValue(capiGroup, client.ObjectKey{Name: name, Namespace: namespace})}
+	}
+	return nil
+}
+
+// indexNodesByProviderID allows finding the Node of a Cluster API Machine by providerID from the cache
+func indexNodesByProviderID(obj client.Object) []string {
+	node, ok := obj.(*v1.Node)
+	if !ok || node.Spec.ProviderID == "" {
+		return nil
+	}
+	return []string{node.Spec.ProviderID}
+}
+
+// getNodeMachineIndexValue returns the value of the nodeMachineField index of the given Machine. The API group is part
+// of it, since the OpenShift and the Cluster API Machines may have the same name.
+func getNodeMachineIndexValue(apiGroup string, machine client.ObjectKey) string {
+	return apiGroup + "/" + machine.String()
+}
+
+func getUnstructuredString(obj client.Object, fields ...string) string {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return ""
+	}
+	value, _, _ := unstructured.NestedString(u.Object, fields...)
+	return value
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Machine backends", func() {
+	DescribeTable("Node index by Machine",
+		func(annotations map[string]string, expected []string) {
+			node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node", Annotations: annotations}}
+			Expect(indexNodesByMachine(node)).To(Equal(expected))
+		},
+		Entry("OpenShift Machine", map[string]string{machineAnnotationOpenshift: "ns/machine"},
+			[]string{"machine.openshift.io/ns/machine"}),
+		Entry("Cluster API Machine", map[string]string{capiMachineAnnotation: "machine", capiClusterNamespaceAnnotation: "ns"},
+			[]string{"cluster.x-k8s.io/ns/machine"}),
+		Entry("OpenShift Machine annotation takes precedence",
+			map[string]string{machineAnnotationOpenshift: "ns/machine", capiMachineAnnotation: "other", capiClusterNamespaceAnnotation: "ns"},
+			[]string{"machine.openshift.io/ns/machine"}),
+		Entry("invalid OpenShift Machine annotation", map[string]string{machineAnnotationOpenshift: "machine"}, nil),
+		Entry("Cluster API Machine without namespace", map[string]string{capiMachineAnnotation: "machine"}, nil),
+		Entry("no annotations", nil, nil),
+	)
+})
@@ -0,0 +1,220 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	machinev1 "github.com/openshift/api/machine/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+
+	"github.com/medik8s/machine-deletion-remediation/api/v1alpha1"
+)
+
+const (
+	// defaultReplicasPath is the JSONPath of the desired replicas of the Machine owners which do not set their own
+	defaultReplicasPath = "{.spec.replicas}"
+)
+
+// machineOwnerResolver describes how MDR finds the Machine owners of a Kind, their desired replicas and their Machines
+type machineOwnerResolver struct {
+	machineAPIGroup string
+	apiVersion      string
+	kind            string
+	// replicasPath is the JSONPath of the owner's desired replicas
+	replicasPath string
+	// machineSelectorPath, if set, is the JSONPath of the owner's label selector matching its Machines
+	machineSelectorPath string
+	// machineNameLabel, if set, is the label of the Machines holding the name of their owner
+	machineNameLabel string
+}
+
+// builtinOwnerResolvers are the Machine owner Kinds supported out of the box
+var builtinOwnerResolvers = []machineOwnerResolver{
+	{machineAPIGroup: machinev1beta1.GroupName, apiVersion: machinev1beta1.GroupVersion.String(), kind: "MachineSet", replicasPath: defaultReplicasPath},
+	{machineAPIGroup: machinev1beta1.GroupName, apiVersion: machinev1.GroupVersion.String(), kind: "ControlPlaneMachineSet", replicasPath: defaultReplicasPath},
+	{machineAPIGroup: capiGroup, apiVersion: capiGroupVersion.String(), kind: "MachineSet", replicasPath: defaultReplicasPath},
+	// the Machines of a MachineDeployment are owned by its MachineSets, they are found by label instead
+	{machineAPIGroup: capiGroup, apiVersion: capiGroupVersion.String(), kind: "MachineDeployment", replicasPath: defaultReplicasPath, machineNameLabel: capiDeploymentNameLabel},
+	{machineAPIGroup: capiGroup, apiVersion: capiControlPlaneGroupVersion.String(), kind: "KubeadmControlPlane", replicasPath: defaultReplicasPath},
+}
+
+// newMachineOwnerResolver returns the resolver of the given owner Kind. It fails if the owner Kind is invalid.
+func newMachineOwnerResolver(ownerKind v1alpha1.MachineOwnerKind) (*machineOwnerResolver, error) {
+	resolver := &machineOwnerResolver{
+		machineAPIGroup:     ownerKind.MachineAPIGroup,
+		apiVersion:          ownerKind.APIVersion,
+		kind:                ownerKind.Kind,
+		replicasPath:        ownerKind.ReplicasPath,
+		machineSelectorPath: ownerKind.MachineSelectorPath,
+	}
+	if resolver.machineAPIGroup == "" {
+		resolver.machineAPIGroup = machinev1beta1.GroupName
+	}
+	if resolver.replicasPath == "" {
+		resolver.replicasPath = defaultReplicasPath
+	}
+
+	if resolver.machineAPIGroup != machinev1beta1.GroupName && resolver.machineAPIGroup != capiGroup {
+		return nil, fmt.Errorf("machineAPIGroup: unsupported Machine API group %q", resolver.machineAPIGroup)
+	}
+	if resolver.kind == "" {
+		return nil, fmt.Errorf("kind: must not be empty")
+	}
+	if gv, err := schema.ParseGroupVersion(resolver.apiVersion); err != nil || gv.Version == "" {
+		return nil, fmt.Errorf("apiVersion: invalid apiVersion %q", resolver.apiVersion)
+	}
+	if _, err := parseJSONPath(resolver.replicasPath); err != nil {
+		return nil, fmt.Errorf("replicasPath: %v", err)
+	}
+	if resolver.machineSelectorPath != "" {
+		if _, err := parseJSONPath(resolver.machineSelectorPath); err != nil {
+			return nil, fmt.Errorf("machineSelectorPath: %v", err)
+		}
+	}
+	return resolver, nil
+}
+
+// getMachineOwnerResolver returns the resolver of the owners of the given Kind, whose Machines are handled by the
+// given backend. The owner Kinds of the operator configuration take precedence over the built-in ones.
+func (r *MachineDeletionRemediationReconciler) getMachineOwnerResolver(backend machineBackend, kind string) (*machineOwnerResolver, bool) {
+	for _, resolver := range r.getOperatorConfig().getOwnerResolvers() {
+		if resolver.machineAPIGroup == backend.apiGroup() && resolver.kind == kind {
+			return resolver, true
+		}
+	}
+	return getBuiltinOwnerResolver(backend.apiGroup(), kind)
+}
+
+// getBuiltinOwnerResolver returns the resolver of the built-in owner Kind, whose Machines belong to the given API group
+func getBuiltinOwnerResolver(machineAPIGroup, kind string) (*machineOwnerResolver, bool) {
+	for i := range builtinOwnerResolvers {
+		if resolver := builtinOwnerResolvers[i]; resolver.machineAPIGroup == machineAPIGroup && resolver.kind == kind {
+			return &resolver, true
+		}
+	}
+	return nil, false
+}
+
+// getReplicas returns the owner's desired replicas. It fails if they are not set, rather than assuming that the owner
+// wants no replicas, e.g. when the replicas path of a custom owner Kind is wrong.
+func (o *machineOwnerResolver) getReplicas(owner *unstructured.Unstructured) (int, error) {
+	value, found, err := getJSONPathValue(owner, o.replicasPath)
+	if err != nil {
+		return 0, err
+	} else if !found {
+		return 0, fmt.Errorf("%s of %s %s is not set", o.replicasPath, o.kind, owner.GetName())
+	}
+	switch replicas := value.(type) {
+	case int64:
+		return int(replicas), nil
+	case float64:
+		return int(replicas), nil
+	default:
+		return 0, fmt.Errorf("%s of %s %s is not a number: %v", o.replicasPath, o.kind, owner.GetName(), value)
+	}
+}
+
+// getMachineSelector returns the owner's selector matching its Machines
+func (o *machineOwnerResolver) getMachineSelector(owner *unstructured.Unstructured) (labels.Selector, error) {
+	value, found, err := getJSONPathValue(owner, o.machineSelectorPath)
+	if err != nil {
+		return nil, err
+	}
+	fields, ok := value.(map[string]interface{})
+	if !found || !ok {
+		return nil, fmt.Errorf("%s of %s %s is not a label selector: %v", o.machineSelectorPath, o.kind, owner.GetName(), value)
+	}
+	selector := &metav1.LabelSelector{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(fields, selector); err != nil {
+		return nil, err
+	}
+	return metav1.LabelSelectorAsSelector(selector)
+}
+
+// getOwnedMachineFilter returns a function checking if a Machine belongs to the owner with the given Kind and name.
+// Depending on the owner Kind, the Machines are matched by their controller ownerReference, by their owner name label,
+// or by the owner's label selector.
+func (r *MachineDeletionRemediationReconciler) getOwnedMachineFilter(ctx context.Context, backend machineBackend, kind, name, namespace string) (func(machine client.Object) bool, error) {
+	resolver, exists := r.getMachineOwnerResolver(backend, kind)
+	switch {
+	case exists && resolver.machineNameLabel != "":
+		return func(machine client.Object) bool {
+			return machine.GetLabels()[resolver.machineNameLabel] == name
+		}, nil
+	case exists && resolver.machineSelectorPath != "":
+		owner, err := r.getMachineOwner(ctx, backend, kind, name, namespace)
+		if err != nil {
+			return nil, err
+		}
+		selector, err := resolver.getMachineSelector(owner)
+		if err != nil {
+			return nil, errors.Wrap(unrecoverableError, err.Error())
+		}
+		return func(machine client.Object) bool {
+			return selector.Matches(labels.Set(machine.GetLabels()))
+		}, nil
+	default:
+		return func(machine client.Object) bool {
+			ownerName, ownerKind := getMachineOwnerNameKind(machine)
+			return ownerName == name && ownerKind == kind
+		}, nil
+	}
+}
+
+// parseJSONPath parses the given JSONPath, accepting it without the surrounding braces too
+func parseJSONPath(path string) (*jsonpath.JSONPath, error) {
+	if !strings.HasPrefix(path, "{") {
+		path = "{" + path + "}"
+	}
+	parser := jsonpath.New("path").AllowMissingKeys(true)
+	if err := parser.Parse(path); err != nil {
+		return nil, fmt.Errorf("invalid JSONPath %q: %v", path, err)
+	}
+	return parser, nil
+}
+
+// getJSONPathValue returns the single value found in the object at the given JSONPath, and false if it is missing
+func getJSONPathValue(obj *unstructured.Unstructured, path string) (interface{}, bool, error) {
+	parser, err := parseJSONPath(path)
+	if err != nil {
+		return nil, false, err
+	}
+	results, err := parser.FindResults(obj.Object)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(results) == 0 || len(results[0]) == 0 {
+		return nil, false, nil
+	}
+	if len(results) > 1 || len(results[0]) > 1 {
+		return nil, false, fmt.Errorf("%s matches more than one value", path)
+	}
+	return results[0][0].Interface(), true, nil
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("Machine owners", func() {
+	newOwner := func(spec map[string]interface{}) *unstructured.Unstructured {
+		owner := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+		owner.SetName("owner")
+		return owner
+	}
+
+	DescribeTable("desired replicas",
+		func(replicasPath string, spec map[string]interface{}, expected int) {
+			resolver := &machineOwnerResolver{kind: "NodePool", replicasPath: replicasPath}
+			Expect(resolver.getReplicas(newOwner(spec))).To(Equal(expected))
+		},
+		Entry("default replicas path", defaultReplicasPath, map[string]interface{}{"replicas": int64(3)}, 3),
+		Entry("custom replicas path", "{.spec.size}", map[string]interface{}{"size": int64(2)}, 2),
+		Entry("floating point value", "{.spec.size}", map[string]interface{}{"size": float64(2)}, 2),
+		Entry("no replicas", defaultReplicasPath, map[string]interface{}{"replicas": int64(0)}, 0),
+	)
+
+	DescribeTable("invalid desired replicas",
+		func(replicasPath string, spec map[string]interface{}) {
+			resolver := &machineOwnerResolver{kind: "NodePool", replicasPath: replicasPath}
+			_, err := resolver.getReplicas(newOwner(spec))
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("missing replicas", defaultReplicasPath, map[string]interface{}{}),
+		Entry("wrong replicas path", "{.spec.size}", map[string]interface{}{"replicas": int64(3)}),
+		Entry("not a number", defaultReplicasPath, map[string]interface{}{"replicas": "three"}),
+	)
+
+	It("resolves the replicas of the built-in owner kinds", func() {
+		for _, resolver := range builtinOwnerResolvers {
+			Expect(resolver.getReplicas(newOwner(map[string]interface{}{"replicas": int64(1)}))).To(Equal(1), resolver.kind)
+		}
+	})
+})
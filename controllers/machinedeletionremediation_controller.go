@@ -19,6 +19,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -34,12 +35,15 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 
-	machinev1 "github.com/openshift/api/machine/v1"
 	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
 
 	"github.com/medik8s/machine-deletion-remediation/api/v1alpha1"
@@ -47,9 +51,13 @@ import (
 
 const (
 	machineAnnotationOpenshift = "machine.openshift.io/machine"
-	// MachineNameNsAnnotation contains to-be-deleted Machine's Name and Namespace
+	// MachineNameNsAnnotation contains to-be-deleted Machine's Name and Namespace.
+	// Deprecated: the Machine is saved in Status.Machine, the annotation is only read to migrate remediations created
+	// by previous versions.
 	MachineNameNsAnnotation = "machine-deletion-remediation.medik8s.io/machineNameNamespace"
-	// MachineOwnerAnnotation contains Machine's ownerReference name and Kind
+	// MachineOwnerAnnotation contains Machine's ownerReference name and Kind.
+	// Deprecated: the Machine owner is saved in Status.MachineOwner, the annotation is only read to migrate
+	// remediations created by previous versions.
 	MachineOwnerAnnotation = "machine-deletion-remediation.medik8s.io/machineOwner"
 	// Infos
 	postponedMachineDeletionInfo  = "target machine was not deleted yet"
@@ -62,6 +70,8 @@ const (
 	nodeNotFoundErrorMsg               = "failed to fetch node"
 	machineNotFoundErrorMsg            = "failed to fetch machine of node"
 	noControllerOwnerErrorMsg          = "ignoring remediation of the machine: the machine has no controller owner"
+	nodeRestorationTimedOutErrorMsg    = "the nodes were not restored within the node restoration timeout, waited %s"
+	ownerKindNotEnabledErrorMsg        = "ignoring remediation of the machine: the machine owner's kind is not enabled in the operator configuration"
 	// Cluster Provider messages
 	machineDeletedOnCloudProviderMessage     = "Machine will be deleted and the unhealthy node replaced. This is a Cloud cluster provider: the new node is expected to have a new name"
 	machineDeletedOnBareMetalProviderMessage = "Machine will be deleted and the unhealthy node replaced. This is a BareMetal cluster provider: the new node is NOT expected to have a new name"
@@ -71,13 +81,23 @@ const (
 type conditionChangeReason string
 
 const (
-	remediationStarted                  conditionChangeReason = "RemediationStarted"
-	remediationTimedOutByNhc            conditionChangeReason = "RemediationStoppedByNHC"
-	remediationFinishedMachineDeleted   conditionChangeReason = "MachineDeleted"
-	remediationSkippedNodeNotFound      conditionChangeReason = "RemediationSkippedNodeNotFound"
-	remediationSkippedMachineNotFound   conditionChangeReason = "RemediationSkippedMachineNotFound"
-	remediationSkippedNoControllerOwner conditionChangeReason = "RemediationSkippedNoControllerOwner"
-	remediationFailed                   conditionChangeReason = "RemediationFailed"
+	remediationStarted                    conditionChangeReason = "RemediationStarted"
+	remediationTimedOutByNhc              conditionChangeReason = "RemediationStoppedByNHC"
+	remediationFinishedMachineDeleted     conditionChangeReason = "MachineDeleted"
+	remediationSkippedNodeNotFound        conditionChangeReason = "RemediationSkippedNodeNotFound"
+	remediationSkippedMachineNotFound     conditionChangeReason = "RemediationSkippedMachineNotFound"
+	remediationSkippedNoControllerOwner   conditionChangeReason = "RemediationSkippedNoControllerOwner"
+	remediationSkippedOwnerKindNotEnabled conditionChangeReason = "RemediationSkippedOwnerKindNotEnabled"
+	remediationSkippedDuplicate           conditionChangeReason = "RemediationSkippedDuplicate"
+	remediationSkippedDryRun              conditionChangeReason = "RemediationSkippedDryRun"
+	remediationFailed                     conditionChangeReason = "RemediationFailed"
+	remediationNodeRestorationTimedOut    conditionChangeReason = "NodeRestorationTimedOut"
+	remediationBlockedQuorumAtRisk        conditionChangeReason = "RemediationBlockedQuorumAtRisk"
+	remediationInvalidMaintenanceWindow   conditionChangeReason = "InvalidMaintenanceWindow"
+	remediationBareMetalHostError         conditionChangeReason = "BareMetalHostError"
+	remediationFallbackStarted            conditionChangeReason = "FallbackRemediationStarted"
+	remediationFallbackSucceeded          conditionChangeReason = "FallbackRemediationSucceeded"
+	remediationFallbackFailed             conditionChangeReason = "FallbackRemediationFailed"
 )
 
 var (
@@ -92,15 +112,34 @@ type MachineDeletionRemediationReconciler struct {
 	Log      logr.Logger
 	Scheme   *runtime.Scheme
 	Recorder record.EventRecorder
+	// Config is the operator configuration. The default configuration is used if nil.
+	Config *OperatorConfig
+	// MaxInFlight is the value of the deprecated --max-in-flight flag, used when the operator configuration does not
+	// set maxInFlight.
+	MaxInFlight *intstr.IntOrString
+	// PlatformDetectors detect additional platforms, before the built-in ones, to tell if the Node keeps its name
+	// when the Machine is replaced
+	PlatformDetectors []PlatformDetector
+	// capiMachines, if set, is the cache indexing the Cluster API Machines, see setupCapiMachineIndexes
+	capiMachines client.Reader
 }
 
 //+kubebuilder:rbac:groups=machine-deletion-remediation.medik8s.io,resources=machinedeletionremediations,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=machine-deletion-remediation.medik8s.io,resources=machinedeletionremediations/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=machine-deletion-remediation.medik8s.io,resources=machinedeletionremediations/finalizers,verbs=update
+//+kubebuilder:rbac:groups=machine-deletion-remediation.medik8s.io,resources=machinedeletionremediationapprovals,verbs=get;list;watch
 //+kubebuilder:rbac:groups=machine.openshift.io,resources=machines,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=machine.openshift.io,resources=machinesets,verbs=get;list;watch
 //+kubebuilder:rbac:groups=machine.openshift.io,resources=controlplanemachinesets,verbs=get;list;watch
-//+kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines,verbs=get;list;watch;update;patch;delete
+//+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinesets;machinedeployments,verbs=get;list;watch
+//+kubebuilder:rbac:groups=controlplane.cluster.x-k8s.io,resources=kubeadmcontrolplanes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=config.openshift.io,resources=infrastructures,verbs=get;list;watch
+//+kubebuilder:rbac:groups=metal3.io,resources=baremetalhosts,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch;patch
+//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;delete
+//+kubebuilder:rbac:groups=core,resources=pods/eviction,verbs=create
+//+kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -114,6 +153,7 @@ func (r *MachineDeletionRemediationReconciler) Reconcile(ctx context.Context, re
 	log := r.Log.WithValues("machinedeletionremediation", req.NamespacedName)
 
 	log.Info("reconciling...")
+	defer r.updateInFlightMetric(ctx)
 
 	var err error
 	var mdr *v1alpha1.MachineDeletionRemediation
@@ -122,17 +162,45 @@ func (r *MachineDeletionRemediationReconciler) Reconcile(ctx context.Context, re
 	}
 
 	log.Info("Machine Deletion Remediation CR found", "name", mdr.GetName())
+	if r.Config != nil && !r.Config.IsLoaded() {
+		// the defaults could delete Machines the configuration protects, e.g. with dry run
+		log.Info("waiting for the operator configuration to be loaded")
+		return ctrl.Result{RequeueAfter: configNotLoadedRequeueInterval}, nil
+	}
+	if !mdr.GetDeletionTimestamp().IsZero() {
+		log.Info("remediation is being deleted, cleaning up")
+		return ctrl.Result{}, r.finalizeRemediation(ctx, mdr)
+	}
+
+	if err = r.addCleanupFinalizer(ctx, mdr); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	config := r.getConfig()
+	previous := mdr.DeepCopy()
 
 	defer func() {
+		mdr.Status.Phase = getRemediationPhase(mdr)
 		if updateErr := r.updateStatus(ctx, mdr); updateErr != nil {
 			if !apiErrors.IsConflict(updateErr) {
 				finalErr = utilerrors.NewAggregate([]error{updateErr, finalErr})
 			}
-			finalResult.RequeueAfter = time.Second
+			finalResult.RequeueAfter = config.StatusUpdateRequeueInterval.Duration
+			return
 		}
+		recordRemediationProgress(previous, mdr)
 	}()
 
 	if r.isTimedOutByNHC(mdr) {
+		if err := r.stopFallbackRemediation(ctx, mdr); err != nil {
+			log.Error(err, "could not stop the fallback remediation")
+			return ctrl.Result{}, err
+		}
+		// the Machine termination must not be held once NHC gave up on the remediation
+		if _, err := r.cleanupPreTerminateHook(ctx, mdr); err != nil {
+			log.Error(err, "could not remove the preTerminate hook")
+			return ctrl.Result{}, err
+		}
 		if updateRequired, err := r.updateConditions(remediationTimedOutByNhc, mdr); err != nil {
 			return ctrl.Result{}, err
 		} else if updateRequired {
@@ -142,14 +210,28 @@ func (r *MachineDeletionRemediationReconciler) Reconcile(ctx context.Context, re
 		return ctrl.Result{}, nil
 	}
 
-	if updateRequired, err := r.updateConditions(remediationStarted, mdr); err != nil {
-		log.Error(err, "could not update Status conditions")
-		return ctrl.Result{}, err
-	} else if updateRequired {
-		return ctrl.Result{RequeueAfter: time.Second}, nil
+	if !isRemediationStarted(mdr) {
+		if updateRequired, err := r.updateConditions(remediationStarted, mdr); err != nil {
+			log.Error(err, "could not update Status conditions")
+			return ctrl.Result{}, err
+		} else if updateRequired {
+			return ctrl.Result{RequeueAfter: config.StatusUpdateRequeueInterval.Duration}, nil
+		}
+	}
+
+	// once fallen back, the remediation only follows the fallback remediation
+	if isFallbackRemediationStarted(mdr) {
+		requeueAfter, err := r.followFallbackRemediation(ctx, mdr)
+		return ctrl.Result{RequeueAfter: requeueAfter}, err
+	}
+
+	// a dry run is final: the Machine must not be touched by later reconciliations
+	if isDryRunCompleted(mdr) {
+		log.Info("remediation completed in dry run mode, nothing to do")
+		return ctrl.Result{}, nil
 	}
 
-	machine, err := r.getMachine(ctx, mdr)
+	backend, machine, err := r.getMachine(ctx, mdr)
 	if err != nil {
 		// Handling specific error scenarios. We avoid re-queue by returning nil after updating the
 		// conditions, as these are unrecoverable errors and re-queue would not change the
@@ -158,10 +240,12 @@ func (r *MachineDeletionRemediationReconciler) Reconcile(ctx context.Context, re
 		if err == nodeNotFoundError {
 			commonevents.WarningEvent(r.Recorder, mdr, string(remediationSkippedNodeNotFound), nodeNotFoundErrorMsg)
 			_, err = r.updateConditions(remediationSkippedNodeNotFound, mdr)
+		} else if err == machineNotFoundError && isFallbackRemediationEnabled(config, mdr) {
+			err = r.startFallbackRemediation(ctx, mdr, remediationSkippedMachineNotFound, machineNotFoundErrorMsg)
 		} else if err == machineNotFoundError {
 			commonevents.WarningEvent(r.Recorder, mdr, string(remediationSkippedMachineNotFound), machineNotFoundErrorMsg)
 			_, err = r.updateConditions(remediationSkippedMachineNotFound, mdr)
-		} else if err == unrecoverableError {
+		} else if errors.Cause(err) == unrecoverableError {
 			commonevents.WarningEvent(r.Recorder, mdr, string(remediationFailed), unrecoverableError.Error())
 			_, err = r.updateConditions(remediationFailed, mdr)
 		}
@@ -173,18 +257,53 @@ func (r *MachineDeletionRemediationReconciler) Reconcile(ctx context.Context, re
 	// Otherwise, there are two possibilities:
 	// 1. The machine is still to be deleted or pending deletion.
 	// 2. The machine was re-provisioned and it is newer than the remediation CR.
-	// If the latter, wait for the expected number of nodes to be restored before setting the Succeeded condition.
+	// If the latter, wait for the replacement Machine's node to be Ready before setting the Succeeded condition.
 	// NOTE: the Machine will always be nil after deletion if it changes name after re-provisioning, this is why we
-	// verify nodes count restoration even if machine == nil.
+	// look for the replacement Machine even if machine == nil.
 	if machine == nil || machine.GetCreationTimestamp().After(mdr.GetCreationTimestamp().Time) {
-		if isRestored, err := r.isExpectedNodesNumberRestored(ctx, mdr); err != nil {
+		if mdr.Status.MachineDeletedTime == nil {
+			now := metav1.Now()
+			mdr.Status.MachineDeletedTime = &now
+		}
+		completeStuckDeletion(mdr)
+
+		// the BareMetalHost is followed until its Node is restored, or the remediation completes
+		if failed, err := r.followBareMetalHost(ctx, mdr); err != nil {
+			return ctrl.Result{}, err
+		} else if failed {
+			return ctrl.Result{}, nil
+		}
+
+		if !isWaitForNodeReplacementEnabled(mdr) {
+			log.Info("not waiting for the node to be replaced as per remediation spec")
+			if updateRequired, err := r.updateConditions(remediationFinishedMachineDeleted, mdr); err != nil {
+				return ctrl.Result{}, err
+			} else if updateRequired {
+				commonevents.RemediationFinished(r.Recorder, mdr)
+			}
+			return ctrl.Result{}, nil
+		}
+
+		// the remediation already failed, do not change its outcome if the nodes are eventually restored
+		if isConditionReason(mdr, commonconditions.SucceededType, remediationNodeRestorationTimedOut) ||
+			isConditionReason(mdr, commonconditions.SucceededType, remediationFailed) {
+			return ctrl.Result{}, nil
+		}
+
+		if isRestored, err := r.isReplacementNodeReady(ctx, mdr); err != nil {
 			msg := "could not verify if node was restored"
 			log.Error(err, msg)
-			commonevents.WarningEvent(r.Recorder, mdr, "unableToVerifyNodesCount", err.Error())
-			if err == unrecoverableError {
-				return ctrl.Result{}, nil
+			commonevents.WarningEvent(r.Recorder, mdr, "unableToVerifyNodeReplacement", err.Error())
+			if errors.Cause(err) != unrecoverableError {
+				return ctrl.Result{}, err
 			}
-			return ctrl.Result{}, err
+			if updateRequired, err := r.updateConditions(remediationFailed, mdr); err != nil {
+				return ctrl.Result{}, err
+			} else if updateRequired {
+				setConditionMessage(mdr, commonconditions.SucceededType, err.Error())
+				commonevents.WarningEvent(r.Recorder, mdr, string(remediationFailed), err.Error())
+			}
+			return ctrl.Result{}, nil
 		} else if isRestored {
 			if updateRequired, err := r.updateConditions(remediationFinishedMachineDeleted, mdr); err != nil {
 				return ctrl.Result{}, err
@@ -193,84 +312,247 @@ func (r *MachineDeletionRemediationReconciler) Reconcile(ctx context.Context, re
 			}
 			return ctrl.Result{}, nil
 		}
-		log.Info("waiting for the nodes count to be re-provisioned")
-		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+
+		timedOut, waited, remaining := getNodeRestorationTimeoutStatus(mdr)
+		if timedOut {
+			msg := fmt.Sprintf(nodeRestorationTimedOutErrorMsg, waited.Round(time.Second))
+			if updateRequired, err := r.updateConditions(remediationNodeRestorationTimedOut, mdr); err != nil {
+				return ctrl.Result{}, err
+			} else if updateRequired {
+				setConditionMessage(mdr, commonconditions.SucceededType, msg)
+				log.Info(msg)
+				commonevents.WarningEvent(r.Recorder, mdr, string(remediationNodeRestorationTimedOut), msg)
+			}
+			return ctrl.Result{}, nil
+		}
+
+		log.Info("waiting for the node to be replaced", "waited", waited.Round(time.Second),
+			"replacement machine", mdr.Status.ReplacementMachineName, "replacement node", mdr.Status.ReplacementNodeName)
+		requeueAfter := r.getPollInterval(mdr)
+		if remaining > 0 && remaining < requeueAfter {
+			requeueAfter = remaining
+		}
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
 	}
 
 	log.Info("target machine found", "machine", machine.GetName())
 
+	if mdr.Status.MachineDeletionRequestedTime == nil {
+		if owner, err := r.getOwningRemediation(ctx, mdr, machine); err != nil {
+			log.Error(err, "could not verify if the machine is remediated by another remediation", "machine", machine.GetName())
+			return ctrl.Result{}, err
+		} else if owner != nil {
+			msg := fmt.Sprintf(duplicateRemediationErrorMsg, client.ObjectKeyFromObject(owner))
+			if updateRequired, err := r.updateConditions(remediationSkippedDuplicate, mdr); err != nil {
+				return ctrl.Result{}, err
+			} else if updateRequired {
+				setConditionMessage(mdr, commonconditions.SucceededType, msg)
+				log.Info(msg, "machine", machine.GetName())
+				commonevents.WarningEvent(r.Recorder, mdr, string(remediationSkippedDuplicate), msg)
+			}
+			return ctrl.Result{}, nil
+		}
+	}
+
 	// Detect if Node name is expected to change after Machine deletion given
-	// the Platform type. In case of BareMetal platform, the name is NOT
-	// expected to change, for other providers, instead, the name is expected
-	// to change.
-	var status metav1.ConditionStatus
-	var permanentNodeDeletionExpectedMsg string
-
-	// The providerID contains the platform type as prefix (e.g. baremetal:///...)
-	if machine.Spec.ProviderID == nil || *machine.Spec.ProviderID == "" {
-		log.Info("Machine does not have ProviderID")
-		permanentNodeDeletionExpectedMsg = machineDeletedOnUnknownProviderMessage
-		status = metav1.ConditionUnknown
-	} else if strings.HasPrefix(*machine.Spec.ProviderID, "baremetal") {
-		permanentNodeDeletionExpectedMsg = machineDeletedOnBareMetalProviderMessage
-		status = metav1.ConditionFalse
-	} else {
-		permanentNodeDeletionExpectedMsg = machineDeletedOnCloudProviderMessage
-		status = metav1.ConditionTrue
+	// the platform. On bare metal platforms the name is NOT expected to change,
+	// on the other ones, instead, the name is expected to change.
+	status, err := r.getPermanentNodeDeletionExpectation(ctx, backend, machine, config)
+	if err != nil {
+		return ctrl.Result{}, err
 	}
 
 	if updateRequired := r.setPermanentNodeDeletionExpectedCondition(status, mdr); updateRequired {
+		permanentNodeDeletionExpectedMsg := meta.FindStatusCondition(mdr.Status.Conditions, commonconditions.PermanentNodeDeletionExpectedType).Message
 		log.Info(permanentNodeDeletionExpectedMsg)
 		commonevents.NormalEvent(r.Recorder, mdr, "PermanentNodeDeletionExpected", permanentNodeDeletionExpectedMsg)
-		return ctrl.Result{RequeueAfter: time.Second}, nil
+		return ctrl.Result{RequeueAfter: config.StatusUpdateRequeueInterval.Duration}, nil
 	}
 
 	if !machine.GetDeletionTimestamp().IsZero() {
 		// Machine deletion requested already. Log deletion progress until the Machine exists
-		log.Info(postponedMachineDeletionInfo, "machine", machine.Name, "machine status.phase", machine.Status.Phase)
-		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		log.Info(postponedMachineDeletionInfo, "machine", machine.GetName(), "machine status.phase", backend.getPhase(machine))
+		if failed, err := r.followBareMetalHost(ctx, mdr); err != nil {
+			return ctrl.Result{}, err
+		} else if failed {
+			return ctrl.Result{}, nil
+		}
+		stuckRemaining, err := r.handleStuckDeletion(ctx, mdr, backend, machine)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		requeueAfter := r.getPollInterval(mdr)
+		if backend.hasPreTerminateHook(machine) {
+			if requeueAfter, err = r.handlePreTerminateHook(ctx, mdr, backend, machine); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		if stuckRemaining > 0 && stuckRemaining < requeueAfter {
+			requeueAfter = stuckRemaining
+		}
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
 	}
 
 	if !hasControllerOwner(machine) {
 		log.Info(noControllerOwnerErrorMsg, "machine", machine.GetName(), "remediation name", mdr.Name)
+		if isFallbackRemediationEnabled(config, mdr) {
+			return ctrl.Result{}, r.startFallbackRemediation(ctx, mdr, remediationSkippedNoControllerOwner, noControllerOwnerErrorMsg)
+		}
 		commonevents.WarningEvent(r.Recorder, mdr, string(remediationSkippedNoControllerOwner), noControllerOwnerErrorMsg)
 		_, err = r.updateConditions(remediationSkippedNoControllerOwner, mdr)
 		return ctrl.Result{}, err
 	}
 
-	// save Machine's name and namespace to follow its deletion phase
-	if err = r.saveMachineData(ctx, mdr, machine); err != nil {
-		log.Error(err, "could not save Machine's Name and Namespace", "machine name", machine.GetName(), "machine namespace", machine.GetNamespace())
-		return ctrl.Result{}, errors.Wrapf(err, "failed to save Machine's name and namespace")
+	if err = r.setMachineData(ctx, mdr, backend, machine); err != nil {
+		log.Error(err, "could not get Machine's data", "machine name", machine.GetName(), "machine namespace", machine.GetNamespace())
+		return ctrl.Result{}, errors.Wrapf(err, "failed to get Machine's data")
+	}
+
+	if owner := mdr.Status.MachineOwner; owner == nil || !slices.Contains(config.EnabledOwnerKinds, owner.Kind) {
+		log.Info(ownerKindNotEnabledErrorMsg, "machine", machine.GetName(), "enabled owner kinds", config.EnabledOwnerKinds)
+		if isFallbackRemediationEnabled(config, mdr) {
+			return ctrl.Result{}, r.startFallbackRemediation(ctx, mdr, remediationSkippedOwnerKindNotEnabled, ownerKindNotEnabledErrorMsg)
+		}
+		commonevents.WarningEvent(r.Recorder, mdr, string(remediationSkippedOwnerKindNotEnabled), ownerKindNotEnabledErrorMsg)
+		_, err = r.updateConditions(remediationSkippedOwnerKindNotEnabled, mdr)
+		return ctrl.Result{}, err
+	}
+
+	if atRisk, msg, err := r.isControlPlaneQuorumAtRisk(ctx, mdr, backend, machine); err != nil {
+		log.Error(err, "could not verify control plane quorum", "machine", machine.GetName())
+		return ctrl.Result{}, err
+	} else if atRisk {
+		if updateRequired, err := r.updateConditions(remediationBlockedQuorumAtRisk, mdr); err != nil {
+			return ctrl.Result{}, err
+		} else if updateRequired {
+			setConditionMessage(mdr, commonconditions.ProcessingType, msg)
+			log.Info(msg, "machine", machine.GetName())
+			commonevents.WarningEvent(r.Recorder, mdr, string(remediationBlockedQuorumAtRisk), msg)
+		}
+		return ctrl.Result{RequeueAfter: r.getPollInterval(mdr)}, nil
+	} else if updateRequired, err := r.updateConditions(remediationStarted, mdr); err != nil {
+		return ctrl.Result{}, err
+	} else if updateRequired {
+		log.Info("control plane quorum is not at risk anymore, resuming remediation", "machine", machine.GetName())
+	}
+
+	if isApprovalRequired(mdr) {
+		if approved, requeueAfter, err := r.waitForApproval(ctx, mdr, machine); err != nil {
+			return ctrl.Result{}, err
+		} else if !approved {
+			return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		}
+	}
+
+	// once the drain started, the remediation is not deferred anymore if the maintenance window closes
+	if mdr.Status.Drain == nil {
+		if requeueAfter, err := r.deferToMaintenanceWindow(ctx, mdr, machine, config); errors.Cause(err) == invalidMaintenanceWindowError {
+			msg := err.Error()
+			if updateRequired, err := r.updateConditions(remediationInvalidMaintenanceWindow, mdr); err != nil {
+				return ctrl.Result{}, err
+			} else if updateRequired {
+				setConditionMessage(mdr, commonconditions.SucceededType, msg)
+				log.Info(msg, "machine", machine.GetName())
+				commonevents.WarningEvent(r.Recorder, mdr, string(remediationInvalidMaintenanceWindow), msg)
+			}
+			return ctrl.Result{}, nil
+		} else if err != nil {
+			log.Error(err, "could not verify the maintenance windows", "machine", machine.GetName())
+			return ctrl.Result{}, err
+		} else if requeueAfter > 0 {
+			return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		}
+	}
+
+	if reached, msg, err := r.isMaxInFlightReached(ctx, mdr); err != nil {
+		log.Error(err, "could not verify the number of Machines being deleted", "machine", machine.GetName())
+		return ctrl.Result{}, err
+	} else if reached {
+		if updateRequired := setWaitingCondition(mdr, metav1.ConditionTrue, v1alpha1.MaxInFlightReachedReason, msg); updateRequired {
+			log.Info(msg, "machine", machine.GetName())
+			commonevents.NormalEvent(r.Recorder, mdr, v1alpha1.MaxInFlightReachedReason, msg)
+		}
+		return ctrl.Result{RequeueAfter: r.getPollInterval(mdr)}, nil
+	} else if meta.FindStatusCondition(mdr.Status.Conditions, v1alpha1.WaitingConditionType) != nil {
+		setWaitingCondition(mdr, metav1.ConditionFalse, v1alpha1.MaxInFlightNotReachedReason, "")
+	}
+
+	// all the checks passed: in dry run mode, report the Machine instead of draining the Node and deleting it
+	if isDryRunEnabled(config, mdr) {
+		return ctrl.Result{}, r.completeDryRun(mdr, machine)
+	}
+
+	if isDrainEnabled(mdr) {
+		if node, err := r.getMachineNode(ctx, backend, machine); err != nil {
+			log.Error(err, "could not get the machine's node to drain it", "machine", machine.GetName())
+			return ctrl.Result{}, err
+		} else if node == nil {
+			log.Info("machine has no node, skipping drain", "machine", machine.GetName())
+		} else if drained, requeueAfter, err := r.drainNode(ctx, mdr, node); err != nil {
+			log.Error(err, "could not drain node", "node", node.GetName())
+			return ctrl.Result{}, err
+		} else if !drained {
+			return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		}
+	}
+
+	if isPreTerminateHookEnabled(mdr) {
+		if err = r.addPreTerminateHook(ctx, mdr, backend, machine); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// save the Machine deletion request to follow its deletion phase
+	if err = r.saveMachineDeletionRequest(ctx, mdr); err != nil {
+		log.Error(err, "could not save Machine's data", "machine name", machine.GetName(), "machine namespace", machine.GetNamespace())
+		return ctrl.Result{}, errors.Wrapf(err, "failed to save Machine's data")
 	}
 
 	log.Info("request machine deletion", "machine", machine.GetName(), "remediation name", mdr.Name)
-	err = r.Delete(ctx, machine)
+	var deleteOpts []client.DeleteOption
+	if mdr.Spec.DeletionPropagation != nil {
+		deleteOpts = append(deleteOpts, client.PropagationPolicy(*mdr.Spec.DeletionPropagation))
+	}
+	err = r.Delete(ctx, machine, deleteOpts...)
 	if err != nil {
 		log.Error(err, "failed to delete machine", "machine", machine.GetName())
 		return ctrl.Result{}, err
 	}
 	// The actual remediation has just started. This should be reached only once per CR.
 	commonevents.RemediationStarted(r.Recorder, mdr)
+	recordRemediationStarted(mdr)
 
 	// requeue immediately to check machine deletion progression
 	return ctrl.Result{Requeue: true}, nil
 }
 
-func hasControllerOwner(machine *machinev1beta1.Machine) bool {
-	refs := machine.GetOwnerReferences()
-	for i := range refs {
-		if refs[i].Controller != nil && *refs[i].Controller {
-			return true
-		}
-	}
-	return false
+func hasControllerOwner(machine metav1.Object) bool {
+	return metav1.GetControllerOfNoCopy(machine) != nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *MachineDeletionRemediationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// The Pods are cached cluster-wide even if no remediation drains its Node, because indexes cannot be added to the
+	// informers once the cache started. The drain lists the Pods of the Node on every requeue until they are gone,
+	// which would otherwise cost a List from the API server each time.
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &v1.Pod{}, podNodeNameField, indexPodsByNodeName); err != nil {
+		return err
+	}
+	// The Node of a Machine is looked up by the quorum check, the replacement tracking, the drain and the stuck
+	// deletion detection, without listing all the Nodes each time
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &v1.Node{}, nodeMachineField, indexNodesByMachine); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &v1.Node{}, nodeProviderIDField, indexNodesByProviderID); err != nil {
+		return err
+	}
+	var err error
+	if r.capiMachines, err = setupCapiMachineIndexes(mgr); err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.MachineDeletionRemediation{}).
+		Watches(&v1alpha1.MachineDeletionRemediationApproval{}, handler.EnqueueRequestsFromMapFunc(getApprovedRemediation)).
 		Complete(r)
 }
 
@@ -288,10 +570,19 @@ func (r *MachineDeletionRemediationReconciler) getRemediation(ctx context.Contex
 	return remediation, nil
 }
 
+// getNodeName returns the name of the Node to be remediated. NHC sets the NodeNameAnnotation on the CRs of
+// remediators supporting multiple templates, as their name is generated. Otherwise, the CR's name is the Node's name.
+func getNodeName(remediation *v1alpha1.MachineDeletionRemediation) string {
+	if nodeName := remediation.GetAnnotations()[commonannotations.NodeNameAnnotation]; nodeName != "" {
+		return nodeName
+	}
+	return remediation.GetName()
+}
+
 func (r *MachineDeletionRemediationReconciler) getNodeFromCR(ctx context.Context, mdr *v1alpha1.MachineDeletionRemediation) (*v1.Node, error) {
 	node := &v1.Node{}
 	key := client.ObjectKey{
-		Name: mdr.Name,
+		Name: getNodeName(mdr),
 	}
 
 	if err := r.Get(ctx, key, node); err != nil {
@@ -301,81 +592,195 @@ func (r *MachineDeletionRemediationReconciler) getNodeFromCR(ctx context.Context
 }
 
 // getMachine retrieves a Machine from the cluster based on the remediation.
-// It returns the machine and an error if any occurred during the retrieval process.
-func (r *MachineDeletionRemediationReconciler) getMachine(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation) (*machinev1beta1.Machine, error) {
+// It returns the backend handling the machine, the machine and an error if any occurred during the retrieval process.
+func (r *MachineDeletionRemediationReconciler) getMachine(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation) (machineBackend, client.Object, error) {
 	// The Name and Namespace to retrieve the target Machine can come from the following sources:
 	// - the remediation's ownerReference: if the remediation was created by MachineHealthcheck
 	// - the remediation's Node: if the remediation was created by NodeHealthcheck or manually
 	// - the remediation's MachineNameNsAnnotation annotation: once the Machine is found and its Name and Namespace are saved in
 
-	// Try to get first the Machine's data from the remediation's annotation, if any. It means that the Machine was already
-	// been found in a previous cycle and we can use the data to verify if the Machine was deleted upon our request or not
-	machineName, machineNs, err := getRemediationDataFromAnnotation(remediation, MachineNameNsAnnotation)
-	if err != nil {
+	// Try to get first the Machine's data from the remediation's status, if its deletion was requested. It means that
+	// the Machine was already been found in a previous cycle and we can use the data to verify if the Machine was deleted upon our request or not
+	if err := migrateMachineDataAnnotations(remediation); err != nil {
 		r.Log.Error(err, "could not get Machine data from remediation", "remediation", remediation.GetName(), "annotation", MachineNameNsAnnotation)
-		return nil, unrecoverableError
+		return nil, nil, unrecoverableError
 	}
 
-	// If the Machine's Name is not in the annotation, it means that it must come from the one of the other two
+	var backend machineBackend
+	var machineName, machineNs string
+	// If the Machine deletion was not requested yet, the Machine must come from the one of the other two
 	// sources and in turns it means that the Machine must exist in the cluster, otherwise an error is returned.
-	isUnhandledMachine := machineName == ""
+	isUnhandledMachine := remediation.Status.MachineDeletionRequestedTime == nil || remediation.Status.Machine == nil
 	if isUnhandledMachine {
-		if machineName, machineNs = r.getMachineNameNsFromOwnerReference(ctx, remediation); machineName == "" {
-			if machineName, machineNs, err = r.getMachineNameNsFromRemediationName(ctx, remediation); err != nil {
-				if apiErrors.IsNotFound(err) {
-					r.Log.Error(err, nodeNotFoundErrorMsg, "node name", remediation.Name)
-					err = nodeNotFoundError
-				}
-				return nil, err
-			}
+		var err error
+		if backend, machineName, machineNs, err = r.getTargetMachineNameNs(ctx, remediation); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		var err error
+		if backend, err = r.getMachineBackendFromStatus(remediation); err != nil {
+			r.Log.Error(err, "could not get Machine API from remediation", "remediation", remediation.GetName(), "apiVersion", remediation.Status.Machine.APIVersion)
+			return nil, nil, unrecoverableError
 		}
+		machineName, machineNs = remediation.Status.Machine.Name, remediation.Status.Machine.Namespace
 	}
 
-	r.Log.Info("Looking for the target Machine", "machine", machineName, "namespace", machineNs)
-	machine := &machinev1beta1.Machine{}
+	r.Log.Info("Looking for the target Machine", "machine", machineName, "namespace", machineNs, "API group", backend.apiGroup())
+	machine := backend.newMachine()
 	if err := r.Get(ctx, client.ObjectKey{Name: machineName, Namespace: machineNs}, machine); err != nil {
 		if !apiErrors.IsNotFound(err) {
-			return nil, err
+			return nil, nil, err
 		}
 
 		// Machine was not found in the cluster, one the following cases must apply:
 		// - if it was already handled, it might have been just deleted upon our request
 		// - otherwise, it must exist in the cluster and if not an error is returned.
 		if isUnhandledMachine {
-			r.Log.Error(err, machineNotFoundErrorMsg, "node", remediation.Name, "machine", machineName)
-			return nil, machineNotFoundError
+			r.Log.Error(err, machineNotFoundErrorMsg, "node", getNodeName(remediation), "machine", machineName)
+			return nil, nil, machineNotFoundError
 		}
 
-		r.Log.Info(successfulMachineDeletionInfo, "node", remediation.Name, "machine", machineName)
-		return nil, nil
+		r.Log.Info(successfulMachineDeletionInfo, "node", getNodeName(remediation), "machine", machineName)
+		return backend, nil, nil
 	}
 
-	return machine, nil
+	return backend, machine, nil
 }
 
-// saveMachineData saves target Machine data in a remediation's annotation
-func (r *MachineDeletionRemediationReconciler) saveMachineData(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation, machine *machinev1beta1.Machine) error {
-	annotations := remediation.GetAnnotations()
-	if annotations == nil {
-		annotations = make(map[string]string, 1)
+// getTargetMachineNameNs returns the backend, the name and the namespace of the Machine targeted by the remediation,
+// either from the remediation's ownerReference or from its Node
+func (r *MachineDeletionRemediationReconciler) getTargetMachineNameNs(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation) (machineBackend, string, string, error) {
+	if backend, machineName, machineNs := r.getMachineNameNsFromOwnerReference(ctx, remediation); machineName != "" {
+		return backend, machineName, machineNs, nil
 	}
+	return r.getMachineNameNsFromRemediationName(ctx, remediation)
+}
 
-	if _, exists := annotations[MachineNameNsAnnotation]; !exists {
-		annotations[MachineNameNsAnnotation] =
-			fmt.Sprintf("%s/%s", machine.Namespace, machine.Name)
+// ResolveMachine returns the Machine targeted by the remediation. It is used by the validating webhook to detect
+// duplicate remediations, and to reject the remediations whose Machine cannot be found.
+func (r *MachineDeletionRemediationReconciler) ResolveMachine(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation) (v1alpha1.MachineKey, error) {
+	machine, err := r.resolveMachine(ctx, remediation)
+	if err != nil {
+		return v1alpha1.MachineKey{}, err
+	}
+	key, err := r.getMachineKey(machine)
+	if err != nil {
+		return v1alpha1.MachineKey{}, err
 	}
+	return v1alpha1.MachineKey{Group: key.Group, ObjectKey: key.ObjectKey}, nil
+}
 
-	if _, exists := annotations[MachineOwnerAnnotation]; !exists {
-		name, kind, err := getMachineOwnerNameKind(machine)
-		if err != nil {
-			return err
+// resolveMachine gets the Machine targeted by the remediation, see ResolveMachine
+func (r *MachineDeletionRemediationReconciler) resolveMachine(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation) (client.Object, error) {
+	backend, machineName, machineNs, err := r.getTargetMachineNameNs(ctx, remediation)
+	if err == nodeNotFoundError {
+		return nil, fmt.Errorf("node %s not found", getNodeName(remediation))
+	} else if errors.Cause(err) == unrecoverableError {
+		return nil, fmt.Errorf("node %s is not associated with a supported Machine", getNodeName(remediation))
+	} else if err != nil {
+		return nil, err
+	}
+
+	key := client.ObjectKey{Name: machineName, Namespace: machineNs}
+	machine := backend.newMachine()
+	if err := r.Get(ctx, key, machine); err != nil {
+		if apiErrors.IsNotFound(err) {
+			return nil, fmt.Errorf("machine %s of node %s not found", key, getNodeName(remediation))
+		}
+		return nil, err
+	}
+	return machine, nil
+}
+
+// setMachineData sets the target Machine data in the remediation's status
+func (r *MachineDeletionRemediationReconciler) setMachineData(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation, backend machineBackend, machine client.Object) error {
+	gvk, err := apiutil.GVKForObject(machine, r.Client.Scheme())
+	if err != nil {
+		return err
+	}
+	remediation.Status.Machine = &v1alpha1.ObjectReference{
+		APIVersion: gvk.GroupVersion().String(),
+		Kind:       gvk.Kind,
+		Name:       machine.GetName(),
+		Namespace:  machine.GetNamespace(),
+	}
+
+	name, kind, err := backend.getMachineOwnerNameKind(ctx, machine)
+	if err != nil {
+		return err
+	}
+	remediation.Status.MachineOwner = nil
+	if name != "" {
+		var apiVersion string
+		if resolver, exists := r.getMachineOwnerResolver(backend, kind); exists {
+			apiVersion = resolver.apiVersion
+		}
+		remediation.Status.MachineOwner = &v1alpha1.ObjectReference{
+			APIVersion: apiVersion,
+			Kind:       kind,
+			Name:       name,
+			Namespace:  machine.GetNamespace(),
 		}
-		annotations[MachineOwnerAnnotation] = fmt.Sprintf("%s/%s", kind, name)
 	}
 
-	remediation.SetAnnotations(annotations)
+	remediation.Status.ProviderID = backend.getProviderID(machine)
+	r.setBareMetalHost(remediation, machine)
+	return nil
+}
 
-	return r.Update(ctx, remediation)
+// saveMachineDeletionRequest saves the Machine deletion request time in the remediation's status. The status is
+// updated right away, so that the Machine is not looked up again through its Node once deleted.
+func (r *MachineDeletionRemediationReconciler) saveMachineDeletionRequest(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation) error {
+	if remediation.Status.MachineDeletionRequestedTime == nil {
+		now := metav1.Now()
+		remediation.Status.MachineDeletionRequestedTime = &now
+	}
+
+	remediation.Status.Phase = getRemediationPhase(remediation)
+	return r.Client.Status().Update(ctx, remediation)
+}
+
+// migrateMachineDataAnnotations moves the target Machine data saved in the remediation's annotations by previous
+// versions to the remediation's status. Those versions supported OpenShift Machines only.
+func migrateMachineDataAnnotations(remediation *v1alpha1.MachineDeletionRemediation) error {
+	if remediation.Status.Machine != nil {
+		return nil
+	}
+
+	machineName, machineNs, err := getRemediationDataFromAnnotation(remediation, MachineNameNsAnnotation)
+	if err != nil || machineName == "" {
+		return err
+	}
+
+	ownerName, ownerKind, err := getRemediationDataFromAnnotation(remediation, MachineOwnerAnnotation)
+	if err != nil {
+		return err
+	}
+
+	remediation.Status.Machine = &v1alpha1.ObjectReference{
+		APIVersion: machinev1beta1.GroupVersion.String(),
+		Kind:       "Machine",
+		Name:       machineName,
+		Namespace:  machineNs,
+	}
+	if ownerName != "" {
+		var apiVersion string
+		if resolver, exists := getBuiltinOwnerResolver(machinev1beta1.GroupName, ownerKind); exists {
+			apiVersion = resolver.apiVersion
+		}
+		remediation.Status.MachineOwner = &v1alpha1.ObjectReference{
+			APIVersion: apiVersion,
+			Kind:       ownerKind,
+			Name:       ownerName,
+			Namespace:  machineNs,
+		}
+	}
+	if remediation.Status.MachineDeletionRequestedTime == nil {
+		// the deletion request time was not saved, the remediation's creation is the closest approximation
+		creationTime := remediation.GetCreationTimestamp()
+		remediation.Status.MachineDeletionRequestedTime = &creationTime
+	}
+	return nil
 }
 
 // getRemediationDataFromAnnotation returns the data saved in the provided annotation of the remediation.
@@ -411,16 +816,29 @@ func (r *MachineDeletionRemediationReconciler) updateConditions(reason condition
 	var processingConditionStatus, succeededConditionStatus metav1.ConditionStatus
 
 	switch reason {
-	case remediationStarted:
+	case remediationStarted,
+		remediationBlockedQuorumAtRisk,
+		remediationFallbackStarted:
 		processingConditionStatus = metav1.ConditionTrue
 		succeededConditionStatus = metav1.ConditionUnknown
-	case remediationFinishedMachineDeleted:
+	case remediationFinishedMachineDeleted,
+		remediationFallbackSucceeded:
 		processingConditionStatus = metav1.ConditionFalse
 		succeededConditionStatus = metav1.ConditionTrue
+	case remediationSkippedDryRun:
+		// NHC escalates to the next remediation as soon as Succeeded is False, which a dry run must not trigger
+		processingConditionStatus = metav1.ConditionFalse
+		succeededConditionStatus = metav1.ConditionUnknown
 	case remediationTimedOutByNhc,
+		remediationNodeRestorationTimedOut,
 		remediationSkippedNoControllerOwner,
+		remediationSkippedOwnerKindNotEnabled,
+		remediationSkippedDuplicate,
 		remediationSkippedNodeNotFound,
 		remediationSkippedMachineNotFound,
+		remediationInvalidMaintenanceWindow,
+		remediationBareMetalHostError,
+		remediationFallbackFailed,
 		remediationFailed:
 		processingConditionStatus = metav1.ConditionFalse
 		succeededConditionStatus = metav1.ConditionFalse
@@ -436,9 +854,11 @@ func (r *MachineDeletionRemediationReconciler) updateConditions(reason condition
 		return false, nil
 	}
 
-	// if the requested Status.Conditions are already set, skip update
+	// if the requested Status.Conditions are already set, skip update. The reason of an ongoing remediation can change
+	// (e.g. when it is blocked and then resumed), while the reason of a completed one is final
 	if meta.IsStatusConditionPresentAndEqual(mdr.Status.Conditions, commonconditions.ProcessingType, processingConditionStatus) &&
-		meta.IsStatusConditionPresentAndEqual(mdr.Status.Conditions, commonconditions.SucceededType, succeededConditionStatus) {
+		meta.IsStatusConditionPresentAndEqual(mdr.Status.Conditions, commonconditions.SucceededType, succeededConditionStatus) &&
+		(processingConditionStatus != metav1.ConditionTrue || isConditionReason(mdr, commonconditions.ProcessingType, reason)) {
 		return false, nil
 	}
 
@@ -458,6 +878,55 @@ func (r *MachineDeletionRemediationReconciler) updateConditions(reason condition
 	return true, nil
 }
 
+// getPermanentNodeDeletionExpectation detects the Machine's platform, and returns whether the Node of the replacement
+// Machine is expected to get a new name (True), keep the name of the deleted one (False), or whether it is not known
+// (Unknown). Machines without providerID are detected by their providerSpec and the cluster's Infrastructure CR only.
+// Machines on platforms not detected get a new name, unless their providerID is not set.
+func (r *MachineDeletionRemediationReconciler) getPermanentNodeDeletionExpectation(ctx context.Context, backend machineBackend, machine client.Object, config v1alpha1.MachineDeletionRemediationConfigSpec) (metav1.ConditionStatus, error) {
+	platformType, err := r.getPlatformType(ctx)
+	if err != nil {
+		r.Log.Error(err, "could not get the cluster's platform type")
+		return "", err
+	}
+	platform := PlatformInfo{
+		ProviderID:       backend.getProviderID(machine),
+		ProviderSpecKind: backend.getProviderSpecKind(machine),
+		PlatformType:     platformType,
+		BareMetalHost:    machine.GetAnnotations()[bareMetalHostAnnotation],
+		MachineName:      machine.GetName(),
+	}
+	if platform.BareMetalHostExternallyProvisioned, err = r.isBareMetalHostExternallyProvisioned(ctx, machine); err != nil {
+		return "", err
+	}
+	if node, err := r.getMachineNode(ctx, backend, machine); err != nil {
+		r.Log.Error(err, "could not get the machine's node", "machine", machine.GetName())
+		return "", err
+	} else if node != nil {
+		platform.NodeName = node.GetName()
+	}
+
+	detector, detected := detectPlatform(r.getPlatformDetectors(config.BareMetalProviderIDPrefixes), platform)
+	if !detected {
+		if platform.ProviderID == "" {
+			r.Log.Info("Machine does not have ProviderID and its platform is unknown", "machine", machine.GetName(),
+				"providerSpec kind", platform.ProviderSpecKind, "platform type", platform.PlatformType)
+			return metav1.ConditionUnknown, nil
+		}
+		r.Log.Info("unknown platform, the Node name is expected to change", "machine", machine.GetName(), "providerID", platform.ProviderID)
+		return metav1.ConditionTrue, nil
+	}
+	expectation := detector.GetNodeNameExpectation(platform)
+	r.Log.Info("detected Machine platform", "machine", machine.GetName(), "platform", detector.Name(), "Node name", expectation)
+	switch expectation {
+	case NodeNameKept:
+		return metav1.ConditionFalse, nil
+	case NodeNameChanged:
+		return metav1.ConditionTrue, nil
+	default:
+		return metav1.ConditionUnknown, nil
+	}
+}
+
 func (r *MachineDeletionRemediationReconciler) setPermanentNodeDeletionExpectedCondition(status metav1.ConditionStatus, mdr *v1alpha1.MachineDeletionRemediation) bool {
 	var reason, message string
 	switch status {
@@ -487,6 +956,109 @@ func (r *MachineDeletionRemediationReconciler) setPermanentNodeDeletionExpectedC
 	return true
 }
 
+// getOperatorConfig returns the operator configuration, or the default one if not set
+func (r *MachineDeletionRemediationReconciler) getOperatorConfig() *OperatorConfig {
+	if r.Config != nil {
+		return r.Config
+	}
+	return NewOperatorConfig()
+}
+
+// getConfig returns the effective operator configuration
+func (r *MachineDeletionRemediationReconciler) getConfig() v1alpha1.MachineDeletionRemediationConfigSpec {
+	spec := r.getOperatorConfig().Get()
+	if spec.MaxInFlight == nil && r.MaxInFlight != nil {
+		spec.MaxInFlight = r.MaxInFlight
+	}
+	return spec
+}
+
+// getPollInterval returns the interval between two checks of the remediation progress
+func (r *MachineDeletionRemediationReconciler) getPollInterval(remediation *v1alpha1.MachineDeletionRemediation) time.Duration {
+	if remediation.Spec.PollInterval == nil || remediation.Spec.PollInterval.Duration <= 0 {
+		return r.getConfig().DefaultPollInterval.Duration
+	}
+	return remediation.Spec.PollInterval.Duration
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isWaitForNodeReplacementEnabled checks if the remediation has to wait for the Nodes to be replaced before succeeding
+func isWaitForNodeReplacementEnabled(remediation *v1alpha1.MachineDeletionRemediation) bool {
+	return remediation.Spec.WaitForNodeReplacement == nil || *remediation.Spec.WaitForNodeReplacement
+}
+
+// getNodeRestorationTimeoutStatus returns whether the remediation's NodeRestorationTimeout expired, how long MDR has
+// been waiting for the Nodes to be restored since the Machine deletion request, and the time left before the timeout.
+func getNodeRestorationTimeoutStatus(remediation *v1alpha1.MachineDeletionRemediation) (timedOut bool, waited, remaining time.Duration) {
+	if remediation.Status.MachineDeletionRequestedTime == nil {
+		return false, 0, 0
+	}
+
+	waited = time.Since(remediation.Status.MachineDeletionRequestedTime.Time)
+	if remediation.Spec.NodeRestorationTimeout == nil || remediation.Spec.NodeRestorationTimeout.Duration <= 0 {
+		return false, waited, 0
+	}
+
+	remaining = remediation.Spec.NodeRestorationTimeout.Duration - waited
+	return remaining <= 0, waited, remaining
+}
+
+// getRemediationPhase summarizes the remediation progress from its conditions and the Machine deletion timestamps
+func getRemediationPhase(remediation *v1alpha1.MachineDeletionRemediation) v1alpha1.RemediationPhase {
+	switch {
+	case meta.IsStatusConditionTrue(remediation.Status.Conditions, commonconditions.SucceededType):
+		return v1alpha1.RemediationPhaseSucceeded
+	case meta.IsStatusConditionFalse(remediation.Status.Conditions, commonconditions.SucceededType):
+		return v1alpha1.RemediationPhaseFailed
+	case meta.IsStatusConditionTrue(remediation.Status.Conditions, v1alpha1.DryRunConditionType):
+		return v1alpha1.RemediationPhaseDryRun
+	case meta.IsStatusConditionTrue(remediation.Status.Conditions, v1alpha1.WaitingConditionType):
+		return v1alpha1.RemediationPhaseWaiting
+	case isAwaitingApproval(remediation):
+		return v1alpha1.RemediationPhaseAwaitingApproval
+	case isDeferred(remediation):
+		return v1alpha1.RemediationPhaseDeferred
+	case remediation.Status.FallbackRemediation != nil:
+		return v1alpha1.RemediationPhaseFallbackRemediation
+	case remediation.Status.MachineDeletedTime != nil:
+		return v1alpha1.RemediationPhaseWaitingForReplacement
+	case remediation.Status.MachineDeletionRequestedTime != nil:
+		return v1alpha1.RemediationPhaseMachineDeletionRequested
+	case remediation.Status.Drain != nil && remediation.Status.Drain.CompletionTime == nil:
+		return v1alpha1.RemediationPhaseDraining
+	case meta.IsStatusConditionTrue(remediation.Status.Conditions, commonconditions.ProcessingType):
+		return v1alpha1.RemediationPhaseStarted
+	default:
+		return ""
+	}
+}
+
+// isRemediationStarted checks if the remediation has already been started, regardless of its outcome
+func isRemediationStarted(remediation *v1alpha1.MachineDeletionRemediation) bool {
+	return meta.FindStatusCondition(remediation.Status.Conditions, commonconditions.ProcessingType) != nil
+}
+
+// isConditionReason checks if the given condition is set with the given reason
+func isConditionReason(remediation *v1alpha1.MachineDeletionRemediation, conditionType string, reason conditionChangeReason) bool {
+	condition := meta.FindStatusCondition(remediation.Status.Conditions, conditionType)
+	return condition != nil && condition.Reason == string(reason)
+}
+
+// setConditionMessage sets the message of the given condition, if it exists
+func setConditionMessage(remediation *v1alpha1.MachineDeletionRemediation, conditionType, message string) {
+	if condition := meta.FindStatusCondition(remediation.Status.Conditions, conditionType); condition != nil {
+		condition.Message = message
+	}
+}
+
 // isTimedOutByNHC checks if NHC set a timeout annotation on the CR
 func (r *MachineDeletionRemediationReconciler) isTimedOutByNHC(remediation *v1alpha1.MachineDeletionRemediation) bool {
 	if remediation != nil && remediation.Annotations != nil && remediation.DeletionTimestamp == nil {
@@ -496,135 +1068,210 @@ func (r *MachineDeletionRemediationReconciler) isTimedOutByNHC(remediation *v1al
 	return false
 }
 
-// isExpectedNodesNumberRestored checks if the number of nodes associated to the Machine Owner is equal to the
-// Machine Owner's Replicas value
-func (r *MachineDeletionRemediationReconciler) isExpectedNodesNumberRestored(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation) (bool, error) {
-	_, namespace, err := getRemediationDataFromAnnotation(remediation, MachineNameNsAnnotation)
-	if err != nil {
-		return false, errors.Wrap(unrecoverableError, err.Error())
+// isReplacementNodeReady looks for the Machine created by the Machine owner to replace the deleted one, and checks
+// if its Node is Ready. The names of the replacement Machine and Node are saved in the remediation's status.
+func (r *MachineDeletionRemediationReconciler) isReplacementNodeReady(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation) (bool, error) {
+	if remediation.Status.Machine == nil || remediation.Status.MachineOwner == nil {
+		return false, errors.Wrap(unrecoverableError, "the remediation status does not contain the Machine and its owner")
 	}
+	machineName, namespace := remediation.Status.Machine.Name, remediation.Status.Machine.Namespace
+	name, kind := remediation.Status.MachineOwner.Name, remediation.Status.MachineOwner.Kind
 
-	name, kind, err := getRemediationDataFromAnnotation(remediation, MachineOwnerAnnotation)
+	backend, err := r.getMachineBackendFromStatus(remediation)
 	if err != nil {
 		return false, errors.Wrap(unrecoverableError, err.Error())
 	}
 
-	replicas, err := r.getMachineOwnerSpecReplicas(ctx, kind, name, namespace)
+	replicas, err := r.getMachineOwnerSpecReplicas(ctx, backend, kind, name, namespace)
 	if err != nil {
 		r.Log.Error(err, "could not get Machine owner's Spec.Replicas", "kind", kind, "name", name, "namespace", namespace)
 		return false, err
 	}
 
 	if replicas == 0 {
-		r.Log.Info("Machine owner's Spec.Replicas is 0, no need to verify Node count restoration")
+		r.Log.Info("Machine owner's Spec.Replicas is 0, no replacement Machine is expected")
 		return true, nil
 	}
 
-	nodes, err := r.getMachineOwnerNodes(ctx, name)
+	replacement, err := r.getReplacementMachine(ctx, remediation, backend, kind, name, machineName, namespace)
 	if err != nil {
-		r.Log.Error(err, "could not get Machine owner's nodes", "kind", kind, "name", name, "namespace", namespace)
+		r.Log.Error(err, "could not get replacement Machine", "kind", kind, "name", name, "namespace", namespace)
 		return false, err
 	}
+	if replacement == nil {
+		r.Log.Info("replacement Machine not created yet", "owner kind", kind, "owner name", name)
+		return false, nil
+	}
+	remediation.Status.ReplacementMachineName = replacement.GetName()
 
-	r.Log.Info("verifying nodes count restoration", "expected", replicas, "actual", len(nodes))
-	return len(nodes) == replicas, nil
-}
+	node, err := r.getMachineNode(ctx, backend, replacement)
+	if err != nil {
+		r.Log.Error(err, "could not get replacement Machine's Node", "machine", replacement.GetName())
+		return false, err
+	}
+	if node == nil {
+		r.Log.Info("replacement Machine's Node not created yet", "machine", replacement.GetName())
+		return false, nil
+	}
+	remediation.Status.ReplacementNodeName = node.GetName()
 
-// getMachineOwner returns the MachineSet object given its name and namespace
-func (r *MachineDeletionRemediationReconciler) getMachineOwner(ctx context.Context, kind, name, namespace string) (*unstructured.Unstructured, error) {
-	kindToApiVersionMap := map[string]string{
-		"MachineSet":             machinev1beta1.GroupVersion.String(),
-		"ControlPlaneMachineSet": machinev1.GroupVersion.String(),
+	isReady := isNodeReady(node)
+	r.Log.Info("verifying replacement Node readiness", "machine", replacement.GetName(), "node", node.GetName(), "ready", isReady)
+	if isReady && remediation.Status.ReplacementNodeReadyTime == nil {
+		now := metav1.Now()
+		remediation.Status.ReplacementNodeReadyTime = &now
 	}
+	return isReady, nil
+}
 
-	apiVersion, exists := kindToApiVersionMap[kind]
-	if !exists {
-		return nil, errors.Wrap(unrecoverableError, fmt.Sprintf("unknown kind %s", kind))
+// getReplacementMachine returns the oldest Machine created by the owner after the deletion of the remediated Machine was
+// requested, which is not already tracked as replacement by another remediation. It returns nil if there is none yet.
+func (r *MachineDeletionRemediationReconciler) getReplacementMachine(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation, backend machineBackend, ownerKind, ownerName, deletedMachineName, namespace string) (client.Object, error) {
+	// the replacement Machine is expected to be created after the deletion request
+	deletionRequestedTime := remediation.GetCreationTimestamp().Time
+	if remediation.Status.MachineDeletionRequestedTime != nil {
+		deletionRequestedTime = remediation.Status.MachineDeletionRequestedTime.Time
 	}
 
-	r.Log.Info("getting Machine owner", "kind", kind, "name", name, "namespace", namespace, "apiVersion", apiVersion)
+	// if the remediation already found its replacement, keep using it until it exists
+	if name := remediation.Status.ReplacementMachineName; name != "" {
+		machine := backend.newMachine()
+		if err := r.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, machine); err == nil {
+			if machine.GetDeletionTimestamp().IsZero() {
+				return machine, nil
+			}
+		} else if !apiErrors.IsNotFound(err) {
+			return nil, err
+		}
+	}
 
-	owner := &unstructured.Unstructured{}
-	owner.SetKind(kind)
-	owner.SetAPIVersion(apiVersion)
-	key := client.ObjectKey{
-		Name:      name,
-		Namespace: namespace,
+	machines, err := backend.listMachines(ctx, namespace)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := r.Get(ctx, key, owner); err != nil {
-		if apiErrors.IsNotFound(err) {
-			return nil, errors.Wrap(unrecoverableError, err.Error())
-		}
+	isOwned, err := r.getOwnedMachineFilter(ctx, backend, ownerKind, ownerName, namespace)
+	if err != nil {
 		return nil, err
 	}
-	return owner, nil
-}
 
-// getMachineOwnerNodes returns the Nodes associated to the Machine's Owner
-func (r *MachineDeletionRemediationReconciler) getMachineOwnerNodes(ctx context.Context, ownerName string) ([]v1.Node, error) {
-	allNodes := &v1.NodeList{}
-	if err := r.List(ctx, allNodes); err != nil {
+	claimedMachines, err := r.getClaimedReplacementMachines(ctx, remediation, namespace)
+	if err != nil {
 		return nil, err
 	}
 
-	machineOwnerNodes := []v1.Node{}
-	for _, node := range allNodes.Items {
-		machineName, machineNs, err := getMachineNameNsFromNode(&node)
-		if err != nil {
-			r.Log.Error(err, "could not get MachineNameNS from Node", "node", node.Name)
+	var replacement client.Object
+	for _, machine := range machines {
+		creationTime := machine.GetCreationTimestamp().Time
+		switch {
+		case !machine.GetDeletionTimestamp().IsZero(),
+			creationTime.Before(deletionRequestedTime),
+			machine.GetName() == deletedMachineName && !creationTime.After(deletionRequestedTime),
+			claimedMachines[machine.GetName()],
+			!isOwned(machine):
 			continue
 		}
-
-		machine := machinev1beta1.Machine{}
-		key := client.ObjectKey{
-			Name:      machineName,
-			Namespace: machineNs,
+		if replacement == nil || creationTime.Before(replacement.GetCreationTimestamp().Time) {
+			replacement = machine
 		}
-		if err := r.Get(ctx, key, &machine); err != nil {
-			r.Log.Error(err, "could not get Machine of Node", "node", node.Name, "search key", key)
+	}
+	return replacement, nil
+}
+
+// getClaimedReplacementMachines returns the names of the Machines in the given namespace already tracked as
+// replacement by other remediations
+func (r *MachineDeletionRemediationReconciler) getClaimedReplacementMachines(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation, namespace string) (map[string]bool, error) {
+	remediations := &v1alpha1.MachineDeletionRemediationList{}
+	if err := r.List(ctx, remediations); err != nil {
+		return nil, err
+	}
+
+	claimed := make(map[string]bool)
+	for _, other := range remediations.Items {
+		if other.GetUID() == remediation.GetUID() || other.Status.ReplacementMachineName == "" {
 			continue
 		}
+		if other.Status.Machine != nil && other.Status.Machine.Namespace == namespace {
+			claimed[other.Status.ReplacementMachineName] = true
+		}
+	}
+	return claimed, nil
+}
 
-		if curOwnerName, _, err := getMachineOwnerNameKind(&machine); err != nil {
-			r.Log.Error(err, "no valid ownerReference", "node", node.Name, "machine", machine.Name)
-		} else if curOwnerName == ownerName {
-			machineOwnerNodes = append(machineOwnerNodes, node)
+func isNodeReady(node *v1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == v1.NodeReady {
+			return condition.Status == v1.ConditionTrue
 		}
 	}
+	return false
+}
+
+// getMachineOwner returns the Machine owner object given its kind, name and namespace
+func (r *MachineDeletionRemediationReconciler) getMachineOwner(ctx context.Context, backend machineBackend, kind, name, namespace string) (*unstructured.Unstructured, error) {
+	resolver, exists := r.getMachineOwnerResolver(backend, kind)
+	if !exists {
+		return nil, errors.Wrap(unrecoverableError, fmt.Sprintf("unknown kind %s", kind))
+	}
+
+	r.Log.Info("getting Machine owner", "kind", kind, "name", name, "namespace", namespace, "apiVersion", resolver.apiVersion)
+
+	owner := &unstructured.Unstructured{}
+	owner.SetKind(kind)
+	owner.SetAPIVersion(resolver.apiVersion)
+	key := client.ObjectKey{
+		Name:      name,
+		Namespace: namespace,
+	}
 
-	return machineOwnerNodes, nil
+	if err := r.Get(ctx, key, owner); err != nil {
+		if apiErrors.IsNotFound(err) {
+			return nil, errors.Wrap(unrecoverableError, err.Error())
+		}
+		return nil, err
+	}
+	return owner, nil
 }
 
-func (r *MachineDeletionRemediationReconciler) getMachineNameNsFromRemediationName(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation) (machineName, machineNs string, err error) {
+func (r *MachineDeletionRemediationReconciler) getMachineNameNsFromRemediationName(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation) (backend machineBackend, machineName, machineNs string, err error) {
 	var node *v1.Node
 	node, err = r.getNodeFromCR(ctx, remediation)
 	if err != nil {
 		if apiErrors.IsNotFound(err) {
-			r.Log.Error(err, nodeNotFoundErrorMsg, "node name", remediation.Name)
+			r.Log.Error(err, nodeNotFoundErrorMsg, "node name", getNodeName(remediation))
 			err = nodeNotFoundError
 		}
-		return "", "", err
+		return nil, "", "", err
 	}
 
-	machineName, machineNs, err = getMachineNameNsFromNode(node)
+	backend, machineName, machineNs, err = r.getMachineBackendForNode(ctx, node)
 	if err != nil {
 		r.Log.Error(err, "could not get Machine Name NS from Node", "node", node.Name, "annotations", node.GetAnnotations())
-		return "", "", unrecoverableError
+		return nil, "", "", unrecoverableError
 	}
-	return machineName, machineNs, nil
+	return backend, machineName, machineNs, nil
 }
 
-func (r *MachineDeletionRemediationReconciler) getMachineNameNsFromOwnerReference(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation) (machineName, machineNs string) {
+func (r *MachineDeletionRemediationReconciler) getMachineNameNsFromOwnerReference(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation) (backend machineBackend, machineName, machineNs string) {
 	for _, owner := range remediation.GetOwnerReferences() {
-		if owner.Kind == "Machine" {
-			machineName, machineNs = owner.Name, remediation.Namespace
-			r.Log.Info("remediation's ownerReference has Machine Kind", "machine", machineName, "namespace", machineNs)
-			break
+		if owner.Kind != "Machine" {
+			continue
 		}
+		gv, err := schema.ParseGroupVersion(owner.APIVersion)
+		if err != nil {
+			r.Log.Error(err, "invalid apiVersion in remediation's ownerReference", "apiVersion", owner.APIVersion)
+			continue
+		}
+		if backend, err = r.getMachineBackend(gv.Group); err != nil {
+			r.Log.Error(err, "unsupported Machine in remediation's ownerReference", "apiVersion", owner.APIVersion)
+			continue
+		}
+		machineName, machineNs = owner.Name, remediation.Namespace
+		r.Log.Info("remediation's ownerReference has Machine Kind", "machine", machineName, "namespace", machineNs, "API group", gv.Group)
+		break
 	}
 
-	return machineName, machineNs
+	return backend, machineName, machineNs
 }
 
 func getMachineNameNsFromNode(node *v1.Node) (string, string, error) {
@@ -644,35 +1291,28 @@ func getMachineNameNsFromNode(node *v1.Node) (string, string, error) {
 	return "", "", fmt.Errorf(invalidValueMachineAnnotationError, node.Name)
 }
 
-// getMachineOwnerNameKind returns the Machine's ownerReference name and Kind. It returns an error if the Machine has
-// more than one Owner Reference.
-func getMachineOwnerNameKind(machine *machinev1beta1.Machine) (name, kind string, err error) {
-	owners := machine.GetOwnerReferences()
-	switch len(owners) {
-	case 0:
-		// not an error, Machines can be created without ownerReference
-		return "", "", nil
-	case 1:
-		return owners[0].Name, owners[0].Kind, nil
-	default:
-		return "", "", fmt.Errorf("machine has more than one owner")
+// getMachineOwnerNameKind returns the name and Kind of the Machine's controller ownerReference. Other ownerReferences
+// are ignored, and empty values are returned if the Machine has no controller owner.
+func getMachineOwnerNameKind(machine metav1.Object) (name, kind string) {
+	if owner := metav1.GetControllerOfNoCopy(machine); owner != nil {
+		return owner.Name, owner.Kind
 	}
+	return "", ""
 }
 
-// getMachineOwnerSpecReplicas returns the Machine's owner Spec.Replicas field
-func (r *MachineDeletionRemediationReconciler) getMachineOwnerSpecReplicas(ctx context.Context, kind, name, namespace string) (int, error) {
-	owner, err := r.getMachineOwner(ctx, kind, name, namespace)
+// getMachineOwnerSpecReplicas returns the desired replicas of the Machine's owner, found at the replicas path of its Kind
+func (r *MachineDeletionRemediationReconciler) getMachineOwnerSpecReplicas(ctx context.Context, backend machineBackend, kind, name, namespace string) (int, error) {
+	owner, err := r.getMachineOwner(ctx, backend, kind, name, namespace)
 	if err != nil {
 		r.Log.Error(err, "could not get Machine owner", "kind", kind, "name", name, "namespace", namespace)
-		if apiErrors.IsNotFound(err) {
-			return 0, errors.Wrap(unrecoverableError, err.Error())
-		}
 		return 0, err
 	}
 
-	replicas, _, err := unstructured.NestedInt64(owner.Object, "spec", "replicas")
+	// the owner was found, so its Kind is known
+	resolver, _ := r.getMachineOwnerResolver(backend, kind)
+	replicas, err := resolver.getReplicas(owner)
 	if err != nil {
 		return 0, errors.Wrap(unrecoverableError, err.Error())
 	}
-	return int(replicas), nil
+	return replicas, nil
 }
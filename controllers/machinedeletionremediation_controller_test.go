@@ -3,20 +3,29 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	commonannotations "github.com/medik8s/common/pkg/annotations"
 	commonconditions "github.com/medik8s/common/pkg/conditions"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
 
 	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	configv1 "github.com/openshift/api/config/v1"
 	machinev1 "github.com/openshift/api/machine/v1"
 	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
 
@@ -41,6 +50,10 @@ const (
 	processingConditionSetAndMatchSuccess                                = "ProcessingConditionSetAndMatch"
 	processingConditionSetButWrongReasonError                            = "processingConditionSetButWrongReason"
 	processingConditionStartedInfo                                       = "{\"processingConditionStatus\": \"True\", \"succededConditionStatus\": \"Unknown\", \"reason\": \"RemediationStarted\"}"
+	capiMachineSetKind, capiMachineDeploymentKind, capiKcpKind           = "MachineSet", "MachineDeployment", "KubeadmControlPlane"
+	capiMachineSetName, capiMachineDeploymentName, capiKcpName           = "capi-machine-set-x", "capi-machine-deployment-x", "capi-kcp-x"
+	capiWorkerNodeName, capiCpNodeName                                   = "capi-worker-node-x", "capi-cp-node-x"
+	capiWorkerMachineName, capiCpMachineName                             = "capi-worker-node-x-machine", "capi-cp-node-x-machine"
 )
 
 var underTest *v1alpha1.MachineDeletionRemediation
@@ -68,19 +81,64 @@ var _ = Describe("Machine Deletion Remediation CR", func() {
 				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: defaultNamespace},
 			}
 			Expect(k8sClient.Create(context.Background(), underTest)).To(Succeed())
-			DeferCleanup(k8sClient.Delete, underTest)
+			DeferCleanup(deleteRemediationAndWait, underTest)
 		})
 
 		When("creating a resource", func() {
 			It("CR is namespace scoped", func() {
 				Expect(underTest.Namespace).To(Not(BeEmpty()))
 			})
+
+			It("CR spec has default values", func() {
+				Expect(underTest.Spec.PollInterval).To(BeNil())
+				Expect(underTest.Spec.WaitForNodeReplacement).To(Equal(ptr.To(true)))
+				Expect(underTest.Spec.DeletionPropagation).To(Equal(ptr.To(metav1.DeletePropagationBackground)))
+			})
+		})
+
+		When("creating a resource with invalid spec", func() {
+			It("is rejected", func() {
+				invalid := &v1alpha1.MachineDeletionRemediation{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-invalid", Namespace: defaultNamespace},
+				}
+				invalid.Spec.DeletionPropagation = ptr.To(metav1.DeletionPropagation("Never"))
+				Expect(k8sClient.Create(context.Background(), invalid)).ToNot(Succeed())
+			})
 		})
 	})
 
+	Context("Machine owner", func() {
+		DescribeTable("is the controller ownerReference",
+			func(owners []metav1.OwnerReference, expectedName, expectedKind string) {
+				machine := &machinev1beta1.Machine{ObjectMeta: metav1.ObjectMeta{OwnerReferences: owners}}
+				name, kind := getMachineOwnerNameKind(machine)
+				Expect(name).To(Equal(expectedName))
+				Expect(kind).To(Equal(expectedKind))
+				Expect(hasControllerOwner(machine)).To(Equal(expectedName != ""))
+			},
+			Entry("no owner", nil, "", ""),
+			Entry("single controller owner", []metav1.OwnerReference{
+				{Kind: machineSetKind, Name: machineSetName, Controller: ptr.To(true)},
+			}, machineSetName, machineSetKind),
+			Entry("single owner which is not a controller", []metav1.OwnerReference{
+				{Kind: machineSetKind, Name: machineSetName},
+			}, "", ""),
+			Entry("controller owner after other owners", []metav1.OwnerReference{
+				{Kind: "ConfigMap", Name: "machine-inventory"},
+				{Kind: "Secret", Name: "machine-credentials", Controller: ptr.To(false)},
+				{Kind: cpmsKind, Name: cpmsName, Controller: ptr.To(true)},
+			}, cpmsName, cpmsKind),
+			Entry("several owners without controller", []metav1.OwnerReference{
+				{Kind: "ConfigMap", Name: "machine-inventory"},
+				{Kind: machineSetKind, Name: machineSetName, Controller: ptr.To(false)},
+			}, "", ""),
+		)
+	})
+
 	Context("Reconciliation", func() {
 		BeforeEach(func() {
 			plogs.Clear()
+			clearEvents()
 
 			machineSet = createMachineSet(machineSetName, 1)
 			machineSetZeroReplicas = createMachineSet(machineSetNameZeroReplicas, 0)
@@ -130,7 +188,7 @@ var _ = Describe("Machine Deletion Remediation CR", func() {
 
 		JustBeforeEach(func() {
 			Expect(k8sClient.Create(context.Background(), underTest)).To(Succeed())
-			DeferCleanup(k8sClient.Delete, underTest)
+			DeferCleanup(deleteRemediationAndWait, underTest)
 		})
 
 		Context("Sunny Flows", func() {
@@ -219,8 +277,63 @@ var _ = Describe("Machine Deletion Remediation CR", func() {
 				})
 			})
 
+			When("remediation associated machine has a controller owner ref among other owner refs", func() {
+				BeforeEach(func() {
+					workerNodeMachine.OwnerReferences = append([]metav1.OwnerReference{
+						{APIVersion: "v1", Kind: "ConfigMap", Name: "machine-inventory", UID: "5678"},
+					}, workerNodeMachine.OwnerReferences...)
+					Expect(k8sClient.Update(context.Background(), workerNodeMachine)).To(Succeed())
+					underTest = createRemediationOwnedByNHC(workerNode.Name)
+				})
+
+				It("worker machine is deleted and its controller owner is saved", func() {
+					verifyMachineIsDeleted(workerNodeMachineName)
+					verifyRemediationStatusMachine(machinev1beta1.GroupVersion.String(), workerNodeMachineName, machineSetKind, machineSetName)
+				})
+			})
+
+			When("remediation associated machine has several owner refs without controller", func() {
+				BeforeEach(func() {
+					workerNodeMachine.OwnerReferences[0].Controller = nil
+					workerNodeMachine.OwnerReferences = append(workerNodeMachine.OwnerReferences,
+						metav1.OwnerReference{APIVersion: "v1", Kind: "ConfigMap", Name: "machine-inventory", UID: "5678"})
+					Expect(k8sClient.Update(context.Background(), workerNodeMachine)).To(Succeed())
+					underTest = createRemediationOwnedByNHC(workerNode.Name)
+				})
+
+				It("No machine is deleted", func() {
+					verifyMachineNotDeleted(workerNodeMachineName)
+					verifyConditionsMatch([]expectedCondition{
+						{commonconditions.ProcessingType, metav1.ConditionFalse, remediationSkippedNoControllerOwner},
+						{commonconditions.SucceededType, metav1.ConditionFalse, remediationSkippedNoControllerOwner}})
+				})
+			})
+
+			When("remediation associated machine owner's kind is not enabled in the operator configuration", func() {
+				BeforeEach(func() {
+					updateOperatorConfig(func(spec *v1alpha1.MachineDeletionRemediationConfigSpec) {
+						spec.EnabledOwnerKinds = []string{"ControlPlaneMachineSet"}
+					})
+					underTest = createRemediationOwnedByNHC(workerNode.Name)
+				})
+
+				It("No machine is deleted", func() {
+					verifyMachineNotDeleted(workerNodeMachineName)
+					verifyConditionsMatch([]expectedCondition{
+						{commonconditions.ProcessingType, metav1.ConditionFalse, remediationSkippedOwnerKindNotEnabled},
+						{commonconditions.SucceededType, metav1.ConditionFalse, remediationSkippedOwnerKindNotEnabled},
+						// Cluster provider is not set in this test
+						{commonconditions.PermanentNodeDeletionExpectedType, metav1.ConditionUnknown, v1alpha1.MachineDeletionOnUndefinedProviderReason}})
+					verifyEvents([]expectedEvent{
+						{v1.EventTypeWarning, "RemediationSkippedOwnerKindNotEnabled", ownerKindNotEnabledErrorMsg, true},
+						{v1.EventTypeNormal, "RemediationStarted", "Remediation started", false},
+					})
+				})
+			})
+
 			When("remediation associated machine has valid owner ref of CPMS Kind", func() {
 				BeforeEach(func() {
+					createHealthyControlPlanePeers(cpms, 2)
 					underTest = createRemediationOwnedByNHC(cpNodeWithOwnerList[0].Name)
 				})
 
@@ -248,6 +361,7 @@ var _ = Describe("Machine Deletion Remediation CR", func() {
 						cpNode := cpNodeWithOwnerList[i]
 						cpNode.Annotations[machineAnnotationOpenshift] = fmt.Sprintf("%s/%s", machineNamespace, replacementName)
 						Expect(k8sClient.Update(context.Background(), &cpNode)).To(Succeed())
+						setNodeReady(&cpNode)
 					}
 
 					// Now the remediation should be completed
@@ -262,13 +376,122 @@ var _ = Describe("Machine Deletion Remediation CR", func() {
 				})
 			})
 
+			When("deleting the control plane machine would put the quorum at risk", func() {
+				BeforeEach(func() {
+					// only one healthy peer, while 2 out of 3 members are required
+					createHealthyControlPlanePeers(cpms, 1)
+					underTest = createRemediationOwnedByNHC(cpNodeWithOwnerList[0].Name)
+				})
+
+				It("CP machine is deleted only once the quorum is not at risk anymore", func() {
+					verifyConditionsMatch([]expectedCondition{
+						{commonconditions.ProcessingType, metav1.ConditionTrue, remediationBlockedQuorumAtRisk},
+						{commonconditions.SucceededType, metav1.ConditionUnknown, remediationBlockedQuorumAtRisk}})
+					verifyMachineNotDeleted(cpNodeMachineName)
+					verifyEvents([]expectedEvent{
+						{v1.EventTypeWarning, "RemediationBlockedQuorumAtRisk", fmt.Sprintf(quorumAtRiskErrorMsg, 1, 3, 2), true},
+						{v1.EventTypeNormal, "RemediationStarted", "Remediation started", false},
+					})
+
+					peer := createMachineWithOwner(cpNodeMachineName+"-late-peer", cpms)
+					Expect(k8sClient.Create(context.Background(), peer)).To(Succeed())
+					DeferCleanup(deleteIgnoreNotFound(), peer)
+					peerNode := createNodeWithMachine(cpNodeWithOwnerName+"-late-peer", peer)
+					Expect(k8sClient.Create(context.Background(), peerNode)).To(Succeed())
+					DeferCleanup(k8sClient.Delete, peerNode)
+					setNodeReady(peerNode)
+
+					verifyMachineIsDeleted(cpNodeMachineName)
+					verifyConditionsMatch([]expectedCondition{
+						{commonconditions.ProcessingType, metav1.ConditionTrue, remediationStarted},
+						{commonconditions.SucceededType, metav1.ConditionUnknown, remediationStarted}})
+				})
+			})
+
+			When("another control plane node is being remediated", func() {
+				BeforeEach(func() {
+					peerNodes := createHealthyControlPlanePeers(cpms, 2)
+					otherRemediation := createRemediationOwnedByNHC(peerNodes[0].Name)
+					Expect(k8sClient.Create(context.Background(), otherRemediation)).To(Succeed())
+					DeferCleanup(deleteRemediationAndWait, otherRemediation)
+					underTest = createRemediationOwnedByNHC(cpNodeWithOwnerList[0].Name)
+				})
+
+				It("CP machine is not deleted", func() {
+					verifyConditionsMatch([]expectedCondition{
+						{commonconditions.ProcessingType, metav1.ConditionTrue, remediationBlockedQuorumAtRisk},
+						{commonconditions.SucceededType, metav1.ConditionUnknown, remediationBlockedQuorumAtRisk}})
+					verifyMachineNotDeleted(cpNodeMachineName)
+				})
+			})
+
+			When("the worker machine's MachineSet limits the machines being deleted at the same time", func() {
+				var secondWorkerNode *v1.Node
+				var secondWorkerMachine *machinev1beta1.Machine
+
+				BeforeEach(func() {
+					Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(machineSet), machineSet)).To(Succeed())
+					machineSet.Spec.Replicas = ptr.To[int32](2)
+					machineSet.SetAnnotations(map[string]string{MaxInFlightAnnotation: "50%"})
+					Expect(k8sClient.Update(context.Background(), machineSet)).To(Succeed())
+
+					secondWorkerMachine = createMachineWithOwner(workerNodeMachineName+"-2", machineSet)
+					Expect(k8sClient.Create(context.Background(), secondWorkerMachine)).To(Succeed())
+					DeferCleanup(deleteIgnoreNotFound(), secondWorkerMachine)
+					secondWorkerNode = createNodeWithMachine(workerNodeName+"-2", secondWorkerMachine)
+					Expect(k8sClient.Create(context.Background(), secondWorkerNode)).To(Succeed())
+					DeferCleanup(k8sClient.Delete, secondWorkerNode)
+
+					// the first remediation is created before, or at least it is sorted before, the one under test
+					firstRemediation := createRemediationOwnedByNHC(workerNode.Name)
+					Expect(k8sClient.Create(context.Background(), firstRemediation)).To(Succeed())
+					DeferCleanup(deleteRemediationAndWait, firstRemediation)
+
+					underTest = createRemediationOwnedByNHC(secondWorkerNode.Name)
+				})
+
+				It("waits for the first remediation to complete before deleting the machine", func() {
+					verifyMachineIsDeleted(workerNodeMachineName)
+					verifyConditionMatches(v1alpha1.WaitingConditionType, metav1.ConditionTrue, v1alpha1.MaxInFlightReachedReason)
+					verifyRemediationPhase(v1alpha1.RemediationPhaseWaiting)
+					verifyMachineNotDeleted(secondWorkerMachine.Name)
+					verifyEvents([]expectedEvent{
+						{v1.EventTypeNormal, v1alpha1.MaxInFlightReachedReason, fmt.Sprintf(ownerMaxInFlightReachedMsg, 1, machineSetKind, machineSetName, 1), true},
+					})
+
+					// complete the first remediation
+					replacement := createMachineWithOwner(workerNodeMachineName+"-replacement", machineSet)
+					Expect(k8sClient.Create(context.Background(), replacement)).To(Succeed())
+					DeferCleanup(k8sClient.Delete, replacement)
+					workerNode.Annotations[machineAnnotationOpenshift] = fmt.Sprintf("%s/%s", machineNamespace, replacement.Name)
+					Expect(k8sClient.Update(context.Background(), workerNode)).To(Succeed())
+					setNodeReady(workerNode)
+
+					verifyMachineIsDeleted(secondWorkerMachine.Name)
+					verifyConditionMatches(v1alpha1.WaitingConditionType, metav1.ConditionFalse, v1alpha1.MaxInFlightNotReachedReason)
+				})
+			})
+
 			When("worker node remediation exists", func() {
+				var metricsLabels prometheus.Labels
+				var startedBefore, succeededBefore float64
+
 				BeforeEach(func() {
 					underTest = createRemediationOwnedByNHC(workerNode.Name)
+					// Cluster provider is not set in this test
+					metricsLabels = prometheus.Labels{ownerKindLabel: machineSetKind, providerTypeLabel: unknownLabelValue}
+					startedBefore = getCounterValue(remediationsStarted, metricsLabels)
+					succeededBefore = getCounterValue(remediationsSucceeded, metricsLabels)
 				})
 				It("worker machine is deleted", func() {
 					verifyMachineIsDeleted(workerNodeMachineName)
 					verifyMachineNotDeleted(masterNodeMachineName)
+					Eventually(func() float64 {
+						return getCounterValue(remediationsStarted, metricsLabels)
+					}, "10s", "100ms").Should(Equal(startedBefore + 1))
+					Eventually(func() float64 {
+						return getGaugeValue(remediationsInFlight, metricsLabels)
+					}, "10s", "100ms").Should(Equal(float64(1)))
 
 					// Machine is deleted, but the remediation is not completed yet
 					verifyConditionsMatch([]expectedCondition{
@@ -276,6 +499,8 @@ var _ = Describe("Machine Deletion Remediation CR", func() {
 						{commonconditions.SucceededType, metav1.ConditionUnknown, remediationStarted},
 						// Cluster provider is not set in this test
 						{commonconditions.PermanentNodeDeletionExpectedType, metav1.ConditionUnknown, v1alpha1.MachineDeletionOnUndefinedProviderReason}})
+					verifyRemediationStatusMachine(machinev1beta1.GroupVersion.String(), workerNodeMachineName, machineSetKind, machineSetName)
+					verifyRemediationPhase(v1alpha1.RemediationPhaseWaitingForReplacement)
 
 					// Mock Machine and Node re-provisioning (even though this test does not actually delete the node, just the machine).
 					// 1. Create a Machine's replacement with a new name
@@ -287,6 +512,7 @@ var _ = Describe("Machine Deletion Remediation CR", func() {
 
 					workerNode.Annotations[machineAnnotationOpenshift] = fmt.Sprintf("%s/%s", machineNamespace, machineReplacementName)
 					Expect(k8sClient.Update(context.Background(), workerNode)).To(Succeed())
+					setNodeReady(workerNode)
 
 					// Now the remediation should be completed
 					verifyConditionsMatch([]expectedCondition{
@@ -298,23 +524,1021 @@ var _ = Describe("Machine Deletion Remediation CR", func() {
 						{v1.EventTypeNormal, "RemediationStarted", "Remediation started", true},
 						{v1.EventTypeNormal, "RemediationFinished", "Remediation finished", true},
 					})
+
+					verifyRemediationPhase(v1alpha1.RemediationPhaseSucceeded)
+					Eventually(func() float64 {
+						return getCounterValue(remediationsSucceeded, metricsLabels)
+					}, "10s", "100ms").Should(Equal(succeededBefore + 1))
+					Eventually(func() float64 {
+						return getGaugeValue(remediationsInFlight, metricsLabels)
+					}, "10s", "100ms").Should(BeZero())
+					mdr := &v1alpha1.MachineDeletionRemediation{}
+					Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+					Expect(mdr.Status.MachineDeletionRequestedTime).ToNot(BeNil())
+					Expect(mdr.Status.MachineDeletedTime).ToNot(BeNil())
+					Expect(mdr.Status.MachineDeletedTime.Before(mdr.Status.MachineDeletionRequestedTime)).To(BeFalse())
+					Expect(mdr.Status.ReplacementNodeName).To(Equal(workerNode.Name))
+					Expect(mdr.Status.ReplacementNodeReadyTime).ToNot(BeNil())
+				})
+			})
+
+			When("remediation was created by a previous version saving the machine data in annotations", func() {
+				BeforeEach(func() {
+					underTest = createRemediationOwnedByNHCWithAnnotation(workerNode.Name, MachineNameNsAnnotation,
+						fmt.Sprintf("%s/%s", machineNamespace, workerNodeMachineName))
+					underTest.Annotations[MachineOwnerAnnotation] = fmt.Sprintf("%s/%s", machineSetKind, machineSetName)
+				})
+
+				It("migrates the machine data to the status", func() {
+					verifyMachineIsDeleted(workerNodeMachineName)
+					verifyRemediationStatusMachine(machinev1beta1.GroupVersion.String(), workerNodeMachineName, machineSetKind, machineSetName)
+				})
+			})
+
+			When("remediation is created by NHC with a generated name and the node name annotation", func() {
+				BeforeEach(func() {
+					underTest = createRemediationOwnedByNHCWithAnnotation(workerNode.Name+"-xyz12", commonannotations.NodeNameAnnotation, workerNode.Name)
+				})
+
+				It("worker machine is deleted", func() {
+					verifyMachineIsDeleted(workerNodeMachineName)
+					verifyMachineNotDeleted(masterNodeMachineName)
+					verifyConditionsMatch([]expectedCondition{
+						{commonconditions.ProcessingType, metav1.ConditionTrue, remediationStarted},
+						{commonconditions.SucceededType, metav1.ConditionUnknown, remediationStarted}})
+				})
+			})
+
+			When("remediation does not wait for node replacement", func() {
+				BeforeEach(func() {
+					underTest = createRemediationOwnedByNHC(workerNode.Name)
+					underTest.Spec.WaitForNodeReplacement = ptr.To(false)
+				})
+
+				It("succeeds as soon as the worker machine is deleted", func() {
+					verifyMachineIsDeleted(workerNodeMachineName)
+					verifyConditionsMatch([]expectedCondition{
+						{commonconditions.ProcessingType, metav1.ConditionFalse, remediationFinishedMachineDeleted},
+						{commonconditions.SucceededType, metav1.ConditionTrue, remediationFinishedMachineDeleted}})
+					verifyEvents([]expectedEvent{
+						{v1.EventTypeNormal, "RemediationFinished", "Remediation finished", true},
+					})
+				})
+			})
+
+			When("remediation runs in dry run mode", func() {
+				BeforeEach(func() {
+					underTest = createRemediationOwnedByNHC(workerNode.Name)
+					underTest.Spec.DryRun = true
+					underTest.Spec.Drain = &v1alpha1.DrainSpec{}
+				})
+
+				It("completes without draining the node nor deleting the worker machine", func() {
+					verifyConditionsMatch([]expectedCondition{
+						{commonconditions.ProcessingType, metav1.ConditionFalse, remediationSkippedDryRun},
+						{commonconditions.SucceededType, metav1.ConditionUnknown, remediationSkippedDryRun},
+						{v1alpha1.DryRunConditionType, metav1.ConditionTrue, v1alpha1.MachineDeletionSkippedReason}})
+					verifyRemediationPhase(v1alpha1.RemediationPhaseDryRun)
+					verifyMachineNotDeleted(workerNodeMachineName)
+					verifyEventEmitted(v1.EventTypeNormal, wouldDeleteMachineReason, workerNodeMachineName, workerNode.Name)
+					verifyEvents([]expectedEvent{
+						{v1.EventTypeNormal, "RemediationStarted", "Remediation started", false},
+					})
+
+					node := &v1.Node{}
+					Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(workerNode), node)).To(Succeed())
+					Expect(node.Spec.Unschedulable).To(BeFalse())
+					mdr := &v1alpha1.MachineDeletionRemediation{}
+					Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+					Expect(mdr.Status.MachineDeletionRequestedTime).To(BeNil())
+					Expect(mdr.Status.Drain).To(BeNil())
+				})
+			})
+
+			When("dry run is enabled in the operator configuration", func() {
+				BeforeEach(func() {
+					updateOperatorConfig(func(spec *v1alpha1.MachineDeletionRemediationConfigSpec) {
+						spec.DryRun = true
+					})
+					underTest = createRemediationOwnedByNHC(workerNode.Name)
+				})
+
+				It("does not delete the worker machine", func() {
+					verifyConditionsMatch([]expectedCondition{
+						{commonconditions.ProcessingType, metav1.ConditionFalse, remediationSkippedDryRun},
+						{commonconditions.SucceededType, metav1.ConditionUnknown, remediationSkippedDryRun},
+						{v1alpha1.DryRunConditionType, metav1.ConditionTrue, v1alpha1.MachineDeletionSkippedReason}})
+					verifyMachineNotDeleted(workerNodeMachineName)
+					verifyRemediationPhase(v1alpha1.RemediationPhaseDryRun)
+				})
+
+				It("does not delete the worker machine once dry run is disabled", func() {
+					verifyConditionMatches(v1alpha1.DryRunConditionType, metav1.ConditionTrue, v1alpha1.MachineDeletionSkippedReason)
+					updateOperatorConfig(func(spec *v1alpha1.MachineDeletionRemediationConfigSpec) {
+						spec.DryRun = false
+					})
+
+					// trigger a new reconciliation
+					Eventually(func() error {
+						mdr := &v1alpha1.MachineDeletionRemediation{}
+						if err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr); err != nil {
+							return err
+						}
+						mdr.Annotations = map[string]string{"test": "dry-run-disabled"}
+						return k8sClient.Update(context.Background(), mdr)
+					}, "10s", "100ms").Should(Succeed())
+
+					verifyMachineNotDeleted(workerNodeMachineName)
+					verifyConditionMatches(v1alpha1.DryRunConditionType, metav1.ConditionTrue, v1alpha1.MachineDeletionSkippedReason)
+					verifyRemediationPhase(v1alpha1.RemediationPhaseDryRun)
+				})
+			})
+
+			When("the operator restarts with dry run enabled in the operator configuration", func() {
+				BeforeEach(func() {
+					updateOperatorConfig(func(spec *v1alpha1.MachineDeletionRemediationConfigSpec) {
+						spec.DryRun = true
+					})
+					// the configuration is back to its defaults until it is loaded
+					operatorConfig.mutex.Lock()
+					operatorConfig.spec, operatorConfig.ownerResolvers, operatorConfig.loaded = NewOperatorConfig().Get(), nil, false
+					operatorConfig.mutex.Unlock()
+					DeferCleanup(loadOperatorConfig)
+
+					underTest = createRemediationOwnedByNHC(workerNode.Name)
+				})
+
+				It("does not delete the worker machine before and after the configuration is loaded", func() {
+					verifyMachineNotDeleted(workerNodeMachineName)
+					mdr := &v1alpha1.MachineDeletionRemediation{}
+					Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+					Expect(mdr.Status.Conditions).To(BeEmpty())
+
+					loadOperatorConfig()
+					verifyConditionsMatch([]expectedCondition{
+						{commonconditions.ProcessingType, metav1.ConditionFalse, remediationSkippedDryRun},
+						{commonconditions.SucceededType, metav1.ConditionUnknown, remediationSkippedDryRun},
+						{v1alpha1.DryRunConditionType, metav1.ConditionTrue, v1alpha1.MachineDeletionSkippedReason}})
+					verifyMachineNotDeleted(workerNodeMachineName)
+				})
+			})
+
+			When("remediation runs in dry run mode but the machine has no controller owner", func() {
+				BeforeEach(func() {
+					underTest = createRemediationOwnedByNHC(masterNode.Name)
+					underTest.Spec.DryRun = true
+				})
+
+				It("is skipped without setting the DryRun condition", func() {
+					verifyConditionsMatch([]expectedCondition{
+						{commonconditions.ProcessingType, metav1.ConditionFalse, remediationSkippedNoControllerOwner},
+						{commonconditions.SucceededType, metav1.ConditionFalse, remediationSkippedNoControllerOwner}})
+					verifyConditionUnset(v1alpha1.DryRunConditionType)
+				})
+			})
+
+			When("remediation requires an approval", func() {
+				BeforeEach(func() {
+					underTest = createRemediationOwnedByNHC(workerNode.Name)
+					underTest.Spec.Approval = &v1alpha1.ApprovalSpec{}
+				})
+
+				JustBeforeEach(func() {
+					verifyConditionMatches(v1alpha1.AwaitingApprovalConditionType, metav1.ConditionTrue, v1alpha1.ApprovalRequestedReason)
+					verifyRemediationPhase(v1alpha1.RemediationPhaseAwaitingApproval)
+					verifyEventEmitted(v1.EventTypeNormal, approvalRequestedEventReason, workerNodeMachineName)
+					verifyMachineNotDeleted(workerNodeMachineName)
+				})
+
+				It("deletes the worker machine once the approval annotation is set", func() {
+					Eventually(func() error {
+						mdr := &v1alpha1.MachineDeletionRemediation{}
+						if err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr); err != nil {
+							return err
+						}
+						mdr.Annotations = map[string]string{ApprovedAnnotation: ""}
+						return k8sClient.Update(context.Background(), mdr)
+					}, "10s", "100ms").Should(Succeed())
+
+					verifyMachineIsDeleted(workerNodeMachineName)
+					verifyConditionMatches(v1alpha1.AwaitingApprovalConditionType, metav1.ConditionFalse, v1alpha1.ApprovedReason)
+					verifyEventEmitted(v1.EventTypeNormal, machineDeletionApprovedReason, workerNodeMachineName, ApprovedAnnotation)
+				})
+
+				It("deletes the worker machine once a MachineDeletionRemediationApproval is created", func() {
+					approval := &v1alpha1.MachineDeletionRemediationApproval{
+						ObjectMeta: metav1.ObjectMeta{Name: "approval", Namespace: underTest.Namespace},
+						Spec:       v1alpha1.MachineDeletionRemediationApprovalSpec{RemediationName: underTest.Name},
+					}
+					Expect(k8sClient.Create(context.Background(), approval)).To(Succeed())
+					DeferCleanup(k8sClient.Delete, approval)
+
+					verifyMachineIsDeleted(workerNodeMachineName)
+					verifyConditionMatches(v1alpha1.AwaitingApprovalConditionType, metav1.ConditionFalse, v1alpha1.ApprovedReason)
+					mdr := &v1alpha1.MachineDeletionRemediation{}
+					Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+					Expect(mdr.Status.Approval).ToNot(BeNil())
+					Expect(mdr.Status.Approval.ApprovedTime).ToNot(BeNil())
+					Expect(mdr.Status.Approval.ApprovedBy).To(ContainSubstring(approval.Name))
+				})
+
+				When("the approval has an auto approve timeout", func() {
+					BeforeEach(func() {
+						underTest.Spec.Approval.AutoApproveAfter = &metav1.Duration{Duration: 3 * time.Second}
+					})
+
+					It("deletes the worker machine once the timeout expires", func() {
+						verifyMachineIsDeleted(workerNodeMachineName)
+						verifyConditionMatches(v1alpha1.AwaitingApprovalConditionType, metav1.ConditionFalse, v1alpha1.AutoApprovedReason)
+					})
+				})
+			})
+
+			When("remediation is restricted to maintenance windows", func() {
+				// a daily window which started more than an hour ago, and is already over
+				closedWindow := func() v1alpha1.MaintenanceWindow {
+					return v1alpha1.MaintenanceWindow{
+						Schedule: fmt.Sprintf("0 %d * * *", (time.Now().UTC().Hour()+22)%24),
+						Duration: metav1.Duration{Duration: time.Minute},
+					}
+				}
+
+				When("the maintenance window is closed", func() {
+					BeforeEach(func() {
+						underTest = createRemediationOwnedByNHC(workerNode.Name)
+						underTest.Spec.MaintenanceWindows = []v1alpha1.MaintenanceWindow{closedWindow()}
+					})
+
+					It("defers the deletion of the worker machine", func() {
+						verifyConditionMatches(v1alpha1.DeferredConditionType, metav1.ConditionTrue, v1alpha1.OutsideMaintenanceWindowReason)
+						verifyRemediationPhase(v1alpha1.RemediationPhaseDeferred)
+						verifyEventEmitted(v1.EventTypeNormal, remediationDeferredReason, workerNodeMachineName)
+						verifyMachineNotDeleted(workerNodeMachineName)
+
+						mdr := &v1alpha1.MachineDeletionRemediation{}
+						Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+						Expect(mdr.Status.NextMaintenanceWindowStart).ToNot(BeNil())
+						Expect(mdr.Status.NextMaintenanceWindowStart.Time).To(BeTemporally(">", time.Now()))
+						Expect(mdr.Status.MachineDeletionRequestedTime).To(BeNil())
+					})
+				})
+
+				When("the maintenance window is open", func() {
+					BeforeEach(func() {
+						underTest = createRemediationOwnedByNHC(workerNode.Name)
+						underTest.Spec.MaintenanceWindows = []v1alpha1.MaintenanceWindow{
+							closedWindow(),
+							{Schedule: "* * * * *", Duration: metav1.Duration{Duration: time.Hour}, TimeZone: "Europe/Paris"},
+						}
+					})
+
+					It("deletes the worker machine", func() {
+						verifyMachineIsDeleted(workerNodeMachineName)
+						verifyConditionUnset(v1alpha1.DeferredConditionType)
+					})
+				})
+
+				When("the maintenance window is invalid", func() {
+					BeforeEach(func() {
+						underTest = createRemediationOwnedByNHC(workerNode.Name)
+						underTest.Spec.MaintenanceWindows = []v1alpha1.MaintenanceWindow{
+							{Schedule: "0 25 * * *", Duration: metav1.Duration{Duration: time.Hour}},
+						}
+					})
+
+					It("fails without deleting the worker machine", func() {
+						verifyConditionsMatch([]expectedCondition{
+							{commonconditions.ProcessingType, metav1.ConditionFalse, remediationInvalidMaintenanceWindow},
+							{commonconditions.SucceededType, metav1.ConditionFalse, remediationInvalidMaintenanceWindow}})
+						verifyMachineNotDeleted(workerNodeMachineName)
+					})
+				})
+
+				When("the maintenance window is set in the operator configuration", func() {
+					BeforeEach(func() {
+						updateOperatorConfig(func(spec *v1alpha1.MachineDeletionRemediationConfigSpec) {
+							spec.MaintenanceWindows = []v1alpha1.MachineOwnerMaintenanceWindow{{MaintenanceWindow: closedWindow()}}
+						})
+						underTest = createRemediationOwnedByNHC(workerNode.Name)
+					})
+
+					It("defers the deletion of the worker machine", func() {
+						verifyConditionMatches(v1alpha1.DeferredConditionType, metav1.ConditionTrue, v1alpha1.OutsideMaintenanceWindowReason)
+						verifyMachineNotDeleted(workerNodeMachineName)
+					})
+				})
+			})
+
+			When("remediation drains the node", func() {
+				var pod, daemonSetPod *v1.Pod
+
+				BeforeEach(func() {
+					pod = createPodOnNode("drained-pod", workerNode)
+					daemonSetPod = createPodOnNode("daemonset-pod", workerNode)
+					daemonSetPod.SetOwnerReferences([]metav1.OwnerReference{
+						{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "daemonset", UID: "1234", Controller: ptr.To(true)},
+					})
+					Expect(k8sClient.Create(context.Background(), pod)).To(Succeed())
+					Expect(k8sClient.Create(context.Background(), daemonSetPod)).To(Succeed())
+					DeferCleanup(deletePodNow, pod)
+					DeferCleanup(deletePodNow, daemonSetPod)
+
+					underTest = createRemediationOwnedByNHC(workerNode.Name)
+					underTest.Spec.Drain = &v1alpha1.DrainSpec{GracePeriod: &metav1.Duration{}}
+				})
+
+				It("cordons the node and evicts its pods before deleting the worker machine", func() {
+					verifyMachineIsDeleted(workerNodeMachineName)
+
+					node := &v1.Node{}
+					Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(workerNode), node)).To(Succeed())
+					Expect(node.Spec.Unschedulable).To(BeTrue())
+					Expect(errors.IsNotFound(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(pod), &v1.Pod{}))).To(BeTrue())
+					Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(daemonSetPod), &v1.Pod{})).To(Succeed())
+
+					mdr := &v1alpha1.MachineDeletionRemediation{}
+					Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+					Expect(mdr.Status.Drain).ToNot(BeNil())
+					Expect(mdr.Status.Drain.NodeName).To(Equal(workerNode.Name))
+					Expect(mdr.Status.Drain.CompletionTime).ToNot(BeNil())
+					Expect(mdr.Status.Drain.PendingPods).To(BeZero())
+					Expect(mdr.Status.Drain.Forced).To(BeFalse())
+					verifyEvents([]expectedEvent{
+						{v1.EventTypeNormal, nodeDrainStartedReason, fmt.Sprintf(nodeDrainStartedMsg, workerNode.Name), true},
+						{v1.EventTypeNormal, nodeDrainCompletedReason, fmt.Sprintf(nodeDrainCompletedMsg, workerNode.Name), true},
+						{v1.EventTypeNormal, "RemediationStarted", "Remediation started", true},
+					})
+				})
+
+				When("the pod eviction is blocked by a PodDisruptionBudget", func() {
+					BeforeEach(func() {
+						// pods which are not running are evicted regardless of their PodDisruptionBudget
+						pod.Labels = map[string]string{"app": "drained"}
+						Expect(k8sClient.Update(context.Background(), pod)).To(Succeed())
+						pod.Status.Phase = v1.PodRunning
+						pod.Status.Conditions = []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}}
+						Expect(k8sClient.Status().Update(context.Background(), pod)).To(Succeed())
+
+						pdb := &policyv1.PodDisruptionBudget{
+							ObjectMeta: metav1.ObjectMeta{Name: "drained-pdb", Namespace: pod.Namespace},
+							Spec: policyv1.PodDisruptionBudgetSpec{
+								MinAvailable: ptr.To(intstr.FromInt32(1)),
+								Selector:     &metav1.LabelSelector{MatchLabels: pod.Labels},
+							},
+						}
+						Expect(k8sClient.Create(context.Background(), pdb)).To(Succeed())
+						DeferCleanup(k8sClient.Delete, pdb)
+
+						underTest.Spec.Drain.ForceAfter = &metav1.Duration{Duration: 3 * time.Second}
+					})
+
+					It("deletes the pod once the drain is forced", func() {
+						mdr := &v1alpha1.MachineDeletionRemediation{}
+						Eventually(func(g Gomega) {
+							g.Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+							g.Expect(mdr.Status.Drain).ToNot(BeNil())
+							g.Expect(mdr.Status.Drain.PendingPods).To(Equal(int32(1)))
+							g.Expect(mdr.Status.Drain.BlockedPods).To(ConsistOf(client.ObjectKeyFromObject(pod).String()))
+						}, "2s", "100ms").Should(Succeed())
+						verifyRemediationPhase(v1alpha1.RemediationPhaseDraining)
+						Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(workerNodeMachine), createDummyMachine())).To(Succeed())
+
+						Eventually(func() bool {
+							return errors.IsNotFound(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(workerNodeMachine), createDummyMachine()))
+						}, "10s", "100ms").Should(BeTrue())
+						Expect(errors.IsNotFound(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(pod), &v1.Pod{}))).To(BeTrue())
+
+						Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+						Expect(mdr.Status.Drain.Forced).To(BeTrue())
+						Expect(mdr.Status.Drain.CompletionTime).ToNot(BeNil())
+						verifyEvents([]expectedEvent{
+							{v1.EventTypeWarning, nodeDrainForcedReason, fmt.Sprintf(nodeDrainForcedMsg, workerNode.Name, 3*time.Second), true},
+						})
+					})
+
+					When("the remediation is deleted before the machine deletion", func() {
+						BeforeEach(func() {
+							underTest.Spec.Drain.ForceAfter = nil
+						})
+
+						It("uncordons the node", func() {
+							Eventually(func(g Gomega) {
+								mdr := &v1alpha1.MachineDeletionRemediation{}
+								g.Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+								g.Expect(mdr.Status.Drain).ToNot(BeNil())
+								g.Expect(mdr.Status.Drain.Cordoned).To(BeTrue())
+								g.Expect(mdr.Status.Drain.BlockedPods).ToNot(BeEmpty())
+							}, "10s", "100ms").Should(Succeed())
+
+							Expect(k8sClient.Delete(context.Background(), underTest)).To(Succeed())
+							Eventually(func(g Gomega) {
+								node := &v1.Node{}
+								g.Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(workerNode), node)).To(Succeed())
+								g.Expect(node.Spec.Unschedulable).To(BeFalse())
+							}, "10s", "100ms").Should(Succeed())
+							verifyMachineNotDeleted(workerNodeMachineName)
+							verifyEventEmitted(v1.EventTypeNormal, remediationDeletedReason,
+								fmt.Sprintf("remediation of node %s deleted in phase %s", workerNode.Name, v1alpha1.RemediationPhaseDraining),
+								"uncordoned node "+workerNode.Name)
+						})
+					})
+				})
+
+				When("a pod is held terminating by a finalizer", func() {
+					const podFinalizer = "test.medik8s.io/pod"
+
+					BeforeEach(func() {
+						pod.Finalizers = []string{podFinalizer}
+						Expect(k8sClient.Update(context.Background(), pod)).To(Succeed())
+						DeferCleanup(func(ctx context.Context) error {
+							stuckPod := &v1.Pod{}
+							if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(pod), stuckPod); err != nil {
+								return client.IgnoreNotFound(err)
+							}
+							stuckPod.Finalizers = nil
+							return client.IgnoreNotFound(k8sClient.Update(ctx, stuckPod))
+						})
+						// neither the kubelet of the NotReady node nor the one of a Ready node removes the finalizer
+						Expect(k8sClient.Delete(context.Background(), pod, client.GracePeriodSeconds(30))).To(Succeed())
+
+						underTest.Spec.Drain.ForceAfter = &metav1.Duration{Duration: 3 * time.Second}
+					})
+
+					It("completes the drain once it is forced", func() {
+						Eventually(func(g Gomega) {
+							mdr := &v1alpha1.MachineDeletionRemediation{}
+							g.Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+							g.Expect(mdr.Status.Drain).ToNot(BeNil())
+							g.Expect(mdr.Status.Drain.PendingPods).To(Equal(int32(1)))
+						}, "10s", "100ms").Should(Succeed())
+						verifyRemediationPhase(v1alpha1.RemediationPhaseDraining)
+						verifyMachineNotDeleted(workerNodeMachineName)
+
+						verifyMachineIsDeleted(workerNodeMachineName)
+						mdr := &v1alpha1.MachineDeletionRemediation{}
+						Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+						Expect(mdr.Status.Drain.Forced).To(BeTrue())
+						Expect(mdr.Status.Drain.CompletionTime).ToNot(BeNil())
+						Expect(mdr.Status.Drain.PendingPods).To(BeZero())
+
+						stuckPod := &v1.Pod{}
+						Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(pod), stuckPod)).To(Succeed())
+						Expect(stuckPod.DeletionGracePeriodSeconds).To(Equal(ptr.To[int64](0)))
+					})
+
+					When("the node is Ready", func() {
+						BeforeEach(func() {
+							setNodeReady(workerNode)
+						})
+
+						It("completes the drain once it is forced", func() {
+							verifyMachineIsDeleted(workerNodeMachineName)
+							mdr := &v1alpha1.MachineDeletionRemediation{}
+							Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+							Expect(mdr.Status.Drain.Forced).To(BeTrue())
+							Expect(mdr.Status.Drain.CompletionTime).ToNot(BeNil())
+							Expect(mdr.Status.Drain.PendingPods).To(BeZero())
+
+							stuckPod := &v1.Pod{}
+							Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(pod), stuckPod)).To(Succeed())
+							Expect(stuckPod.Finalizers).To(ConsistOf(podFinalizer))
+						})
+					})
+
+					When("the pod cannot be deleted", func() {
+						BeforeEach(func() {
+							cclient.onDeleteError = fmt.Errorf(mockDeleteFailMessage)
+							DeferCleanup(func() {
+								cclient.onDeleteError = nil
+							})
+						})
+
+						It("does not complete the drain", func() {
+							Eventually(func() bool {
+								return plogs.Contains(mockDeleteFailMessage)
+							}, "10s", "1s").Should(BeTrue())
+							verifyRemediationPhase(v1alpha1.RemediationPhaseDraining)
+							verifyMachineNotDeleted(workerNodeMachineName)
+
+							mdr := &v1alpha1.MachineDeletionRemediation{}
+							Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+							Expect(mdr.Status.Drain.CompletionTime).To(BeNil())
+						})
+					})
+				})
+			})
+
+			When("remediation adds a preTerminate hook to the machine", func() {
+				const machineControllerFinalizer = "test.medik8s.io/machine-controller"
+
+				verifyPreTerminateHook := func(expected bool) {
+					Eventually(func(g Gomega) {
+						machine := createDummyMachine()
+						g.Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(workerNodeMachine), machine)).To(Succeed())
+						g.Expect((&openshiftMachineBackend{}).hasPreTerminateHook(machine)).To(Equal(expected))
+					}, "10s", "100ms").Should(Succeed())
+				}
+
+				setMachineDrained := func() {
+					machine := createDummyMachine()
+					Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(workerNodeMachine), machine)).To(Succeed())
+					Expect(machine.GetDeletionTimestamp().IsZero()).To(BeFalse())
+					machine.Status.Conditions = []machinev1beta1.Condition{
+						{Type: machinev1beta1.MachineDrained, Status: v1.ConditionTrue, LastTransitionTime: metav1.Now()},
+					}
+					Expect(k8sClient.Status().Update(context.Background(), machine)).To(Succeed())
+				}
+
+				BeforeEach(func() {
+					// the Machine controller's finalizer keeps the Machine until it is terminated
+					workerNodeMachine.SetFinalizers([]string{machineControllerFinalizer})
+					Expect(k8sClient.Update(context.Background(), workerNodeMachine)).To(Succeed())
+					DeferCleanup(func() {
+						machine := createDummyMachine()
+						if err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(workerNodeMachine), machine); err == nil {
+							machine.SetFinalizers(nil)
+							Expect(k8sClient.Update(context.Background(), machine)).To(Succeed())
+						}
+					})
+
+					underTest = createRemediationOwnedByNHC(workerNode.Name)
+					underTest.Spec.PreTerminateHook = &v1alpha1.PreTerminateHookSpec{}
+				})
+
+				It("removes the hook once the machine is drained", func() {
+					verifyPreTerminateHook(true)
+					mdr := &v1alpha1.MachineDeletionRemediation{}
+					Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+					Expect(mdr.Finalizers).To(ContainElement(CleanupFinalizer))
+
+					setMachineDrained()
+					verifyPreTerminateHook(false)
+
+					Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+					Expect(mdr.Status.PreTerminateHook).ToNot(BeNil())
+					Expect(mdr.Status.PreTerminateHook.AddedTime).ToNot(BeNil())
+					Expect(mdr.Status.PreTerminateHook.MachineDrainedTime).ToNot(BeNil())
+					Expect(mdr.Status.PreTerminateHook.RemovedTime).ToNot(BeNil())
+					verifyEvents([]expectedEvent{
+						{v1.EventTypeNormal, preTerminateHookAddedReason, fmt.Sprintf(preTerminateHookAddedMsg, workerNodeMachineName), true},
+						{v1.EventTypeNormal, "RemediationStarted", "Remediation started", true},
+						{v1.EventTypeNormal, machineDrainedReason, fmt.Sprintf(machineDrainedMsg, workerNodeMachineName), true},
+						{v1.EventTypeNormal, preTerminateHookRemovedReason, fmt.Sprintf(preTerminateHookRemovedMsg, workerNodeMachineName), true},
+					})
+				})
+
+				When("the hook has to be released", func() {
+					BeforeEach(func() {
+						underTest.Spec.PreTerminateHook.ReleaseTimeout = &metav1.Duration{Duration: time.Hour}
+					})
+
+					It("keeps the hook until the release annotation is set", func() {
+						verifyPreTerminateHook(true)
+						setMachineDrained()
+						Eventually(func(g Gomega) {
+							mdr := &v1alpha1.MachineDeletionRemediation{}
+							g.Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+							g.Expect(mdr.Status.PreTerminateHook.MachineDrainedTime).ToNot(BeNil())
+						}, "10s", "100ms").Should(Succeed())
+						Consistently(func() bool {
+							machine := createDummyMachine()
+							Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(workerNodeMachine), machine)).To(Succeed())
+							return (&openshiftMachineBackend{}).hasPreTerminateHook(machine)
+						}, "2s", "100ms").Should(BeTrue())
+
+						mdr := &v1alpha1.MachineDeletionRemediation{}
+						Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+						mdr.Annotations = map[string]string{ReleasePreTerminateHookAnnotation: ""}
+						Expect(k8sClient.Update(context.Background(), mdr)).To(Succeed())
+						verifyPreTerminateHook(false)
+					})
+
+					It("removes the hook when NHC stops the remediation", func() {
+						verifyPreTerminateHook(true)
+						setStopRemediationAnnotation()
+						verifyPreTerminateHook(false)
+						verifyConditionsMatch([]expectedCondition{
+							{commonconditions.ProcessingType, metav1.ConditionFalse, remediationTimedOutByNhc},
+							{commonconditions.SucceededType, metav1.ConditionFalse, remediationTimedOutByNhc}})
+						verifyEventEmitted(v1.EventTypeNormal, preTerminateHookRemovedReason, fmt.Sprintf(preTerminateHookRemovedMsg, workerNodeMachineName))
+					})
+
+					It("removes the hook when the remediation is deleted", func() {
+						verifyPreTerminateHook(true)
+						Expect(k8sClient.Delete(context.Background(), underTest)).To(Succeed())
+						verifyPreTerminateHook(false)
+						verifyEventEmitted(v1.EventTypeNormal, remediationDeletedReason,
+							fmt.Sprintf("remediation of node %s deleted in phase %s", workerNode.Name, v1alpha1.RemediationPhaseMachineDeletionRequested),
+							"removed the preTerminate hook from machine "+workerNodeMachineName)
+					})
+				})
+
+				When("the machine is not drained within the release timeout", func() {
+					BeforeEach(func() {
+						underTest.Spec.PreTerminateHook.ReleaseTimeout = &metav1.Duration{Duration: 3 * time.Second}
+					})
+
+					It("removes the hook once the release timeout expires", func() {
+						verifyPreTerminateHook(true)
+						verifyPreTerminateHook(false)
+
+						mdr := &v1alpha1.MachineDeletionRemediation{}
+						Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+						Expect(mdr.Status.PreTerminateHook.MachineDrainedTime).To(BeNil())
+						Expect(mdr.Status.PreTerminateHook.RemovedTime).ToNot(BeNil())
+						Eventually(func() bool {
+							return plogs.Contains(fmt.Sprintf(preTerminateHookReleaseTimeoutMsg, 3*time.Second))
+						}, "10s", "1s").Should(BeTrue())
+					})
+				})
+			})
+
+			When("worker node is not restored within the node restoration timeout", func() {
+				BeforeEach(func() {
+					underTest = createRemediationOwnedByNHC(workerNode.Name)
+					underTest.Spec.NodeRestorationTimeout = &metav1.Duration{Duration: 2 * time.Second}
+				})
+
+				It("fails the remediation", func() {
+					verifyMachineIsDeleted(workerNodeMachineName)
+					verifyConditionsMatch([]expectedCondition{
+						{commonconditions.ProcessingType, metav1.ConditionFalse, remediationNodeRestorationTimedOut},
+						{commonconditions.SucceededType, metav1.ConditionFalse, remediationNodeRestorationTimedOut}})
+
+					mdr := &v1alpha1.MachineDeletionRemediation{}
+					Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+					Expect(mdr.Status.MachineDeletionRequestedTime).ToNot(BeNil())
+					Expect(meta.FindStatusCondition(mdr.Status.Conditions, commonconditions.SucceededType).Message).To(ContainSubstring("waited"))
+					Eventually(func() bool {
+						return plogs.Contains("the nodes were not restored within the node restoration timeout")
+					}, "10s", "1s").Should(BeTrue())
+				})
+			})
+
+			When("worker node's replacement machine exists but its node is not ready", func() {
+				BeforeEach(func() {
+					underTest = createRemediationOwnedByNHC(workerNode.Name)
+				})
+
+				It("waits for the replacement node to be ready", func() {
+					verifyMachineIsDeleted(workerNodeMachineName)
+
+					replacementName := workerNodeMachineName + "-replacement"
+					replacement := createMachineWithOwner(replacementName, machineSet)
+					Expect(k8sClient.Create(context.Background(), replacement)).To(Succeed())
+					DeferCleanup(k8sClient.Delete, replacement)
+
+					workerNode.Annotations[machineAnnotationOpenshift] = fmt.Sprintf("%s/%s", machineNamespace, replacementName)
+					Expect(k8sClient.Update(context.Background(), workerNode)).To(Succeed())
+
+					Eventually(func(g Gomega) {
+						mdr := &v1alpha1.MachineDeletionRemediation{}
+						g.Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+						g.Expect(mdr.Status.ReplacementMachineName).To(Equal(replacementName))
+						g.Expect(mdr.Status.ReplacementNodeName).To(Equal(workerNode.Name))
+					}, "30s", "1s").Should(Succeed())
+					verifyConditionsMatch([]expectedCondition{
+						{commonconditions.ProcessingType, metav1.ConditionTrue, remediationStarted},
+						{commonconditions.SucceededType, metav1.ConditionUnknown, remediationStarted}})
+
+					setNodeReady(workerNode)
+					verifyConditionsMatch([]expectedCondition{
+						{commonconditions.ProcessingType, metav1.ConditionFalse, remediationFinishedMachineDeleted},
+						{commonconditions.SucceededType, metav1.ConditionTrue, remediationFinishedMachineDeleted}})
+				})
+			})
+
+			When("creating a resource in baremetal provider", func() {
+				BeforeEach(func() {
+					setMachineProviderID(workerNodeMachine, "baremetal:///dummy-provider-ID")
+					underTest = createRemediationOwnedByNHC(workerNode.Name)
+
+				})
+				It("sets PermanentNodeDeletionExpected condition to false", func() {
+					verifyConditionMatches(commonconditions.PermanentNodeDeletionExpectedType, metav1.ConditionFalse, v1alpha1.MachineDeletionOnBareMetalProviderReason)
+					verifyEvents([]expectedEvent{
+						{v1.EventTypeNormal,
+							"PermanentNodeDeletionExpected",
+							"Machine will be deleted and the unhealthy node replaced. This is a BareMetal cluster provider: the new node is NOT expected to have a new name",
+							true},
+					})
+				})
+			})
+
+			When("worker node's machine is provisioned on a BareMetalHost", func() {
+				var host *unstructured.Unstructured
+
+				BeforeEach(func() {
+					host = createBareMetalHost("worker-host", "provisioned")
+					Eventually(func(g Gomega) {
+						g.Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(workerNodeMachine), workerNodeMachine)).To(Succeed())
+						workerNodeMachine.SetAnnotations(map[string]string{bareMetalHostAnnotation: machineNamespace + "/" + host.GetName()})
+						g.Expect(k8sClient.Update(context.Background(), workerNodeMachine)).To(Succeed())
+					}, "10s", "1s").Should(Succeed())
+					underTest = createRemediationOwnedByNHC(workerNode.Name)
+					underTest.Spec.PollInterval = &metav1.Duration{Duration: time.Second}
+				})
+
+				It("follows the host through deprovisioning and reprovisioning", func() {
+					verifyMachineIsDeleted(workerNodeMachineName)
+					verifyBareMetalHostState("provisioned")
+
+					updateBareMetalHostStatus(host, "deprovisioning", "OK", "", "")
+					verifyBareMetalHostState("deprovisioning")
+					verifyEventEmitted(v1.EventTypeNormal, bareMetalHostStateChangedReason, host.GetName(), "deprovisioning")
+
+					updateBareMetalHostStatus(host, "available", "OK", "", "")
+					verifyBareMetalHostState("available")
+					updateBareMetalHostStatus(host, "provisioned", "OK", "", "")
+					verifyBareMetalHostState("provisioned")
+
+					mdr := &v1alpha1.MachineDeletionRemediation{}
+					Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+					Expect(mdr.Status.BareMetalHost.Name).To(Equal(host.GetName()))
+					Expect(mdr.Status.BareMetalHost.Namespace).To(Equal(machineNamespace))
+					Expect(mdr.Status.BareMetalHost.DeprovisionedTime).ToNot(BeNil())
+					Expect(mdr.Status.BareMetalHost.ReprovisionedTime).ToNot(BeNil())
+					verifyConditionsMatch([]expectedCondition{
+						{commonconditions.ProcessingType, metav1.ConditionTrue, remediationStarted},
+						{commonconditions.SucceededType, metav1.ConditionUnknown, remediationStarted}})
+				})
+
+				It("fails the remediation when the host enters an error state", func() {
+					verifyMachineIsDeleted(workerNodeMachineName)
+					verifyBareMetalHostState("provisioned")
+
+					updateBareMetalHostStatus(host, "deprovisioning", "error", "provisioned registration error", "BMC unreachable")
+					verifyConditionsMatch([]expectedCondition{
+						{commonconditions.ProcessingType, metav1.ConditionFalse, remediationBareMetalHostError},
+						{commonconditions.SucceededType, metav1.ConditionFalse, remediationBareMetalHostError}})
+					verifyRemediationPhase(v1alpha1.RemediationPhaseFailed)
+					verifyEventEmitted(v1.EventTypeWarning, string(remediationBareMetalHostError), host.GetName(), "BMC unreachable")
+
+					mdr := &v1alpha1.MachineDeletionRemediation{}
+					Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+					Expect(mdr.Status.BareMetalHost.OperationalStatus).To(Equal("error"))
+					Expect(mdr.Status.BareMetalHost.ErrorType).To(Equal("provisioned registration error"))
+					Expect(meta.FindStatusCondition(mdr.Status.Conditions, commonconditions.SucceededType).Message).To(ContainSubstring("BMC unreachable"))
+				})
+			})
+
+			When("worker node's machine deletion is stuck", func() {
+				const holdFinalizer = "test.medik8s.io/hold"
+				var pod *v1.Pod
+
+				BeforeEach(func() {
+					Eventually(func(g Gomega) {
+						g.Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(workerNodeMachine), workerNodeMachine)).To(Succeed())
+						workerNodeMachine.SetFinalizers([]string{holdFinalizer})
+						workerNodeMachine.Spec.LifecycleHooks.PreDrain = []machinev1beta1.LifecycleHook{{Name: "test-hook", Owner: "test"}}
+						g.Expect(k8sClient.Update(context.Background(), workerNodeMachine)).To(Succeed())
+					}, "10s", "1s").Should(Succeed())
+					DeferCleanup(func() {
+						Eventually(func(g Gomega) {
+							machine := &machinev1beta1.Machine{}
+							if err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(workerNodeMachine), machine); errors.IsNotFound(err) {
+								return
+							}
+							machine.SetFinalizers(nil)
+							g.Expect(k8sClient.Update(context.Background(), machine)).To(Succeed())
+						}, "10s", "1s").Should(Succeed())
+						verifyMachineIsDeleted(workerNodeMachineName)
+					})
+
+					// running pods are blocked by a PodDisruptionBudget allowing no disruption
+					pod = createPodOnNode("stuck-deletion-pod", workerNode)
+					pod.Labels = map[string]string{"app": "stuck-deletion"}
+					Expect(k8sClient.Create(context.Background(), pod)).To(Succeed())
+					DeferCleanup(deletePodNow, pod)
+					pod.Status.Phase = v1.PodRunning
+					Expect(k8sClient.Status().Update(context.Background(), pod)).To(Succeed())
+					pdb := &policyv1.PodDisruptionBudget{
+						ObjectMeta: metav1.ObjectMeta{Name: "stuck-deletion-pdb", Namespace: pod.Namespace},
+						Spec: policyv1.PodDisruptionBudgetSpec{
+							MinAvailable: ptr.To(intstr.FromInt32(1)),
+							Selector:     &metav1.LabelSelector{MatchLabels: pod.Labels},
+						},
+					}
+					Expect(k8sClient.Create(context.Background(), pdb)).To(Succeed())
+					DeferCleanup(k8sClient.Delete, pdb)
+
+					underTest = createRemediationOwnedByNHC(workerNode.Name)
+					underTest.Spec.PollInterval = &metav1.Duration{Duration: time.Second}
+					underTest.Spec.StuckDeletion = &v1alpha1.StuckDeletionSpec{
+						Timeout: metav1.Duration{Duration: 2 * time.Second},
+						Escalations: []v1alpha1.DeletionEscalation{
+							v1alpha1.DeletionEscalationExcludeNodeDraining, v1alpha1.DeletionEscalationRemovePreTerminateHook,
+						},
+					}
+				})
+
+				It("reports what holds the machine and applies the escalations one timeout apart", func() {
+					verifyConditionMatches(v1alpha1.MachineDeletionStuckConditionType, metav1.ConditionTrue, v1alpha1.MachineDeletionTimedOutReason)
+					verifyRemediationPhase(v1alpha1.RemediationPhaseMachineDeletionRequested)
+					verifyEventEmitted(v1.EventTypeWarning, v1alpha1.MachineDeletionStuckConditionType, workerNodeMachineName, holdFinalizer)
+					verifyEventEmitted(v1.EventTypeNormal, machineDeletionEscalatedReason, string(v1alpha1.DeletionEscalationExcludeNodeDraining))
+
+					mdr := &v1alpha1.MachineDeletionRemediation{}
+					Eventually(func(g Gomega) {
+						g.Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+						g.Expect(mdr.Status.StuckDeletion).ToNot(BeNil())
+						g.Expect(mdr.Status.StuckDeletion.Escalations).To(ConsistOf(v1alpha1.DeletionEscalationExcludeNodeDraining))
+					}, "10s", "100ms").Should(Succeed())
+					Expect(mdr.Status.StuckDeletion.DetectedTime).ToNot(BeNil())
+					Expect(mdr.Status.StuckDeletion.LastEscalationTime).ToNot(BeNil())
+					firstEscalationTime := mdr.Status.StuckDeletion.LastEscalationTime.Time
+					Expect(mdr.Status.StuckDeletion.Finalizers).To(ConsistOf(holdFinalizer))
+					Expect(mdr.Status.StuckDeletion.LifecycleHooks).To(ConsistOf("preDrain/test-hook"))
+					Expect(mdr.Status.StuckDeletion.DrainBlockers).To(ConsistOf(client.ObjectKeyFromObject(pod).String()))
+					Expect(meta.FindStatusCondition(mdr.Status.Conditions, v1alpha1.MachineDeletionStuckConditionType).Message).To(
+						ContainSubstring("pods blocking the drain: " + client.ObjectKeyFromObject(pod).String()))
+
+					machine := &machinev1beta1.Machine{}
+					Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(workerNodeMachine), machine)).To(Succeed())
+					Expect(machine.Annotations).To(HaveKey(openshiftExcludeNodeDrainingAnnotation))
+
+					// the next escalation waits for another stuck deletion timeout
+					Eventually(func(g Gomega) {
+						g.Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+						g.Expect(mdr.Status.StuckDeletion.Escalations).To(Equal([]v1alpha1.DeletionEscalation{
+							v1alpha1.DeletionEscalationExcludeNodeDraining, v1alpha1.DeletionEscalationRemovePreTerminateHook,
+						}))
+					}, "10s", "1s").Should(Succeed())
+					Expect(mdr.Status.StuckDeletion.LastEscalationTime.Time.Sub(firstEscalationTime)).To(BeNumerically(">=", time.Second))
+				})
+
+				It("completes the stuck deletion once the machine is gone", func() {
+					verifyConditionMatches(v1alpha1.MachineDeletionStuckConditionType, metav1.ConditionTrue, v1alpha1.MachineDeletionTimedOutReason)
+
+					Eventually(func(g Gomega) {
+						machine := &machinev1beta1.Machine{}
+						g.Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(workerNodeMachine), machine)).To(Succeed())
+						machine.SetFinalizers(nil)
+						g.Expect(k8sClient.Update(context.Background(), machine)).To(Succeed())
+					}, "10s", "1s").Should(Succeed())
+					verifyMachineIsDeleted(workerNodeMachineName)
+					verifyConditionMatches(v1alpha1.MachineDeletionStuckConditionType, metav1.ConditionFalse, v1alpha1.MachineDeletionCompletedReason)
+				})
+			})
+
+			When("worker node's machine deletion is not stuck", func() {
+				BeforeEach(func() {
+					underTest = createRemediationOwnedByNHC(workerNode.Name)
+					underTest.Spec.StuckDeletion = &v1alpha1.StuckDeletionSpec{Timeout: metav1.Duration{Duration: time.Minute}}
+				})
+
+				It("does not set the MachineDeletionStuck condition", func() {
+					verifyMachineIsDeleted(workerNodeMachineName)
+					verifyConditionUnset(v1alpha1.MachineDeletionStuckConditionType)
+				})
+			})
+
+			When("remediation associated machine has no owner ref and a fallback remediation template is set", func() {
+				var fallback *unstructured.Unstructured
+
+				BeforeEach(func() {
+					template := createRebootRemediationTemplate("reboot-template")
+					underTest = createRemediationOwnedByNHC(masterNode.Name)
+					underTest.Spec.PollInterval = &metav1.Duration{Duration: time.Second}
+					underTest.Spec.FallbackRemediationTemplate = &v1alpha1.FallbackRemediationTemplateReference{
+						APIVersion: template.GetAPIVersion(),
+						Kind:       template.GetKind(),
+						Name:       template.GetName(),
+					}
+
+					// there is no garbage collector in the test environment
+					fallback = &unstructured.Unstructured{}
+					fallback.SetAPIVersion(template.GetAPIVersion())
+					fallback.SetKind("RebootRemediation")
+					fallback.SetName(masterNode.Name)
+					fallback.SetNamespace(underTest.Namespace)
+					DeferCleanup(deleteIgnoreNotFound(), fallback)
+				})
+
+				JustBeforeEach(func() {
+					verifyConditionsMatch([]expectedCondition{
+						{commonconditions.ProcessingType, metav1.ConditionTrue, remediationFallbackStarted},
+						{commonconditions.SucceededType, metav1.ConditionUnknown, remediationFallbackStarted}})
+					verifyRemediationPhase(v1alpha1.RemediationPhaseFallbackRemediation)
+					verifyEventEmitted(v1.EventTypeNormal, string(remediationFallbackStarted), noControllerOwnerErrorMsg, "RebootRemediation")
+					Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(fallback), fallback)).To(Succeed())
+				})
+
+				It("creates the fallback remediation from the template for the same node", func() {
+					rebootMethod, _, _ := unstructured.NestedString(fallback.Object, "spec", "rebootMethod")
+					Expect(rebootMethod).To(Equal("Power"))
+					Expect(fallback.GetAnnotations()).To(HaveKeyWithValue(commonannotations.NodeNameAnnotation, masterNode.Name))
+					controller := metav1.GetControllerOf(fallback)
+					Expect(controller).ToNot(BeNil())
+					Expect(controller.Kind).To(Equal("MachineDeletionRemediation"))
+					Expect(controller.Name).To(Equal(underTest.Name))
+
+					mdr := &v1alpha1.MachineDeletionRemediation{}
+					Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+					Expect(mdr.Status.FallbackRemediation).ToNot(BeNil())
+					Expect(mdr.Status.FallbackRemediation.Remediation.Kind).To(Equal("RebootRemediation"))
+					Expect(mdr.Status.FallbackRemediation.Remediation.Name).To(Equal(masterNode.Name))
+					Expect(mdr.Status.FallbackRemediation.Reason).To(Equal(string(remediationSkippedNoControllerOwner)))
+					Expect(mdr.Status.FallbackRemediation.CreatedTime).ToNot(BeNil())
+					verifyMachineNotDeleted(masterNodeMachineName)
+				})
+
+				It("succeeds once the fallback remediation succeeds", func() {
+					setRemediationSucceededCondition(fallback, metav1.ConditionTrue, "node rebooted")
+					verifyConditionsMatch([]expectedCondition{
+						{commonconditions.ProcessingType, metav1.ConditionFalse, remediationFallbackSucceeded},
+						{commonconditions.SucceededType, metav1.ConditionTrue, remediationFallbackSucceeded}})
+					verifyRemediationPhase(v1alpha1.RemediationPhaseSucceeded)
+					verifyEventEmitted(v1.EventTypeNormal, string(remediationFallbackSucceeded), "RebootRemediation", masterNode.Name)
+
+					mdr := &v1alpha1.MachineDeletionRemediation{}
+					Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+					Expect(mdr.Status.FallbackRemediation.CompletedTime).ToNot(BeNil())
+				})
+
+				It("fails once the fallback remediation fails", func() {
+					setRemediationSucceededCondition(fallback, metav1.ConditionFalse, "reboot failed")
+					verifyConditionsMatch([]expectedCondition{
+						{commonconditions.ProcessingType, metav1.ConditionFalse, remediationFallbackFailed},
+						{commonconditions.SucceededType, metav1.ConditionFalse, remediationFallbackFailed}})
+					verifyRemediationPhase(v1alpha1.RemediationPhaseFailed)
+					verifyEventEmitted(v1.EventTypeWarning, string(remediationFallbackFailed), "RebootRemediation", "reboot failed")
+				})
+
+				It("fails if the fallback remediation is deleted before completing", func() {
+					Expect(k8sClient.Delete(context.Background(), fallback)).To(Succeed())
+					verifyConditionsMatch([]expectedCondition{
+						{commonconditions.ProcessingType, metav1.ConditionFalse, remediationFallbackFailed},
+						{commonconditions.SucceededType, metav1.ConditionFalse, remediationFallbackFailed}})
+					verifyEventEmitted(v1.EventTypeWarning, string(remediationFallbackFailed), "was deleted before completing")
+				})
+
+				It("stops the fallback remediation when NHC stops the remediation", func() {
+					Eventually(func() error {
+						mdr := &v1alpha1.MachineDeletionRemediation{}
+						if err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr); err != nil {
+							return err
+						}
+						mdr.Annotations = map[string]string{commonannotations.NhcTimedOut: time.Now().Format(time.RFC3339)}
+						return k8sClient.Update(context.Background(), mdr)
+					}, "10s", "100ms").Should(Succeed())
+
+					verifyConditionsMatch([]expectedCondition{
+						{commonconditions.ProcessingType, metav1.ConditionFalse, remediationTimedOutByNhc},
+						{commonconditions.SucceededType, metav1.ConditionFalse, remediationTimedOutByNhc}})
+					Eventually(func(g Gomega) {
+						g.Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(fallback), fallback)).To(Succeed())
+						g.Expect(fallback.GetAnnotations()).To(HaveKey(commonannotations.NhcTimedOut))
+					}, "10s", "1s").Should(Succeed())
+				})
+			})
+
+			When("the operator is not allowed to create the fallback remediation", func() {
+				BeforeEach(func() {
+					template := createRebootRemediationTemplate("reboot-template")
+					cclient.onCreateError = errors.NewForbidden(schema.GroupResource{Group: "remediation.example.com", Resource: "rebootremediations"}, masterNode.Name, fmt.Errorf("RBAC denied"))
+					DeferCleanup(func() {
+						cclient.onCreateError = nil
+					})
+					underTest = createRemediationOwnedByNHC(masterNode.Name)
+					underTest.Spec.FallbackRemediationTemplate = &v1alpha1.FallbackRemediationTemplateReference{
+						APIVersion: template.GetAPIVersion(),
+						Kind:       template.GetKind(),
+						Name:       template.GetName(),
+					}
+				})
+
+				It("fails without retrying", func() {
+					verifyConditionsMatch([]expectedCondition{
+						{commonconditions.ProcessingType, metav1.ConditionFalse, remediationFallbackFailed},
+						{commonconditions.SucceededType, metav1.ConditionFalse, remediationFallbackFailed}})
+					verifyEventEmitted(v1.EventTypeWarning, string(remediationFallbackFailed), noControllerOwnerErrorMsg, "not allowed to create RebootRemediation")
+
+					mdr := &v1alpha1.MachineDeletionRemediation{}
+					Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+					Expect(mdr.Status.FallbackRemediation).To(BeNil())
 				})
 			})
 
-			When("creating a resource in baremetal provider", func() {
+			When("the fallback remediation template does not exist", func() {
 				BeforeEach(func() {
-					setMachineProviderID(workerNodeMachine, "baremetal:///dummy-provider-ID")
-					underTest = createRemediationOwnedByNHC(workerNode.Name)
-
+					underTest = createRemediationOwnedByNHC(masterNode.Name)
+					underTest.Spec.FallbackRemediationTemplate = &v1alpha1.FallbackRemediationTemplateReference{
+						APIVersion: "remediation.example.com/v1alpha1",
+						Kind:       "RebootRemediationTemplate",
+						Name:       "missing-template",
+					}
 				})
-				It("sets PermanentNodeDeletionExpected condition to false", func() {
-					verifyConditionMatches(commonconditions.PermanentNodeDeletionExpectedType, metav1.ConditionFalse, v1alpha1.MachineDeletionOnBareMetalProviderReason)
-					verifyEvents([]expectedEvent{
-						{v1.EventTypeNormal,
-							"PermanentNodeDeletionExpected",
-							"Machine will be deleted and the unhealthy node replaced. This is a BareMetal cluster provider: the new node is NOT expected to have a new name",
-							true},
-					})
+
+				It("fails without creating the fallback remediation", func() {
+					verifyConditionsMatch([]expectedCondition{
+						{commonconditions.ProcessingType, metav1.ConditionFalse, remediationFallbackFailed},
+						{commonconditions.SucceededType, metav1.ConditionFalse, remediationFallbackFailed}})
+					verifyEventEmitted(v1.EventTypeWarning, string(remediationFallbackFailed), noControllerOwnerErrorMsg, "missing-template", "not found")
+
+					mdr := &v1alpha1.MachineDeletionRemediation{}
+					Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+					Expect(mdr.Status.FallbackRemediation).To(BeNil())
 				})
 			})
 
@@ -352,6 +1576,36 @@ var _ = Describe("Machine Deletion Remediation CR", func() {
 					})
 				})
 			})
+
+			When("creating a resource without providerID in a baremetal providerSpec", func() {
+				BeforeEach(func() {
+					setMachineProviderSpecKind(workerNodeMachine, "BareMetalMachineProviderSpec")
+					underTest = createRemediationOwnedByNHC(workerNode.Name)
+				})
+				It("sets PermanentNodeDeletionExpected condition to false", func() {
+					verifyConditionMatches(commonconditions.PermanentNodeDeletionExpectedType, metav1.ConditionFalse, v1alpha1.MachineDeletionOnBareMetalProviderReason)
+				})
+			})
+
+			When("creating a resource without providerID in a cloud platform Infrastructure", func() {
+				BeforeEach(func() {
+					createInfrastructure(configv1.AWSPlatformType)
+					underTest = createRemediationOwnedByNHC(workerNode.Name)
+				})
+				It("sets PermanentNodeDeletionExpected condition to true", func() {
+					verifyConditionMatches(commonconditions.PermanentNodeDeletionExpectedType, metav1.ConditionTrue, v1alpha1.MachineDeletionOnCloudProviderReason)
+				})
+			})
+
+			When("creating a resource without providerID in a bare metal platform Infrastructure", func() {
+				BeforeEach(func() {
+					createInfrastructure(configv1.BareMetalPlatformType)
+					underTest = createRemediationOwnedByNHC(workerNode.Name)
+				})
+				It("sets PermanentNodeDeletionExpected condition to false", func() {
+					verifyConditionMatches(commonconditions.PermanentNodeDeletionExpectedType, metav1.ConditionFalse, v1alpha1.MachineDeletionOnBareMetalProviderReason)
+				})
+			})
 		})
 
 		Context("Rainy (Error) Flows", func() {
@@ -492,6 +1746,29 @@ var _ = Describe("Machine Deletion Remediation CR", func() {
 				})
 			})
 
+			When("worker node's machine owner is deleted while waiting for the node replacement", func() {
+				BeforeEach(func() {
+					underTest = createRemediationOwnedByNHC(workerNode.Name)
+					underTest.Spec.PollInterval = &metav1.Duration{Duration: time.Second}
+				})
+
+				It("fails the remediation", func() {
+					verifyMachineIsDeleted(workerNodeMachineName)
+					verifyRemediationPhase(v1alpha1.RemediationPhaseWaitingForReplacement)
+
+					Expect(k8sClient.Delete(context.Background(), machineSet)).To(Succeed())
+					// the MachineSet is deleted again when the test completes
+					DeferCleanup(func() {
+						Expect(k8sClient.Create(context.Background(), createMachineSet(machineSetName, 1))).To(Succeed())
+					})
+
+					verifyConditionsMatch([]expectedCondition{
+						{commonconditions.ProcessingType, metav1.ConditionFalse, remediationFailed},
+						{commonconditions.SucceededType, metav1.ConditionFalse, remediationFailed}})
+					verifyEventEmitted(v1.EventTypeWarning, string(remediationFailed), unrecoverableError.Error(), machineSetName)
+				})
+			})
+
 			When("machine associated to worker node fails deletion", func() {
 				BeforeEach(func() {
 					cclient.onDeleteError = fmt.Errorf(mockDeleteFailMessage)
@@ -568,6 +1845,7 @@ var _ = Describe("Machine Deletion Remediation CR", func() {
 
 					workerNode.Annotations[machineAnnotationOpenshift] = fmt.Sprintf("%s/%s", machineNamespace, machineReplacementName)
 					Expect(k8sClient.Update(context.Background(), workerNode)).To(Succeed())
+					setNodeReady(workerNode)
 
 					// Now the remediation should be completed
 					verifyConditionsMatch([]expectedCondition{
@@ -606,6 +1884,284 @@ var _ = Describe("Machine Deletion Remediation CR", func() {
 					})
 				})
 			})
+
+			When("Machine is already remediated by a NHC created CR", func() {
+				var nhcRemediation *v1alpha1.MachineDeletionRemediation
+
+				BeforeEach(func() {
+					nhcRemediation = createRemediationOwnedByNHC(workerNodeName)
+					Expect(k8sClient.Create(context.Background(), nhcRemediation)).To(Succeed())
+					DeferCleanup(deleteRemediationAndWait, nhcRemediation)
+					Eventually(func(g Gomega) {
+						g.Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nhcRemediation), nhcRemediation)).To(Succeed())
+						g.Expect(nhcRemediation.Status.MachineDeletionRequestedTime).ToNot(BeNil())
+					}, "10s", "100ms").Should(Succeed())
+
+					underTest = createRemediationOwnedByMHC("remediation-name", workerNodeMachine)
+				})
+
+				It("MHC remediation is skipped", func() {
+					verifyMachineIsDeleted(workerNodeMachineName)
+					verifyConditionsMatch([]expectedCondition{
+						{commonconditions.ProcessingType, metav1.ConditionFalse, remediationSkippedDuplicate},
+						{commonconditions.SucceededType, metav1.ConditionFalse, remediationSkippedDuplicate}})
+					msg := fmt.Sprintf(duplicateRemediationErrorMsg, client.ObjectKeyFromObject(nhcRemediation))
+					verifyEvents([]expectedEvent{
+						{v1.EventTypeWarning, "RemediationSkippedDuplicate", msg, true},
+					})
+
+					mdr := &v1alpha1.MachineDeletionRemediation{}
+					Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+					Expect(mdr.Status.MachineDeletionRequestedTime).To(BeNil())
+					Expect(meta.FindStatusCondition(mdr.Status.Conditions, commonconditions.SucceededType).Message).To(Equal(msg))
+				})
+			})
+		})
+	})
+
+	Context("Cluster API support", func() {
+		var (
+			capiMachineSet, capiMachineDeployment, capiKcp *unstructured.Unstructured
+			capiWorkerMachine, capiCpMachine               *unstructured.Unstructured
+			capiWorkerNode, capiCpNode                     *v1.Node
+		)
+
+		BeforeEach(func() {
+			plogs.Clear()
+			clearEvents()
+
+			capiMachineSet = createCapiOwner(capiMachineSetKind, capiMachineSetName, 1)
+			capiKcp = createCapiOwner(capiKcpKind, capiKcpName, 3)
+			capiWorkerMachine = createCapiMachineWithOwner(capiWorkerMachineName, capiMachineSet)
+			capiCpMachine = createCapiMachineWithOwner(capiCpMachineName, capiKcp)
+			capiWorkerNode = createNodeWithCapiMachine(capiWorkerNodeName, capiWorkerMachine)
+			capiCpNode = createNodeWithCapiMachine(capiCpNodeName, capiCpMachine)
+
+			for _, obj := range []client.Object{capiMachineSet, capiKcp, capiWorkerNode, capiCpNode} {
+				Expect(k8sClient.Create(context.Background(), obj)).To(Succeed())
+				DeferCleanup(k8sClient.Delete, obj)
+			}
+			for _, obj := range []client.Object{capiWorkerMachine, capiCpMachine} {
+				Expect(k8sClient.Create(context.Background(), obj)).To(Succeed())
+				// The following Machines are expected to be deleted in some tests
+				// so do not error if they are not found
+				DeferCleanup(deleteIgnoreNotFound(), obj)
+			}
+		})
+
+		JustBeforeEach(func() {
+			Expect(k8sClient.Create(context.Background(), underTest)).To(Succeed())
+			DeferCleanup(deleteRemediationAndWait, underTest)
+		})
+
+		When("worker node's machine is owned by a MachineSet", func() {
+			BeforeEach(func() {
+				underTest = createRemediationOwnedByNHC(capiWorkerNodeName)
+			})
+
+			It("worker machine is deleted and the remediation completes once the node is replaced", func() {
+				verifyCapiMachineIsDeleted(capiWorkerMachineName)
+				verifyCapiMachineNotDeleted(capiCpMachineName)
+				verifyRemediationStatusMachine(capiGroupVersion.String(), capiWorkerMachineName, capiMachineSetKind, capiMachineSetName)
+
+				verifyConditionsMatch([]expectedCondition{
+					{commonconditions.ProcessingType, metav1.ConditionTrue, remediationStarted},
+					{commonconditions.SucceededType, metav1.ConditionUnknown, remediationStarted}})
+
+				replacement := createCapiMachineWithOwner(capiWorkerMachineName+"-replacement", capiMachineSet)
+				Expect(k8sClient.Create(context.Background(), replacement)).To(Succeed())
+				DeferCleanup(k8sClient.Delete, replacement)
+				updateNodeCapiMachine(capiWorkerNode, replacement)
+				setNodeReady(capiWorkerNode)
+
+				verifyConditionsMatch([]expectedCondition{
+					{commonconditions.ProcessingType, metav1.ConditionFalse, remediationFinishedMachineDeleted},
+					{commonconditions.SucceededType, metav1.ConditionTrue, remediationFinishedMachineDeleted}})
+			})
+		})
+
+		When("an OpenShift Machine with the same name and namespace is being remediated", func() {
+			var openshiftRemediation *v1alpha1.MachineDeletionRemediation
+
+			BeforeEach(func() {
+				openshiftMachineSet := createMachineSet("openshift-machine-set", 1)
+				openshiftMachine := createMachineWithOwner(capiWorkerMachineName, openshiftMachineSet)
+				openshiftNode := createNodeWithMachine("openshift-node", openshiftMachine)
+				for _, obj := range []client.Object{openshiftMachineSet, openshiftNode} {
+					Expect(k8sClient.Create(context.Background(), obj)).To(Succeed())
+					DeferCleanup(k8sClient.Delete, obj)
+				}
+				Expect(k8sClient.Create(context.Background(), openshiftMachine)).To(Succeed())
+				DeferCleanup(deleteIgnoreNotFound(), openshiftMachine)
+
+				openshiftRemediation = createRemediationOwnedByNHC(openshiftNode.Name)
+				Expect(k8sClient.Create(context.Background(), openshiftRemediation)).To(Succeed())
+				DeferCleanup(deleteRemediationAndWait, openshiftRemediation)
+				Eventually(func(g Gomega) {
+					g.Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(openshiftRemediation), openshiftRemediation)).To(Succeed())
+					g.Expect(openshiftRemediation.Status.MachineDeletionRequestedTime).ToNot(BeNil())
+				}, "10s", "100ms").Should(Succeed())
+
+				underTest = createRemediationOwnedByNHC(capiWorkerNodeName)
+			})
+
+			It("the Cluster API Machine is not considered as already remediated", func() {
+				verifyCapiMachineIsDeleted(capiWorkerMachineName)
+				verifyRemediationStatusMachine(capiGroupVersion.String(), capiWorkerMachineName, capiMachineSetKind, capiMachineSetName)
+				verifyConditionsMatch([]expectedCondition{
+					{commonconditions.ProcessingType, metav1.ConditionTrue, remediationStarted},
+					{commonconditions.SucceededType, metav1.ConditionUnknown, remediationStarted}})
+			})
+		})
+
+		When("worker node's machine is owned by a MachineSet of a MachineDeployment", func() {
+			BeforeEach(func() {
+				capiMachineDeployment = createCapiOwner(capiMachineDeploymentKind, capiMachineDeploymentName, 1)
+				Expect(k8sClient.Create(context.Background(), capiMachineDeployment)).To(Succeed())
+				DeferCleanup(k8sClient.Delete, capiMachineDeployment)
+
+				setCapiControllerOwner(capiMachineSet, capiMachineDeployment)
+				Expect(k8sClient.Update(context.Background(), capiMachineSet)).To(Succeed())
+				capiWorkerMachine.SetLabels(map[string]string{capiDeploymentNameLabel: capiMachineDeploymentName})
+				Expect(k8sClient.Update(context.Background(), capiWorkerMachine)).To(Succeed())
+
+				underTest = createRemediationOwnedByNHC(capiWorkerNodeName)
+			})
+
+			It("restoration is verified against the MachineDeployment", func() {
+				verifyCapiMachineIsDeleted(capiWorkerMachineName)
+				verifyRemediationStatusMachine(capiGroupVersion.String(), capiWorkerMachineName, capiMachineDeploymentKind, capiMachineDeploymentName)
+
+				// The replacement is created by a new MachineSet of the same MachineDeployment (e.g. during a rollout)
+				newMachineSet := createCapiOwner(capiMachineSetKind, capiMachineSetName+"-new", 1)
+				setCapiControllerOwner(newMachineSet, capiMachineDeployment)
+				Expect(k8sClient.Create(context.Background(), newMachineSet)).To(Succeed())
+				DeferCleanup(k8sClient.Delete, newMachineSet)
+
+				replacement := createCapiMachineWithOwner(capiWorkerMachineName+"-replacement", newMachineSet)
+				replacement.SetLabels(map[string]string{capiDeploymentNameLabel: capiMachineDeploymentName})
+				Expect(k8sClient.Create(context.Background(), replacement)).To(Succeed())
+				DeferCleanup(k8sClient.Delete, replacement)
+				updateNodeCapiMachine(capiWorkerNode, replacement)
+				setNodeReady(capiWorkerNode)
+
+				verifyConditionsMatch([]expectedCondition{
+					{commonconditions.ProcessingType, metav1.ConditionFalse, remediationFinishedMachineDeleted},
+					{commonconditions.SucceededType, metav1.ConditionTrue, remediationFinishedMachineDeleted}})
+			})
+		})
+
+		When("worker node's machine is owned by a custom owner kind", func() {
+			const nodePoolKind, nodePoolName = "NodePool", "node-pool-x"
+			poolLabels := map[string]interface{}{"scaling.example.com/pool": nodePoolName}
+
+			BeforeEach(func() {
+				updateOperatorConfig(func(spec *v1alpha1.MachineDeletionRemediationConfigSpec) {
+					spec.OwnerKinds = []v1alpha1.MachineOwnerKind{{
+						MachineAPIGroup:     capiGroup,
+						APIVersion:          "scaling.example.com/v1",
+						Kind:                nodePoolKind,
+						ReplicasPath:        "{.spec.size}",
+						MachineSelectorPath: "{.spec.machineSelector}",
+					}}
+					spec.EnabledOwnerKinds = append(spec.EnabledOwnerKinds, nodePoolKind)
+				})
+
+				nodePool := &unstructured.Unstructured{}
+				nodePool.SetAPIVersion("scaling.example.com/v1")
+				nodePool.SetKind(nodePoolKind)
+				nodePool.SetNamespace(machineNamespace)
+				nodePool.SetName(nodePoolName)
+				Expect(unstructured.SetNestedField(nodePool.Object, int64(1), "spec", "size")).To(Succeed())
+				Expect(unstructured.SetNestedMap(nodePool.Object, poolLabels, "spec", "machineSelector", "matchLabels")).To(Succeed())
+				Expect(k8sClient.Create(context.Background(), nodePool)).To(Succeed())
+				DeferCleanup(k8sClient.Delete, nodePool)
+
+				setCapiControllerOwner(capiWorkerMachine, nodePool)
+				capiWorkerMachine.SetLabels(map[string]string{"scaling.example.com/pool": nodePoolName})
+				Expect(k8sClient.Update(context.Background(), capiWorkerMachine)).To(Succeed())
+
+				underTest = createRemediationOwnedByNHC(capiWorkerNodeName)
+			})
+
+			It("restoration is verified against the owner's replicas path and machine selector", func() {
+				verifyCapiMachineIsDeleted(capiWorkerMachineName)
+				verifyRemediationStatusMachine(capiGroupVersion.String(), capiWorkerMachineName, nodePoolKind, nodePoolName)
+
+				// A Machine created later by another owner is not a replacement
+				other := createCapiMachineWithOwner(capiWorkerMachineName+"-other", capiMachineSet)
+				Expect(k8sClient.Create(context.Background(), other)).To(Succeed())
+				DeferCleanup(k8sClient.Delete, other)
+
+				// The replacement is selected by label, regardless of its ownerReference
+				replacement := createCapiMachineWithOwner(capiWorkerMachineName+"-replacement", capiMachineSet)
+				replacement.SetLabels(map[string]string{"scaling.example.com/pool": nodePoolName})
+				Expect(k8sClient.Create(context.Background(), replacement)).To(Succeed())
+				DeferCleanup(k8sClient.Delete, replacement)
+				updateNodeCapiMachine(capiWorkerNode, replacement)
+				setNodeReady(capiWorkerNode)
+
+				verifyConditionsMatch([]expectedCondition{
+					{commonconditions.ProcessingType, metav1.ConditionFalse, remediationFinishedMachineDeleted},
+					{commonconditions.SucceededType, metav1.ConditionTrue, remediationFinishedMachineDeleted}})
+				mdr := &v1alpha1.MachineDeletionRemediation{}
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+				Expect(mdr.Status.MachineOwner.APIVersion).To(Equal("scaling.example.com/v1"))
+				Expect(mdr.Status.ReplacementMachineName).To(Equal(replacement.GetName()))
+			})
+		})
+
+		When("control plane node's machine is owned by a KubeadmControlPlane", func() {
+			BeforeEach(func() {
+				createHealthyControlPlanePeers(capiKcp, 2)
+				underTest = createRemediationOwnedByNHC(capiCpNodeName)
+			})
+
+			It("control plane machine is deleted", func() {
+				verifyCapiMachineIsDeleted(capiCpMachineName)
+				verifyCapiMachineNotDeleted(capiWorkerMachineName)
+				verifyRemediationStatusMachine(capiGroupVersion.String(), capiCpMachineName, capiKcpKind, capiKcpName)
+
+				replacement := createCapiMachineWithOwner(capiCpMachineName+"-replacement", capiKcp)
+				Expect(k8sClient.Create(context.Background(), replacement)).To(Succeed())
+				DeferCleanup(k8sClient.Delete, replacement)
+				updateNodeCapiMachine(capiCpNode, replacement)
+				setNodeReady(capiCpNode)
+
+				verifyConditionsMatch([]expectedCondition{
+					{commonconditions.ProcessingType, metav1.ConditionFalse, remediationFinishedMachineDeleted},
+					{commonconditions.SucceededType, metav1.ConditionTrue, remediationFinishedMachineDeleted}})
+			})
+		})
+
+		When("node has no Cluster API annotations but a matching providerID", func() {
+			BeforeEach(func() {
+				providerID := "aws:///us-east-1a/i-0123456789"
+				Expect(unstructured.SetNestedField(capiWorkerMachine.Object, providerID, "spec", "providerID")).To(Succeed())
+				Expect(k8sClient.Update(context.Background(), capiWorkerMachine)).To(Succeed())
+
+				capiWorkerNode.Annotations = nil
+				capiWorkerNode.Spec.ProviderID = providerID
+				Expect(k8sClient.Update(context.Background(), capiWorkerNode)).To(Succeed())
+
+				underTest = createRemediationOwnedByNHC(capiWorkerNodeName)
+			})
+
+			It("worker machine is deleted", func() {
+				verifyCapiMachineIsDeleted(capiWorkerMachineName)
+				verifyConditionMatches(commonconditions.PermanentNodeDeletionExpectedType, metav1.ConditionTrue, v1alpha1.MachineDeletionOnCloudProviderReason)
+			})
+		})
+
+		When("remediation is created by Cluster API MachineHealthCheck", func() {
+			BeforeEach(func() {
+				underTest = createRemediationOwnedByMHC("capi-remediation-name", capiWorkerMachine)
+			})
+
+			It("worker machine is deleted", func() {
+				verifyCapiMachineIsDeleted(capiWorkerMachineName)
+				verifyCapiMachineNotDeleted(capiCpMachineName)
+			})
 		})
 	})
 })
@@ -625,16 +2181,21 @@ func createRemediationOwnedByNHC(remediationName string) *v1alpha1.MachineDeleti
 	return mdr
 }
 
-func createRemediationOwnedByMHC(remediationName string, owner *machinev1beta1.Machine) *v1alpha1.MachineDeletionRemediation {
+func createRemediationOwnedByMHC(remediationName string, owner client.Object) *v1alpha1.MachineDeletionRemediation {
+	apiVersion := machinev1beta1.SchemeGroupVersion.String()
+	if u, ok := owner.(*unstructured.Unstructured); ok {
+		apiVersion = u.GetAPIVersion()
+	}
+
 	mdr := &v1alpha1.MachineDeletionRemediation{}
 	mdr.Name = remediationName
 	mdr.Namespace = machineNamespace
 	mdr.SetOwnerReferences([]metav1.OwnerReference{
 		{
-			Name:       owner.Name,
+			Name:       owner.GetName(),
 			Kind:       "Machine",
 			UID:        "1234",
-			APIVersion: "machine.openshift.io/v1beta1",
+			APIVersion: apiVersion,
 		},
 	})
 	return mdr
@@ -662,6 +2223,23 @@ func createNode(nodeName string) *v1.Node {
 }
 
 // createMachineSet creates a MachineSet with the given name.
+func createPodOnNode(podName string, node *v1.Node) *v1.Pod {
+	pod := &v1.Pod{}
+	pod.Name = podName
+	pod.Namespace = defaultNamespace
+	pod.Spec.NodeName = node.Name
+	pod.Spec.Containers = []v1.Container{{Name: "test", Image: "test"}}
+	return pod
+}
+
+// deletePodNow deletes the Pod without waiting for the kubelet, which does not run in the test environment
+func deletePodNow(ctx context.Context, pod *v1.Pod) error {
+	if err := k8sClient.Delete(ctx, pod, client.GracePeriodSeconds(0)); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
 func createMachineSet(machineSetName string, replicas int32) *machinev1beta1.MachineSet {
 	machineSet := &machinev1beta1.MachineSet{}
 	machineSet.SetNamespace(machineNamespace)
@@ -740,6 +2318,62 @@ func createMachineWithOwner(machineName string, owner metav1.Object) *machinev1b
 	return machine
 }
 
+// updateOperatorConfig updates the operator configuration CR, waits for the configuration to be applied, and restores
+// the previous configuration when the test ends
+func updateOperatorConfig(update func(spec *v1alpha1.MachineDeletionRemediationConfigSpec)) {
+	config := &v1alpha1.MachineDeletionRemediationConfig{}
+	key := client.ObjectKey{Name: v1alpha1.ConfigCRName, Namespace: operatorNamespace}
+	Eventually(func() error {
+		return k8sClient.Get(context.Background(), key, config)
+	}, "10s", "1s").Should(Succeed())
+
+	original := *config.Spec.DeepCopy()
+	update(&config.Spec)
+	expected, _ := getEffectiveConfig(config.Spec)
+	Expect(k8sClient.Update(context.Background(), config)).To(Succeed())
+	Eventually(operatorConfig.Get, "10s", "100ms").Should(Equal(expected))
+
+	DeferCleanup(func() {
+		Expect(k8sClient.Get(context.Background(), key, config)).To(Succeed())
+		config.Spec = original
+		Expect(k8sClient.Update(context.Background(), config)).To(Succeed())
+		Eventually(operatorConfig.Get, "10s", "100ms").Should(Equal(original))
+	})
+}
+
+// loadOperatorConfig loads the operator configuration from the config CR, as the operator does when it starts
+func loadOperatorConfig() {
+	config := &v1alpha1.MachineDeletionRemediationConfig{}
+	key := client.ObjectKey{Name: v1alpha1.ConfigCRName, Namespace: operatorNamespace}
+	Expect(k8sClient.Get(context.Background(), key, config)).To(Succeed())
+	operatorConfig.Load(config)
+}
+
+// createHealthyControlPlanePeers creates the given number of control plane Machines owned by owner, each one with a
+// Ready Node. The owner can be a ControlPlaneMachineSet or a Cluster API KubeadmControlPlane.
+func createHealthyControlPlanePeers(owner client.Object, count int) []*v1.Node {
+	var nodes []*v1.Node
+	for i := 0; i < count; i++ {
+		nodeName := fmt.Sprintf("%s-peer-%d", owner.GetName(), i)
+		var machine client.Object
+		var node *v1.Node
+		if capiOwner, isCapi := owner.(*unstructured.Unstructured); isCapi {
+			capiMachine := createCapiMachineWithOwner(nodeName+"-machine", capiOwner)
+			machine, node = capiMachine, createNodeWithCapiMachine(nodeName, capiMachine)
+		} else {
+			openshiftMachine := createMachineWithOwner(nodeName+"-machine", owner)
+			machine, node = openshiftMachine, createNodeWithMachine(nodeName, openshiftMachine)
+		}
+		Expect(k8sClient.Create(context.Background(), machine)).To(Succeed())
+		DeferCleanup(deleteIgnoreNotFound(), machine)
+		Expect(k8sClient.Create(context.Background(), node)).To(Succeed())
+		DeferCleanup(k8sClient.Delete, node)
+		setNodeReady(node)
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
 func createDummyMachine() *machinev1beta1.Machine {
 	return createMachine(dummyMachine)
 }
@@ -766,6 +2400,25 @@ func deleteIgnoreNotFound() func(ctx context.Context, obj client.Object) error {
 	}
 }
 
+// clearEvents discards the events emitted by the previous tests, so that the recorder does not block on a full buffer
+func clearEvents() {
+	for len(fakeRecorder.Events) > 0 {
+		<-fakeRecorder.Events
+	}
+}
+
+// deleteRemediationAndWait deletes the remediation and waits for MDR to remove its finalizer, so that a remediation
+// with the same name can be created by the next test
+func deleteRemediationAndWait(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation) error {
+	if err := k8sClient.Delete(ctx, remediation); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	Eventually(func() bool {
+		return errors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(remediation), &v1alpha1.MachineDeletionRemediation{}))
+	}, "10s", "100ms").Should(BeTrue(), "MachineDeletionRemediation %s should have been deleted", remediation.Name)
+	return nil
+}
+
 func verifyConditionMatches(conditionType string, conditionStatus metav1.ConditionStatus, reason conditionChangeReason) {
 	msg := fmt.Sprintf("Verifying that Condition '%v' is '%v' because '%v'", conditionType, conditionStatus, reason)
 	By(msg)
@@ -836,6 +2489,183 @@ func setMachineProviderID(machine *machinev1beta1.Machine, providerID string) {
 	Expect(k8sClient.Update(context.TODO(), machine)).To(Succeed())
 }
 
+// setMachineProviderSpecKind sets a providerSpec of the given Kind on the Machine
+func setMachineProviderSpecKind(machine *machinev1beta1.Machine, kind string) {
+	EventuallyWithOffset(1, func(g Gomega) {
+		g.Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(machine), machine)).To(Succeed())
+		machine.Spec.ProviderSpec.Value = &runtime.RawExtension{Raw: []byte(fmt.Sprintf(`{"kind":%q}`, kind))}
+		g.Expect(k8sClient.Update(context.Background(), machine)).To(Succeed())
+	}, "10s", "1s").Should(Succeed())
+}
+
+// createInfrastructure creates the cluster's Infrastructure CR with the given platform type, and deletes it when the
+// test ends
+func createInfrastructure(platformType configv1.PlatformType) {
+	infrastructure := &configv1.Infrastructure{ObjectMeta: metav1.ObjectMeta{Name: infrastructureName}}
+	ExpectWithOffset(1, k8sClient.Create(context.Background(), infrastructure)).To(Succeed())
+	DeferCleanup(k8sClient.Delete, infrastructure)
+
+	infrastructure.Status.Platform = platformType
+	infrastructure.Status.PlatformStatus = &configv1.PlatformStatus{Type: platformType}
+	ExpectWithOffset(1, k8sClient.Status().Update(context.Background(), infrastructure)).To(Succeed())
+}
+
+// createBareMetalHost creates a Metal3 BareMetalHost in the given provisioning state, and deletes it when the test ends
+func createBareMetalHost(name, state string) *unstructured.Unstructured {
+	host := &unstructured.Unstructured{}
+	host.SetGroupVersionKind(bareMetalHostGVK)
+	host.SetName(name)
+	host.SetNamespace(machineNamespace)
+	ExpectWithOffset(1, k8sClient.Create(context.Background(), host)).To(Succeed())
+	DeferCleanup(k8sClient.Delete, host)
+	updateBareMetalHostStatus(host, state, "OK", "", "")
+	return host
+}
+
+// updateBareMetalHostStatus updates the provisioning state and the operational status of the BareMetalHost
+func updateBareMetalHostStatus(host *unstructured.Unstructured, state, operationalStatus, errorType, errorMessage string) {
+	EventuallyWithOffset(1, func(g Gomega) {
+		g.Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(host), host)).To(Succeed())
+		g.Expect(unstructured.SetNestedField(host.Object, state, "status", "provisioning", "state")).To(Succeed())
+		g.Expect(unstructured.SetNestedField(host.Object, operationalStatus, "status", "operationalStatus")).To(Succeed())
+		g.Expect(unstructured.SetNestedField(host.Object, errorType, "status", "errorType")).To(Succeed())
+		g.Expect(unstructured.SetNestedField(host.Object, errorMessage, "status", "errorMessage")).To(Succeed())
+		g.Expect(k8sClient.Status().Update(context.Background(), host)).To(Succeed())
+	}, "10s", "1s").Should(Succeed())
+}
+
+// verifyBareMetalHostState verifies that the remediation status reports the given BareMetalHost provisioning state
+func verifyBareMetalHostState(state string) {
+	By(fmt.Sprintf("Verifying that the remediation status reports the BareMetalHost in %s state", state))
+	EventuallyWithOffset(1, func(g Gomega) {
+		mdr := &v1alpha1.MachineDeletionRemediation{}
+		g.Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+		g.Expect(mdr.Status.BareMetalHost).ToNot(BeNil())
+		g.Expect(mdr.Status.BareMetalHost.ProvisioningState).To(Equal(state))
+	}, "30s", "1s").Should(Succeed())
+}
+
+// createRebootRemediationTemplate creates a template of the test fallback remediator
+func createRebootRemediationTemplate(name string) *unstructured.Unstructured {
+	template := &unstructured.Unstructured{}
+	template.SetAPIVersion("remediation.example.com/v1alpha1")
+	template.SetKind("RebootRemediationTemplate")
+	template.SetName(name)
+	template.SetNamespace(defaultNamespace)
+	ExpectWithOffset(1, unstructured.SetNestedField(template.Object, "Power", "spec", "template", "spec", "rebootMethod")).To(Succeed())
+	ExpectWithOffset(1, k8sClient.Create(context.Background(), template)).To(Succeed())
+	DeferCleanup(k8sClient.Delete, template)
+	return template
+}
+
+// setRemediationSucceededCondition sets the Succeeded condition of the remediation of another remediator
+func setRemediationSucceededCondition(remediation *unstructured.Unstructured, status metav1.ConditionStatus, message string) {
+	EventuallyWithOffset(1, func(g Gomega) {
+		g.Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(remediation), remediation)).To(Succeed())
+		conditions := []interface{}{map[string]interface{}{
+			"type":    commonconditions.SucceededType,
+			"status":  string(status),
+			"reason":  "Test",
+			"message": message,
+		}}
+		g.Expect(unstructured.SetNestedSlice(remediation.Object, conditions, "status", "conditions")).To(Succeed())
+		g.Expect(k8sClient.Status().Update(context.Background(), remediation)).To(Succeed())
+	}, "10s", "1s").Should(Succeed())
+}
+
+// createCapiOwner creates a Cluster API Machine owner (MachineSet, MachineDeployment or KubeadmControlPlane) with the given name.
+func createCapiOwner(kind, name string, replicas int64) *unstructured.Unstructured {
+	owner := &unstructured.Unstructured{}
+	resolver, _ := getBuiltinOwnerResolver(capiGroup, kind)
+	owner.SetAPIVersion(resolver.apiVersion)
+	owner.SetKind(kind)
+	owner.SetNamespace(machineNamespace)
+	owner.SetName(name)
+	Expect(unstructured.SetNestedField(owner.Object, replicas, "spec", "replicas")).To(Succeed())
+	return owner
+}
+
+func setCapiControllerOwner(obj, owner *unstructured.Unstructured) {
+	obj.SetOwnerReferences([]metav1.OwnerReference{
+		{
+			Name:       owner.GetName(),
+			Kind:       owner.GetKind(),
+			UID:        "1234",
+			APIVersion: owner.GetAPIVersion(),
+			Controller: ptr.To(true),
+		},
+	})
+}
+
+func createCapiMachineWithOwner(machineName string, owner *unstructured.Unstructured) *unstructured.Unstructured {
+	machine := (&capiMachineBackend{}).newMachine().(*unstructured.Unstructured)
+	machine.SetNamespace(machineNamespace)
+	machine.SetName(machineName)
+	setCapiControllerOwner(machine, owner)
+	return machine
+}
+
+func createNodeWithCapiMachine(nodeName string, machine *unstructured.Unstructured) *v1.Node {
+	n := createNode(nodeName)
+	n.Annotations[capiMachineAnnotation] = machine.GetName()
+	n.Annotations[capiClusterNamespaceAnnotation] = machine.GetNamespace()
+	return n
+}
+
+func updateNodeCapiMachine(node *v1.Node, machine *unstructured.Unstructured) {
+	Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(node), node)).To(Succeed())
+	node.Annotations[capiMachineAnnotation] = machine.GetName()
+	Expect(k8sClient.Update(context.Background(), node)).To(Succeed())
+}
+
+func setNodeReady(node *v1.Node) {
+	node.Status.Conditions = []v1.NodeCondition{
+		{
+			Type:   v1.NodeReady,
+			Status: v1.ConditionTrue,
+		},
+	}
+	Expect(k8sClient.Status().Update(context.Background(), node)).To(Succeed())
+}
+
+func verifyCapiMachineNotDeleted(machineName string) {
+	Consistently(
+		func() error {
+			return k8sClient.Get(context.Background(), client.ObjectKey{Namespace: machineNamespace, Name: machineName}, (&capiMachineBackend{}).newMachine())
+		}).ShouldNot(HaveOccurred(), "Machine %s should not have been deleted", machineName)
+}
+
+func verifyCapiMachineIsDeleted(machineName string) {
+	Eventually(func() bool {
+		return errors.IsNotFound(k8sClient.Get(context.Background(), client.ObjectKey{Namespace: machineNamespace, Name: machineName}, (&capiMachineBackend{}).newMachine()))
+	}).Should(BeTrue(), "Machine %s should have been deleted", machineName)
+}
+
+func verifyRemediationStatusMachine(machineAPIVersion, machineName, ownerKind, ownerName string) {
+	By(fmt.Sprintf("Verifying that the remediation status has Machine %s owned by %s %s", machineName, ownerKind, ownerName))
+	Eventually(func(g Gomega) {
+		mdr := &v1alpha1.MachineDeletionRemediation{}
+		g.Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+		g.Expect(mdr.Status.Machine).ToNot(BeNil())
+		g.Expect(mdr.Status.Machine.APIVersion).To(Equal(machineAPIVersion))
+		g.Expect(mdr.Status.Machine.Name).To(Equal(machineName))
+		g.Expect(mdr.Status.Machine.Namespace).To(Equal(machineNamespace))
+		g.Expect(mdr.Status.MachineOwner).ToNot(BeNil())
+		g.Expect(mdr.Status.MachineOwner.Kind).To(Equal(ownerKind))
+		g.Expect(mdr.Status.MachineOwner.Name).To(Equal(ownerName))
+		g.Expect(mdr.Status.MachineOwner.Namespace).To(Equal(machineNamespace))
+	}, "30s", "1s").Should(Succeed())
+}
+
+func verifyRemediationPhase(phase v1alpha1.RemediationPhase) {
+	By(fmt.Sprintf("Verifying that the remediation phase is %s", phase))
+	Eventually(func(g Gomega) {
+		mdr := &v1alpha1.MachineDeletionRemediation{}
+		g.Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(underTest), mdr)).To(Succeed())
+		g.Expect(mdr.Status.Phase).To(Equal(phase))
+	}, "30s", "1s").Should(Succeed())
+}
+
 type expectedEvent struct {
 	eventType, reason, message string
 	expected                   bool
@@ -868,3 +2698,195 @@ func verifyEvents(expectedEvents []expectedEvent) {
 		Expect(exists).To(Equal(expected), "event test failed.\nEvent '%s': expected %v, received %v", formattedEventMessage, expected, exists)
 	}
 }
+
+// verifyEventEmitted verifies that an event with the given type and reason, whose message contains all the given
+// substrings, is emitted. It is meant for events whose message is not fully predictable, e.g. containing durations.
+func verifyEventEmitted(eventType, reason string, messageSubstrings ...string) {
+	By(fmt.Sprintf("verifying that a %s event is emitted", reason))
+	prefix := fmt.Sprintf("%s %s ", eventType, reason)
+	Eventually(func() bool {
+		select {
+		case got := <-fakeRecorder.Events:
+			if !strings.HasPrefix(got, prefix) {
+				return false
+			}
+			for _, substring := range messageSubstrings {
+				if !strings.Contains(got, substring) {
+					return false
+				}
+			}
+			return true
+		default:
+			return false
+		}
+	}, "10s", "10ms").Should(BeTrue(), "event %s was not emitted", reason)
+}
+
+var _ = Describe("Max in flight", func() {
+	DescribeTable("parsing",
+		func(value string, expected *intstr.IntOrString, expectErr bool) {
+			maxInFlight, err := ParseMaxInFlight(value)
+			if expectErr {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+			Expect(err).ToNot(HaveOccurred())
+			Expect(maxInFlight).To(Equal(expected))
+		},
+		Entry("empty value means no limit", "", nil, false),
+		Entry("absolute number", "3", ptr.To(intstr.FromInt32(3)), false),
+		Entry("percentage", "25%", ptr.To(intstr.FromString("25%")), false),
+		Entry("negative number", "-1", nil, true),
+		Entry("percentage above 100%", "101%", nil, true),
+		Entry("not a number", "three", nil, true),
+	)
+
+	DescribeTable("limit",
+		func(value string, total, expected int) {
+			maxInFlight, err := ParseMaxInFlight(value)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(getMaxInFlightLimit(maxInFlight, total)).To(Equal(expected))
+		},
+		Entry("absolute number ignores the total", "2", 10, 2),
+		Entry("percentage of the total", "50%", 10, 5),
+		Entry("percentage is rounded up", "10%", 3, 1),
+		Entry("zero percent", "0%", 10, 0),
+	)
+
+	DescribeTable("remediations in flight",
+		func(update func(other *v1alpha1.MachineDeletionRemediation), expected bool) {
+			now := metav1.Now()
+			remediation := &v1alpha1.MachineDeletionRemediation{ObjectMeta: metav1.ObjectMeta{Name: "remediation", CreationTimestamp: now}}
+			other := &v1alpha1.MachineDeletionRemediation{ObjectMeta: metav1.ObjectMeta{
+				Name:              "other",
+				CreationTimestamp: metav1.NewTime(now.Add(-time.Minute)),
+			}}
+			update(other)
+			Expect(isInFlight(other, remediation)).To(Equal(expected))
+		},
+		Entry("created before", func(_ *v1alpha1.MachineDeletionRemediation) {}, true),
+		Entry("created after", func(other *v1alpha1.MachineDeletionRemediation) {
+			other.CreationTimestamp = metav1.NewTime(time.Now().Add(time.Minute))
+		}, false),
+		Entry("Machine deletion requested", func(other *v1alpha1.MachineDeletionRemediation) {
+			other.CreationTimestamp = metav1.NewTime(time.Now().Add(time.Minute))
+			other.Status.MachineDeletionRequestedTime = ptr.To(metav1.Now())
+		}, true),
+		Entry("completed", func(other *v1alpha1.MachineDeletionRemediation) {
+			other.Status.MachineDeletionRequestedTime = ptr.To(metav1.Now())
+			meta.SetStatusCondition(&other.Status.Conditions, metav1.Condition{
+				Type: commonconditions.ProcessingType, Status: metav1.ConditionFalse, Reason: string(remediationFinishedMachineDeleted),
+			})
+		}, false),
+		Entry("blocked by the control plane quorum", func(other *v1alpha1.MachineDeletionRemediation) {
+			meta.SetStatusCondition(&other.Status.Conditions, metav1.Condition{
+				Type: commonconditions.ProcessingType, Status: metav1.ConditionTrue, Reason: string(remediationBlockedQuorumAtRisk),
+			})
+		}, false),
+		Entry("handed off to its fallback remediation", func(other *v1alpha1.MachineDeletionRemediation) {
+			other.Status.FallbackRemediation = &v1alpha1.FallbackRemediationStatus{
+				Remediation: v1alpha1.ObjectReference{Kind: "SelfNodeRemediation", Name: "other"},
+				Reason:      string(remediationSkippedNoControllerOwner),
+				CreatedTime: ptr.To(metav1.Now()),
+			}
+			meta.SetStatusCondition(&other.Status.Conditions, metav1.Condition{
+				Type: commonconditions.ProcessingType, Status: metav1.ConditionTrue, Reason: string(remediationFallbackStarted),
+			})
+		}, false),
+	)
+})
+
+var _ = Describe("Stuck deletion escalations", func() {
+	const timeout = time.Minute
+
+	DescribeTable("next escalation",
+		func(applied []v1alpha1.DeletionEscalation, lastEscalation time.Duration, expected v1alpha1.DeletionEscalation, due bool) {
+			spec := &v1alpha1.StuckDeletionSpec{
+				Timeout: metav1.Duration{Duration: timeout},
+				Escalations: []v1alpha1.DeletionEscalation{
+					v1alpha1.DeletionEscalationExcludeNodeDraining, v1alpha1.DeletionEscalationRemovePreTerminateHook,
+				},
+			}
+			status := &v1alpha1.StuckDeletionStatus{Escalations: applied}
+			if len(applied) > 0 {
+				status.LastEscalationTime = ptr.To(metav1.NewTime(time.Now().Add(-lastEscalation)))
+			}
+			escalation, remaining := getNextDeletionEscalation(spec, status)
+			Expect(escalation).To(Equal(expected))
+			Expect(remaining <= 0).To(Equal(due))
+		},
+		Entry("first escalation", nil, time.Duration(0), v1alpha1.DeletionEscalationExcludeNodeDraining, true),
+		Entry("next escalation before the timeout",
+			[]v1alpha1.DeletionEscalation{v1alpha1.DeletionEscalationExcludeNodeDraining}, timeout/2,
+			v1alpha1.DeletionEscalationRemovePreTerminateHook, false),
+		Entry("next escalation after the timeout",
+			[]v1alpha1.DeletionEscalation{v1alpha1.DeletionEscalationExcludeNodeDraining}, 2*timeout,
+			v1alpha1.DeletionEscalationRemovePreTerminateHook, true),
+		Entry("all escalations applied",
+			[]v1alpha1.DeletionEscalation{v1alpha1.DeletionEscalationExcludeNodeDraining, v1alpha1.DeletionEscalationRemovePreTerminateHook},
+			2*timeout, v1alpha1.DeletionEscalation(""), true),
+	)
+})
+
+var _ = Describe("Machine resolution", func() {
+	const (
+		resolvedNodeName    = "resolved-node"
+		resolvedMachineName = "resolved-machine"
+	)
+	var reconciler *MachineDeletionRemediationReconciler
+
+	BeforeEach(func() {
+		reconciler = &MachineDeletionRemediationReconciler{
+			Client: k8sClient,
+			Log:    ctrl.Log.WithName("machine-resolution-test"),
+		}
+
+		machine := createMachine(resolvedMachineName)
+		Expect(k8sClient.Create(context.Background(), machine)).To(Succeed())
+		DeferCleanup(k8sClient.Delete, machine)
+
+		node := createNodeWithMachine(resolvedNodeName, machine)
+		Expect(k8sClient.Create(context.Background(), node)).To(Succeed())
+		DeferCleanup(k8sClient.Delete, node)
+
+		nodeWithoutMachine := createNode("node-without-machine")
+		Expect(k8sClient.Create(context.Background(), nodeWithoutMachine)).To(Succeed())
+		DeferCleanup(k8sClient.Delete, nodeWithoutMachine)
+
+		nodeWithMissingMachine := createNodeWithMachine("node-with-missing-machine", createMachine("missing-machine"))
+		Expect(k8sClient.Create(context.Background(), nodeWithMissingMachine)).To(Succeed())
+		DeferCleanup(k8sClient.Delete, nodeWithMissingMachine)
+	})
+
+	It("resolves the Machine of the remediation's Node", func() {
+		Eventually(func() (v1alpha1.MachineKey, error) {
+			return reconciler.ResolveMachine(context.Background(), createRemediationOwnedByNHC(resolvedNodeName))
+		}, "10s", "1s").Should(Equal(v1alpha1.MachineKey{
+			Group:     machinev1beta1.GroupName,
+			ObjectKey: client.ObjectKey{Name: resolvedMachineName, Namespace: machineNamespace},
+		}))
+	})
+
+	It("resolves the Machine of the remediation's ownerReference", func() {
+		machine := createMachine(resolvedMachineName)
+		Eventually(func() (v1alpha1.MachineKey, error) {
+			return reconciler.ResolveMachine(context.Background(), createRemediationOwnedByMHC("mhc-remediation", machine))
+		}, "10s", "1s").Should(Equal(v1alpha1.MachineKey{
+			Group:     machinev1beta1.GroupName,
+			ObjectKey: client.ObjectKey{Name: resolvedMachineName, Namespace: machineNamespace},
+		}))
+	})
+
+	DescribeTable("fails",
+		func(nodeName, expectedErr string) {
+			Eventually(func() error {
+				_, err := reconciler.ResolveMachine(context.Background(), createRemediationOwnedByNHC(nodeName))
+				return err
+			}, "10s", "1s").Should(MatchError(expectedErr))
+		},
+		Entry("when the Node does not exist", "missing-node", "node missing-node not found"),
+		Entry("when the Node has no Machine", "node-without-machine", "node node-without-machine is not associated with a supported Machine"),
+		Entry("when the Machine does not exist", "node-with-missing-machine",
+			"machine openshift-machine-api/missing-machine of node node-with-missing-machine not found"),
+	)
+})
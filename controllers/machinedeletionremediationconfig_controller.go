@@ -0,0 +1,121 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/go-logr/logr"
+
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/medik8s/machine-deletion-remediation/api/v1alpha1"
+)
+
+// MachineDeletionRemediationConfigReconciler reconciles the MachineDeletionRemediationConfig object
+type MachineDeletionRemediationConfigReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+	// Namespace is the operator's namespace, where the MachineDeletionRemediationConfig is looked for
+	Namespace string
+	// Config is updated with the effective configuration
+	Config *OperatorConfig
+}
+
+//+kubebuilder:rbac:groups=machine-deletion-remediation.medik8s.io,resources=machinedeletionremediationconfigs,verbs=get;list;watch;create;update;patch
+//+kubebuilder:rbac:groups=machine-deletion-remediation.medik8s.io,resources=machinedeletionremediationconfigs/status,verbs=get;update;patch
+
+// Reconcile validates the MachineDeletionRemediationConfig, applies its effective configuration to the operator, and
+// reports it in the CR's status. The default configuration is applied if the CR does not exist.
+func (r *MachineDeletionRemediationConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("machinedeletionremediationconfig", req.NamespacedName)
+
+	config := &v1alpha1.MachineDeletionRemediationConfig{}
+	if err := r.Get(ctx, req.NamespacedName, config); err != nil {
+		if apiErrors.IsNotFound(err) {
+			log.Info("MDR config not found, using the default configuration")
+			r.Config.set(NewOperatorConfig().Get())
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "could not get MDR config")
+		return ctrl.Result{}, err
+	}
+
+	effective, validationErrors := getEffectiveConfig(config.Spec)
+	if len(validationErrors) > 0 {
+		log.Info("MDR config has invalid fields, using their defaults", "errors", validationErrors)
+	}
+	if previous := r.Config.Get(); previous.LeaderElectionID != effective.LeaderElectionID {
+		log.Info("the leader election ID changes after the operator restarts", "current", previous.LeaderElectionID, "new", effective.LeaderElectionID)
+	}
+	r.Config.set(effective)
+	log.Info("MDR config applied", "config", effective)
+
+	if reflect.DeepEqual(config.Status.EffectiveConfig, effective) &&
+		reflect.DeepEqual(config.Status.ValidationErrors, validationErrors) &&
+		config.Status.ObservedGeneration == config.Generation {
+		return ctrl.Result{}, nil
+	}
+
+	config.Status.EffectiveConfig = effective
+	config.Status.ValidationErrors = validationErrors
+	config.Status.ObservedGeneration = config.Generation
+	if err := r.Status().Update(ctx, config); err != nil {
+		if !apiErrors.IsConflict(err) {
+			log.Error(err, "could not update MDR config status")
+		}
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager. It also creates the MachineDeletionRemediationConfig with
+// the default configuration, if it does not exist yet.
+func (r *MachineDeletionRemediationConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	isConfigCR := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetName() == v1alpha1.ConfigCRName && obj.GetNamespace() == r.Namespace
+	})
+
+	if err := mgr.Add(manager.RunnableFunc(r.createDefaultConfig)); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.MachineDeletionRemediationConfig{}, builder.WithPredicates(isConfigCR)).
+		Complete(r)
+}
+
+// createDefaultConfig creates the MachineDeletionRemediationConfig, so that the default configuration can be inspected
+// and edited
+func (r *MachineDeletionRemediationConfigReconciler) createDefaultConfig(ctx context.Context) error {
+	config := &v1alpha1.MachineDeletionRemediationConfig{}
+	config.SetName(v1alpha1.ConfigCRName)
+	config.SetNamespace(r.Namespace)
+	if err := r.Create(ctx, config); err != nil && !apiErrors.IsAlreadyExists(err) {
+		r.Log.Error(err, "could not create the default MDR config")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/medik8s/machine-deletion-remediation/api/v1alpha1"
+)
+
+var _ = Describe("Machine Deletion Remediation Config CR", func() {
+	var config *v1alpha1.MachineDeletionRemediationConfig
+
+	BeforeEach(func() {
+		config = &v1alpha1.MachineDeletionRemediationConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: v1alpha1.ConfigCRName, Namespace: operatorNamespace},
+		}
+	})
+
+	When("the operator starts", func() {
+		It("creates the config CR with the default configuration", func() {
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(config), config)).To(Succeed())
+				g.Expect(config.Spec.DefaultPollInterval).To(Equal(&metav1.Duration{Duration: defaultPollInterval}))
+				g.Expect(config.Spec.StatusUpdateRequeueInterval).To(Equal(&metav1.Duration{Duration: defaultStatusUpdateRequeueInterval}))
+				g.Expect(config.Spec.LeaderElectionID).To(Equal(DefaultLeaderElectionID))
+				g.Expect(config.Spec.MaxInFlight).To(BeNil())
+				g.Expect(config.Spec.EnabledOwnerKinds).To(ConsistOf(supportedOwnerKinds))
+				g.Expect(config.Spec.BareMetalProviderIDPrefixes).To(ConsistOf(defaultBareMetalProviderIDPrefixes))
+				g.Expect(config.Status.EffectiveConfig).To(Equal(config.Spec))
+				g.Expect(config.Status.ValidationErrors).To(BeEmpty())
+				g.Expect(config.Status.ObservedGeneration).To(Equal(config.Generation))
+			}, "10s", "1s").Should(Succeed())
+		})
+	})
+
+	When("the config CR is updated", func() {
+		var original v1alpha1.MachineDeletionRemediationConfigSpec
+
+		BeforeEach(func() {
+			Eventually(func() error {
+				return k8sClient.Get(context.Background(), client.ObjectKeyFromObject(config), config)
+			}, "10s", "1s").Should(Succeed())
+			original = *config.Spec.DeepCopy()
+			DeferCleanup(func() {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(config), config)).To(Succeed())
+				config.Spec = original
+				Expect(k8sClient.Update(context.Background(), config)).To(Succeed())
+				Eventually(func(g Gomega) {
+					g.Expect(operatorConfig.Get()).To(Equal(original))
+				}, "10s", "1s").Should(Succeed())
+			})
+		})
+
+		Context("with valid values", func() {
+			It("applies the new configuration without restarting", func() {
+				config.Spec.DefaultPollInterval = &metav1.Duration{Duration: 5 * time.Second}
+				config.Spec.MaxInFlight = ptr.To(intstr.FromString("20%"))
+				config.Spec.EnabledOwnerKinds = []string{"MachineSet"}
+				Expect(k8sClient.Update(context.Background(), config)).To(Succeed())
+
+				Eventually(func(g Gomega) {
+					effective := operatorConfig.Get()
+					g.Expect(effective.DefaultPollInterval.Duration).To(Equal(5 * time.Second))
+					g.Expect(effective.MaxInFlight).To(Equal(ptr.To(intstr.FromString("20%"))))
+					g.Expect(effective.EnabledOwnerKinds).To(Equal([]string{"MachineSet"}))
+
+					g.Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(config), config)).To(Succeed())
+					g.Expect(config.Status.EffectiveConfig).To(Equal(effective))
+					g.Expect(config.Status.ValidationErrors).To(BeEmpty())
+					g.Expect(config.Status.ObservedGeneration).To(Equal(config.Generation))
+				}, "10s", "1s").Should(Succeed())
+			})
+		})
+
+		Context("with invalid values", func() {
+			It("uses the defaults of the invalid fields and reports the validation errors", func() {
+				config.Spec.MaxInFlight = ptr.To(intstr.FromString("150%"))
+				config.Spec.EnabledOwnerKinds = []string{"MachineSet", "ReplicaSet"}
+				config.Spec.LeaderElectionID = "Not_A_Valid_ID"
+				Expect(k8sClient.Update(context.Background(), config)).To(Succeed())
+
+				Eventually(func(g Gomega) {
+					g.Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(config), config)).To(Succeed())
+					g.Expect(config.Status.ObservedGeneration).To(Equal(config.Generation))
+					g.Expect(config.Status.ValidationErrors).To(HaveLen(3))
+					g.Expect(config.Status.EffectiveConfig.MaxInFlight).To(BeNil())
+					g.Expect(config.Status.EffectiveConfig.EnabledOwnerKinds).To(Equal([]string{"MachineSet"}))
+					g.Expect(config.Status.EffectiveConfig.LeaderElectionID).To(Equal(DefaultLeaderElectionID))
+					g.Expect(operatorConfig.Get()).To(Equal(config.Status.EffectiveConfig))
+				}, "10s", "1s").Should(Succeed())
+			})
+		})
+	})
+
+	DescribeTable("Effective config",
+		func(spec v1alpha1.MachineDeletionRemediationConfigSpec, validationErrors int, verify func(v1alpha1.MachineDeletionRemediationConfigSpec)) {
+			effective, errs := getEffectiveConfig(spec)
+			Expect(errs).To(HaveLen(validationErrors))
+			verify(effective)
+		},
+		Entry("empty spec uses the defaults", v1alpha1.MachineDeletionRemediationConfigSpec{}, 0,
+			func(effective v1alpha1.MachineDeletionRemediationConfigSpec) {
+				Expect(effective.DefaultPollInterval.Duration).To(Equal(defaultPollInterval))
+				Expect(effective.StatusUpdateRequeueInterval.Duration).To(Equal(defaultStatusUpdateRequeueInterval))
+				Expect(effective.LeaderElectionID).To(Equal(DefaultLeaderElectionID))
+				Expect(effective.MaxInFlight).To(BeNil())
+				Expect(effective.EnabledOwnerKinds).To(Equal(supportedOwnerKinds))
+				Expect(effective.BareMetalProviderIDPrefixes).To(Equal(defaultBareMetalProviderIDPrefixes))
+			}),
+		Entry("non positive intervals", v1alpha1.MachineDeletionRemediationConfigSpec{
+			DefaultPollInterval:         &metav1.Duration{},
+			StatusUpdateRequeueInterval: &metav1.Duration{Duration: -time.Second},
+		}, 2,
+			func(effective v1alpha1.MachineDeletionRemediationConfigSpec) {
+				Expect(effective.DefaultPollInterval.Duration).To(Equal(defaultPollInterval))
+				Expect(effective.StatusUpdateRequeueInterval.Duration).To(Equal(defaultStatusUpdateRequeueInterval))
+			}),
+		Entry("absolute max in flight", v1alpha1.MachineDeletionRemediationConfigSpec{
+			MaxInFlight: ptr.To(intstr.FromInt32(2)),
+		}, 0,
+			func(effective v1alpha1.MachineDeletionRemediationConfigSpec) {
+				Expect(effective.MaxInFlight).To(Equal(ptr.To(intstr.FromInt32(2))))
+			}),
+		Entry("no enabled owner kinds", v1alpha1.MachineDeletionRemediationConfigSpec{
+			EnabledOwnerKinds: []string{},
+		}, 0,
+			func(effective v1alpha1.MachineDeletionRemediationConfigSpec) {
+				Expect(effective.EnabledOwnerKinds).To(BeEmpty())
+			}),
+		Entry("custom bare metal prefixes", v1alpha1.MachineDeletionRemediationConfigSpec{
+			BareMetalProviderIDPrefixes: []string{"metal3", "ironic"},
+		}, 0,
+			func(effective v1alpha1.MachineDeletionRemediationConfigSpec) {
+				Expect(effective.BareMetalProviderIDPrefixes).To(Equal([]string{"metal3", "ironic"}))
+			}),
+		Entry("custom owner kinds", v1alpha1.MachineDeletionRemediationConfigSpec{
+			OwnerKinds: []v1alpha1.MachineOwnerKind{
+				{APIVersion: "scaling.example.com/v1", Kind: "NodePool"},
+				{MachineAPIGroup: "cluster.x-k8s.io", APIVersion: "scaling.example.com/v1", Kind: "MachinePool", MachineSelectorPath: "{.spec.selector}"},
+				{APIVersion: "scaling.example.com/v1", Kind: "BrokenPool", ReplicasPath: "{.spec.size"},
+				{APIVersion: "", Kind: "NoVersionPool"},
+			},
+			EnabledOwnerKinds: []string{"MachineSet", "NodePool", "MachinePool", "BrokenPool"},
+		}, 3,
+			func(effective v1alpha1.MachineDeletionRemediationConfigSpec) {
+				Expect(effective.OwnerKinds).To(Equal([]v1alpha1.MachineOwnerKind{
+					{MachineAPIGroup: "machine.openshift.io", APIVersion: "scaling.example.com/v1", Kind: "NodePool", ReplicasPath: defaultReplicasPath},
+					{MachineAPIGroup: "cluster.x-k8s.io", APIVersion: "scaling.example.com/v1", Kind: "MachinePool", ReplicasPath: defaultReplicasPath, MachineSelectorPath: "{.spec.selector}"},
+				}))
+				Expect(effective.EnabledOwnerKinds).To(Equal([]string{"MachineSet", "NodePool", "MachinePool"}))
+			}),
+		Entry("invalid maintenance windows", v1alpha1.MachineDeletionRemediationConfigSpec{
+			MaintenanceWindows: []v1alpha1.MachineOwnerMaintenanceWindow{
+				{MaintenanceWindow: v1alpha1.MaintenanceWindow{Schedule: "0 2 * * SAT", Duration: metav1.Duration{Duration: time.Hour}}},
+				{MaintenanceWindow: v1alpha1.MaintenanceWindow{Schedule: "0 2 * *", Duration: metav1.Duration{Duration: time.Hour}}},
+				{MaintenanceWindow: v1alpha1.MaintenanceWindow{Schedule: "0 2 * * *", Duration: metav1.Duration{Duration: time.Hour}, TimeZone: "Mars/Olympus_Mons"}},
+				{
+					MaintenanceWindow:    v1alpha1.MaintenanceWindow{Schedule: "0 2 * * *", Duration: metav1.Duration{Duration: time.Hour}},
+					MachineOwnerSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"invalid key!": "value"}},
+				},
+			},
+		}, 3,
+			func(effective v1alpha1.MachineDeletionRemediationConfigSpec) {
+				Expect(effective.MaintenanceWindows).To(HaveLen(1))
+				Expect(effective.MaintenanceWindows[0].Schedule).To(Equal("0 2 * * SAT"))
+			}),
+	)
+
+	It("builds the owner resolvers when the configuration changes", func() {
+		config := NewOperatorConfig()
+		Expect(config.getOwnerResolvers()).To(BeEmpty())
+
+		config.Load(&v1alpha1.MachineDeletionRemediationConfig{Spec: v1alpha1.MachineDeletionRemediationConfigSpec{
+			OwnerKinds: []v1alpha1.MachineOwnerKind{
+				{APIVersion: "scaling.example.com/v1", Kind: "NodePool", ReplicasPath: "{.spec.size}"},
+				{APIVersion: "scaling.example.com/v1", Kind: "BrokenPool", ReplicasPath: "{.spec.size"},
+			},
+		}})
+		Expect(config.getOwnerResolvers()).To(ConsistOf(&machineOwnerResolver{
+			machineAPIGroup: "machine.openshift.io",
+			apiVersion:      "scaling.example.com/v1",
+			kind:            "NodePool",
+			replicasPath:    "{.spec.size}",
+		}))
+
+		config.Load(nil)
+		Expect(config.getOwnerResolvers()).To(BeEmpty())
+	})
+})
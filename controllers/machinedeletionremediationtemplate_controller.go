@@ -0,0 +1,83 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	commonannotations "github.com/medik8s/common/pkg/annotations"
+
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/medik8s/machine-deletion-remediation/api/v1alpha1"
+)
+
+// MachineDeletionRemediationTemplateReconciler reconciles a MachineDeletionRemediationTemplate object
+type MachineDeletionRemediationTemplateReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=machine-deletion-remediation.medik8s.io,resources=machinedeletionremediationtemplates,verbs=get;list;watch;update;patch
+
+// Reconcile advertises MDR's support for multiple templates on every MachineDeletionRemediationTemplate, so that NHC
+// creates the remediation CRs with a generated name and the NodeNameAnnotation.
+func (r *MachineDeletionRemediationTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("machinedeletionremediationtemplate", req.NamespacedName)
+
+	template := &v1alpha1.MachineDeletionRemediationTemplate{}
+	if err := r.Get(ctx, req.NamespacedName, template); err != nil {
+		if apiErrors.IsNotFound(err) {
+			log.Info("MDR template already deleted, nothing to do")
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "could not get MDR template")
+		return ctrl.Result{}, err
+	}
+
+	annotations := template.GetAnnotations()
+	if annotations[commonannotations.MultipleTemplatesSupportedAnnotation] == "true" {
+		return ctrl.Result{}, nil
+	}
+
+	if annotations == nil {
+		annotations = make(map[string]string, 1)
+	}
+	annotations[commonannotations.MultipleTemplatesSupportedAnnotation] = "true"
+	template.SetAnnotations(annotations)
+
+	log.Info("adding multiple templates support annotation")
+	if err := r.Update(ctx, template); err != nil {
+		if !apiErrors.IsConflict(err) {
+			log.Error(err, "could not update MDR template")
+		}
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *MachineDeletionRemediationTemplateReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.MachineDeletionRemediationTemplate{}).
+		Complete(r)
+}
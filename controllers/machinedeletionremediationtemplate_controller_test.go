@@ -0,0 +1,68 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	commonannotations "github.com/medik8s/common/pkg/annotations"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/medik8s/machine-deletion-remediation/api/v1alpha1"
+)
+
+var _ = Describe("Machine Deletion Remediation Template CR", func() {
+	var template *v1alpha1.MachineDeletionRemediationTemplate
+
+	BeforeEach(func() {
+		template = &v1alpha1.MachineDeletionRemediationTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "template-test", Namespace: defaultNamespace},
+		}
+	})
+
+	JustBeforeEach(func() {
+		Expect(k8sClient.Create(context.Background(), template)).To(Succeed())
+		DeferCleanup(k8sClient.Delete, template)
+	})
+
+	When("a template is created", func() {
+		It("is annotated with multiple templates support", func() {
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(template), template)).To(Succeed())
+				g.Expect(template.GetAnnotations()).To(HaveKeyWithValue(commonannotations.MultipleTemplatesSupportedAnnotation, "true"))
+			}, "10s", "1s").Should(Succeed())
+		})
+	})
+
+	When("a template is created with other annotations", func() {
+		BeforeEach(func() {
+			template.SetAnnotations(map[string]string{"foo": "bar"})
+		})
+
+		It("keeps the existing annotations", func() {
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(template), template)).To(Succeed())
+				g.Expect(template.GetAnnotations()).To(HaveKeyWithValue(commonannotations.MultipleTemplatesSupportedAnnotation, "true"))
+				g.Expect(template.GetAnnotations()).To(HaveKeyWithValue("foo", "bar"))
+			}, "10s", "1s").Should(Succeed())
+		})
+	})
+})
@@ -0,0 +1,190 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	commonevents "github.com/medik8s/common/pkg/events"
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/medik8s/machine-deletion-remediation/api/v1alpha1"
+)
+
+const (
+	remediationDeferredReason = "RemediationDeferred"
+
+	remediationDeferredMsg           = "deletion of machine %s deferred to the next maintenance window, starting at %s"
+	maintenanceWindowStartedMsg      = "maintenance window started, resuming the deletion of machine %s"
+	invalidMaintenanceWindowErrorMsg = "invalid maintenance window"
+)
+
+var invalidMaintenanceWindowError = errors.New(invalidMaintenanceWindowErrorMsg)
+
+// maintenanceWindow is a parsed MaintenanceWindow
+type maintenanceWindow struct {
+	schedule *cronSchedule
+	duration time.Duration
+	location *time.Location
+}
+
+// parseMaintenanceWindow parses and validates the given maintenance window
+func parseMaintenanceWindow(window v1alpha1.MaintenanceWindow) (*maintenanceWindow, error) {
+	schedule, err := parseCronSchedule(window.Schedule)
+	if err != nil {
+		return nil, err
+	}
+	if window.Duration.Duration <= 0 {
+		return nil, fmt.Errorf("invalid duration %s: must be positive", window.Duration.Duration)
+	}
+	location := time.UTC
+	if window.TimeZone != "" {
+		if location, err = time.LoadLocation(window.TimeZone); err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %v", window.TimeZone, err)
+		}
+	}
+	if schedule.next(time.Now().In(location)).IsZero() {
+		return nil, fmt.Errorf("invalid schedule %q: it never matches", window.Schedule)
+	}
+	return &maintenanceWindow{schedule: schedule, duration: window.Duration.Duration, location: location}, nil
+}
+
+// isOpen checks if the window is open at the given time. If it is not, the start of the next window is returned too.
+func (w *maintenanceWindow) isOpen(now time.Time) (bool, time.Time) {
+	now = now.In(w.location)
+	// the first start after now-duration is either the start of the open window, or the start of the next one
+	start := w.schedule.next(now.Add(-w.duration))
+	if start.IsZero() || start.After(now) {
+		return false, start
+	}
+	return true, time.Time{}
+}
+
+// getMaintenanceWindows returns the maintenance windows restricting the remediation's Machine deletion: its own ones,
+// and the ones of the operator configuration selecting its Machine owner
+func (r *MachineDeletionRemediationReconciler) getMaintenanceWindows(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation, config v1alpha1.MachineDeletionRemediationConfigSpec) ([]*maintenanceWindow, error) {
+	var windows []*maintenanceWindow
+	for i, window := range remediation.Spec.MaintenanceWindows {
+		parsed, err := parseMaintenanceWindow(window)
+		if err != nil {
+			return nil, errors.Wrap(invalidMaintenanceWindowError, fmt.Sprintf("maintenanceWindows[%d]: %v", i, err))
+		}
+		windows = append(windows, parsed)
+	}
+	if len(config.MaintenanceWindows) == 0 {
+		return windows, nil
+	}
+
+	ownerLabels, err := r.getMachineOwnerLabels(ctx, remediation)
+	if err != nil {
+		return nil, err
+	}
+	for _, window := range config.MaintenanceWindows {
+		if window.MachineOwnerSelector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(window.MachineOwnerSelector)
+			if err != nil || !selector.Matches(ownerLabels) {
+				continue
+			}
+		}
+		// the operator configuration's windows are validated already
+		if parsed, err := parseMaintenanceWindow(window.MaintenanceWindow); err == nil {
+			windows = append(windows, parsed)
+		}
+	}
+	return windows, nil
+}
+
+// getMachineOwnerLabels returns the labels of the remediation's Machine owner. Unknown or missing owners have no labels.
+func (r *MachineDeletionRemediationReconciler) getMachineOwnerLabels(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation) (labels.Set, error) {
+	owner := remediation.Status.MachineOwner
+	if owner == nil {
+		return labels.Set{}, nil
+	}
+	backend, err := r.getMachineBackendFromStatus(remediation)
+	if err != nil {
+		return nil, err
+	}
+	ownerObj, err := r.getMachineOwner(ctx, backend, owner.Kind, owner.Name, owner.Namespace)
+	if err != nil {
+		if errors.Cause(err) == unrecoverableError {
+			r.Log.Info("could not get Machine owner to select its maintenance windows", "kind", owner.Kind, "name", owner.Name, "error", err.Error())
+			return labels.Set{}, nil
+		}
+		return nil, err
+	}
+	return ownerObj.GetLabels(), nil
+}
+
+// deferToMaintenanceWindow checks if the Machine can be deleted now, i.e. when no maintenance window restricts its
+// deletion or one of them is open. Otherwise, the remediation is deferred to the start of the next window, which is
+// reported in its status, and the time to wait until then is returned.
+func (r *MachineDeletionRemediationReconciler) deferToMaintenanceWindow(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation, machine client.Object, config v1alpha1.MachineDeletionRemediationConfigSpec) (time.Duration, error) {
+	windows, err := r.getMaintenanceWindows(ctx, remediation, config)
+	if err != nil || len(windows) == 0 {
+		return 0, err
+	}
+
+	now := time.Now()
+	var nextStart time.Time
+	for _, window := range windows {
+		open, start := window.isOpen(now)
+		if open {
+			if isDeferred(remediation) {
+				msg := fmt.Sprintf(maintenanceWindowStartedMsg, machine.GetName())
+				r.Log.Info(msg)
+				setDeferredCondition(remediation, metav1.ConditionFalse, v1alpha1.InsideMaintenanceWindowReason, msg)
+			}
+			remediation.Status.NextMaintenanceWindowStart = nil
+			return 0, nil
+		}
+		if !start.IsZero() && (nextStart.IsZero() || start.Before(nextStart)) {
+			nextStart = start
+		}
+	}
+
+	next := metav1.NewTime(nextStart)
+	remediation.Status.NextMaintenanceWindowStart = &next
+	msg := fmt.Sprintf(remediationDeferredMsg, machine.GetName(), nextStart.Format(time.RFC3339))
+	if !isDeferred(remediation) {
+		r.Log.Info(msg)
+		commonevents.NormalEvent(r.Recorder, remediation, remediationDeferredReason, msg)
+	}
+	setDeferredCondition(remediation, metav1.ConditionTrue, v1alpha1.OutsideMaintenanceWindowReason, msg)
+	return time.Until(nextStart), nil
+}
+
+// isDeferred checks if the remediation waits for the next maintenance window to delete the Machine
+func isDeferred(remediation *v1alpha1.MachineDeletionRemediation) bool {
+	return meta.IsStatusConditionTrue(remediation.Status.Conditions, v1alpha1.DeferredConditionType)
+}
+
+// setDeferredCondition sets the Deferred condition
+func setDeferredCondition(remediation *v1alpha1.MachineDeletionRemediation, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&remediation.Status.Conditions, metav1.Condition{
+		Type:    v1alpha1.DeferredConditionType,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/medik8s/machine-deletion-remediation/api/v1alpha1"
+)
+
+var _ = Describe("Maintenance windows", func() {
+	// Wednesday
+	now := time.Date(2024, time.May, 15, 10, 30, 0, 0, time.UTC)
+
+	DescribeTable("next cron schedule time",
+		func(expression string, after, expected time.Time) {
+			schedule, err := parseCronSchedule(expression)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(schedule.next(after)).To(Equal(expected))
+		},
+		Entry("every minute", "* * * * *", now, now.Add(time.Minute)),
+		Entry("later today", "0 22 * * *", now, time.Date(2024, time.May, 15, 22, 0, 0, 0, time.UTC)),
+		Entry("tomorrow", "15 9 * * *", now, time.Date(2024, time.May, 16, 9, 15, 0, 0, time.UTC)),
+		Entry("step", "*/20 * * * *", now, time.Date(2024, time.May, 15, 10, 40, 0, 0, time.UTC)),
+		Entry("range with step", "0 8-18/4 * * *", now, time.Date(2024, time.May, 15, 12, 0, 0, 0, time.UTC)),
+		Entry("day of week by name", "0 2 * * SAT", now, time.Date(2024, time.May, 18, 2, 0, 0, 0, time.UTC)),
+		Entry("Sunday as 7", "0 2 * * 7", now, time.Date(2024, time.May, 19, 2, 0, 0, 0, time.UTC)),
+		Entry("list of months", "0 0 1 JAN,JUL *", now, time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC)),
+		Entry("day of month or day of week", "0 0 17 * MON", now, time.Date(2024, time.May, 17, 0, 0, 0, 0, time.UTC)),
+		Entry("leap day", "0 0 29 2 *", now, time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)),
+		Entry("strictly after a matching time", "30 10 * * *", now, time.Date(2024, time.May, 16, 10, 30, 0, 0, time.UTC)),
+	)
+
+	DescribeTable("invalid cron schedules",
+		func(expression string) {
+			_, err := parseCronSchedule(expression)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("too few fields", "0 2 * *"),
+		Entry("too many fields", "0 0 2 * * *"),
+		Entry("out of range value", "60 * * * *"),
+		Entry("unknown name", "0 0 * * FUN"),
+		Entry("inverted range", "0 10-8 * * *"),
+		Entry("zero step", "*/0 * * * *"),
+	)
+
+	It("rejects schedules which never match", func() {
+		_, err := parseMaintenanceWindow(v1alpha1.MaintenanceWindow{Schedule: "0 0 30 2 *", Duration: metav1.Duration{Duration: time.Hour}})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("computes the schedule in the window's time zone", func() {
+		window, err := parseMaintenanceWindow(v1alpha1.MaintenanceWindow{
+			Schedule: "0 2 * * *",
+			Duration: metav1.Duration{Duration: 2 * time.Hour},
+			TimeZone: "America/New_York",
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		// 2:00 EDT is 6:00 UTC
+		open, next := window.isOpen(now)
+		Expect(open).To(BeFalse())
+		Expect(next.UTC()).To(Equal(time.Date(2024, time.May, 16, 6, 0, 0, 0, time.UTC)))
+
+		open, _ = window.isOpen(time.Date(2024, time.May, 16, 7, 59, 0, 0, time.UTC))
+		Expect(open).To(BeTrue())
+		open, _ = window.isOpen(time.Date(2024, time.May, 16, 8, 0, 0, 0, time.UTC))
+		Expect(open).To(BeFalse())
+	})
+})
@@ -0,0 +1,214 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	commonconditions "github.com/medik8s/common/pkg/conditions"
+	"github.com/pkg/errors"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/medik8s/machine-deletion-remediation/api/v1alpha1"
+)
+
+const (
+	// MaxInFlightAnnotation can be set on a Machine owner (e.g. a MachineSet) to limit the number of its Machines being
+	// deleted at the same time. The value is either an absolute number or a percentage of the owner's replicas.
+	MaxInFlightAnnotation = "machine-deletion-remediation.medik8s.io/max-in-flight"
+
+	operatorMaxInFlightReachedMsg = "waiting for other remediations to complete: %d Machines are being deleted or queued, while the maximum is %d"
+	ownerMaxInFlightReachedMsg    = "waiting for other remediations to complete: %d Machines of %s %s are being deleted or queued, while the maximum is %d"
+)
+
+// ParseMaxInFlight parses a maximum number of Machines being deleted at the same time, either an absolute number
+// (e.g. "3") or a percentage (e.g. "10%"). It returns nil if the value is empty, meaning that there is no limit.
+func ParseMaxInFlight(value string) (*intstr.IntOrString, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	maxInFlight := intstr.Parse(value)
+	if maxInFlight.Type == intstr.Int {
+		if maxInFlight.IntVal < 0 {
+			return nil, fmt.Errorf("invalid max in flight %q: must not be negative", value)
+		}
+		return &maxInFlight, nil
+	}
+
+	percentage, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+	if !strings.HasSuffix(value, "%") || err != nil || percentage < 0 || percentage > 100 {
+		return nil, fmt.Errorf("invalid max in flight %q: must be a number or a percentage between 0%% and 100%%", value)
+	}
+	return &maxInFlight, nil
+}
+
+// getMaxInFlightLimit returns the maximum number of Machines being deleted at the same time, given the maximum in flight
+// value and the total the percentages refer to. Percentages are rounded up, so that at least one Machine can be
+// deleted unless the percentage is 0.
+func getMaxInFlightLimit(maxInFlight *intstr.IntOrString, total int) (int, error) {
+	return intstr.GetScaledValueFromIntOrPercent(maxInFlight, total, true)
+}
+
+// isMaxInFlightReached checks if the remediation has to wait before deleting its Machine, because of the operator wide
+// limit (a percentage of the cluster's Nodes) or of the Machine owner's MaxInFlightAnnotation (a percentage of its
+// replicas). Both the Machines being deleted and the remediations queued before the given one, in creation order, count
+// towards the limits. A message describing the reached limit is returned together with the result.
+func (r *MachineDeletionRemediationReconciler) isMaxInFlightReached(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation) (bool, string, error) {
+	ownerMaxInFlight, err := r.getOwnerMaxInFlight(ctx, remediation)
+	if err != nil {
+		return false, "", err
+	}
+	maxInFlight := r.getConfig().MaxInFlight
+	if maxInFlight == nil && ownerMaxInFlight == nil {
+		return false, "", nil
+	}
+
+	remediations := &v1alpha1.MachineDeletionRemediationList{}
+	if err := r.List(ctx, remediations); err != nil {
+		return false, "", err
+	}
+
+	var inFlight, ownerInFlight int
+	for i := range remediations.Items {
+		other := &remediations.Items[i]
+		if other.GetUID() == remediation.GetUID() || !isInFlight(other, remediation) {
+			continue
+		}
+		inFlight++
+		if isSameMachineOwner(other, remediation) {
+			ownerInFlight++
+		}
+	}
+
+	if maxInFlight != nil {
+		nodes := &v1.NodeList{}
+		if err := r.List(ctx, nodes); err != nil {
+			return false, "", err
+		}
+		limit, err := getMaxInFlightLimit(maxInFlight, len(nodes.Items))
+		if err != nil {
+			return false, "", err
+		}
+		if inFlight >= limit {
+			return true, fmt.Sprintf(operatorMaxInFlightReachedMsg, inFlight, limit), nil
+		}
+	}
+
+	if ownerMaxInFlight != nil {
+		owner := remediation.Status.MachineOwner
+		backend, err := r.getMachineBackendFromStatus(remediation)
+		if err != nil {
+			return false, "", err
+		}
+		replicas, err := r.getMachineOwnerSpecReplicas(ctx, backend, owner.Kind, owner.Name, owner.Namespace)
+		if err != nil {
+			return false, "", err
+		}
+		limit, err := getMaxInFlightLimit(ownerMaxInFlight, replicas)
+		if err != nil {
+			return false, "", err
+		}
+		if ownerInFlight >= limit {
+			return true, fmt.Sprintf(ownerMaxInFlightReachedMsg, ownerInFlight, owner.Kind, owner.Name, limit), nil
+		}
+	}
+	return false, "", nil
+}
+
+// getOwnerMaxInFlight returns the value of the Machine owner's MaxInFlightAnnotation, or nil if it is not set
+func (r *MachineDeletionRemediationReconciler) getOwnerMaxInFlight(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation) (*intstr.IntOrString, error) {
+	owner := remediation.Status.MachineOwner
+	if owner == nil {
+		return nil, nil
+	}
+
+	backend, err := r.getMachineBackendFromStatus(remediation)
+	if err != nil {
+		return nil, err
+	}
+	ownerObj, err := r.getMachineOwner(ctx, backend, owner.Kind, owner.Name, owner.Namespace)
+	if err != nil {
+		if errors.Cause(err) == unrecoverableError {
+			// unknown or missing owners do not limit the remediation
+			r.Log.Info("could not get Machine owner to verify its max in flight", "kind", owner.Kind, "name", owner.Name, "error", err.Error())
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	value, exists := ownerObj.GetAnnotations()[MaxInFlightAnnotation]
+	if !exists {
+		return nil, nil
+	}
+	maxInFlight, err := ParseMaxInFlight(value)
+	if err != nil {
+		r.Log.Error(err, "ignoring invalid annotation on Machine owner", "annotation", MaxInFlightAnnotation, "kind", owner.Kind, "name", owner.Name)
+		return nil, nil
+	}
+	return maxInFlight, nil
+}
+
+// setWaitingCondition sets the Waiting condition, and returns true if its status changed
+func setWaitingCondition(remediation *v1alpha1.MachineDeletionRemediation, status metav1.ConditionStatus, reason, message string) bool {
+	statusChanged := !meta.IsStatusConditionPresentAndEqual(remediation.Status.Conditions, v1alpha1.WaitingConditionType, status)
+	meta.SetStatusCondition(&remediation.Status.Conditions, metav1.Condition{
+		Type:    v1alpha1.WaitingConditionType,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+	return statusChanged
+}
+
+// isInFlight checks if the other remediation holds a slot of the remediations in flight, when the given one is
+// reconciled. Remediations blocked for other reasons, or handed off to their fallback remediation, do not delete any
+// Machine, and must not hold the queue.
+func isInFlight(other, remediation *v1alpha1.MachineDeletionRemediation) bool {
+	if meta.IsStatusConditionPresentAndEqual(other.Status.Conditions, commonconditions.ProcessingType, metav1.ConditionFalse) {
+		return false
+	}
+	if other.Status.MachineDeletionRequestedTime != nil {
+		return true
+	}
+	return isCreatedBefore(other, remediation) &&
+		!isConditionReason(other, commonconditions.ProcessingType, remediationBlockedQuorumAtRisk) &&
+		!isAwaitingApproval(other) && !isDeferred(other) && !isFallbackRemediationStarted(other)
+}
+
+// isCreatedBefore checks if the remediation was created before the other one. Remediations created at the same time
+// are sorted by name.
+func isCreatedBefore(remediation, other *v1alpha1.MachineDeletionRemediation) bool {
+	created, otherCreated := remediation.GetCreationTimestamp(), other.GetCreationTimestamp()
+	if created.Equal(&otherCreated) {
+		return remediation.GetName() < other.GetName()
+	}
+	return created.Before(&otherCreated)
+}
+
+// isSameMachineOwner checks if the Machines targeted by the two remediations have the same owner
+func isSameMachineOwner(remediation, other *v1alpha1.MachineDeletionRemediation) bool {
+	return remediation.Status.MachineOwner != nil && other.Status.MachineOwner != nil &&
+		*remediation.Status.MachineOwner == *other.Status.MachineOwner
+}
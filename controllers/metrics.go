@@ -0,0 +1,191 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	commonconditions "github.com/medik8s/common/pkg/conditions"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/medik8s/machine-deletion-remediation/api/v1alpha1"
+)
+
+const (
+	metricsNamespace = "machine_deletion_remediation"
+
+	ownerKindLabel    = "owner_kind"
+	providerTypeLabel = "provider_type"
+	reasonLabel       = "reason"
+
+	// unknownLabelValue is used when the Machine owner or provider are not known, e.g. because the Machine was not found
+	unknownLabelValue = "unknown"
+
+	// skippedReasonPrefix is the prefix of the reasons of the remediations completed without deleting the Machine
+	skippedReasonPrefix = "RemediationSkipped"
+)
+
+var (
+	remediationsStarted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "remediations_started_total",
+		Help:      "Number of remediations which requested the deletion of their Machine",
+	}, []string{ownerKindLabel, providerTypeLabel})
+
+	remediationsSucceeded = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "remediations_succeeded_total",
+		Help:      "Number of remediations which completed successfully",
+	}, []string{ownerKindLabel, providerTypeLabel})
+
+	remediationsFailed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "remediations_failed_total",
+		Help:      "Number of remediations which failed, by reason",
+	}, []string{ownerKindLabel, providerTypeLabel, reasonLabel})
+
+	remediationsSkipped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "remediations_skipped_total",
+		Help:      "Number of remediations which completed without deleting their Machine, by reason",
+	}, []string{ownerKindLabel, providerTypeLabel, reasonLabel})
+
+	remediationsInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "remediations_in_flight",
+		Help:      "Number of remediations which requested the deletion of their Machine and are not completed yet",
+	}, []string{ownerKindLabel, providerTypeLabel})
+
+	machineDeletionDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "machine_deletion_duration_seconds",
+		Help:      "Time from the remediation creation until its Machine is deleted",
+		Buckets:   prometheus.ExponentialBuckets(30, 2, 10),
+	}, []string{ownerKindLabel, providerTypeLabel})
+
+	nodeRestorationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "node_restoration_duration_seconds",
+		Help:      "Time from the remediation creation until the Node of the replacement Machine is Ready",
+		Buckets:   prometheus.ExponentialBuckets(30, 2, 10),
+	}, []string{ownerKindLabel, providerTypeLabel})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		remediationsStarted,
+		remediationsSucceeded,
+		remediationsFailed,
+		remediationsSkipped,
+		remediationsInFlight,
+		machineDeletionDuration,
+		nodeRestorationDuration,
+	)
+}
+
+// getMetricsLabels returns the owner kind and provider type labels of the remediation
+func getMetricsLabels(remediation *v1alpha1.MachineDeletionRemediation) prometheus.Labels {
+	ownerKind := unknownLabelValue
+	if owner := remediation.Status.MachineOwner; owner != nil && owner.Kind != "" {
+		ownerKind = owner.Kind
+	}
+	return prometheus.Labels{
+		ownerKindLabel:    ownerKind,
+		providerTypeLabel: getProviderType(remediation.Status.ProviderID),
+	}
+}
+
+// getProviderType returns the provider type of the given providerID, i.e. its scheme (e.g. "aws" for
+// "aws:///us-east-1a/i-0123456789")
+func getProviderType(providerID string) string {
+	providerType, _, found := strings.Cut(providerID, "://")
+	if !found || providerType == "" {
+		return unknownLabelValue
+	}
+	return providerType
+}
+
+// recordRemediationStarted records the Machine deletion request of the remediation
+func recordRemediationStarted(remediation *v1alpha1.MachineDeletionRemediation) {
+	remediationsStarted.With(getMetricsLabels(remediation)).Inc()
+}
+
+// recordRemediationProgress records the progress made by the remediation since its previous state was persisted: the
+// Machine deletion, the replacement Node readiness and the remediation outcome. It must be called only once the new
+// state is persisted, so that each step is recorded once.
+func recordRemediationProgress(previous, current *v1alpha1.MachineDeletionRemediation) {
+	labels := getMetricsLabels(current)
+	created := current.GetCreationTimestamp()
+
+	if previous.Status.MachineDeletedTime == nil && current.Status.MachineDeletedTime != nil {
+		machineDeletionDuration.With(labels).Observe(current.Status.MachineDeletedTime.Sub(created.Time).Seconds())
+	}
+	if previous.Status.ReplacementNodeReadyTime == nil && current.Status.ReplacementNodeReadyTime != nil {
+		nodeRestorationDuration.With(labels).Observe(current.Status.ReplacementNodeReadyTime.Sub(created.Time).Seconds())
+	}
+
+	if meta.IsStatusConditionFalse(previous.Status.Conditions, commonconditions.ProcessingType) ||
+		!meta.IsStatusConditionFalse(current.Status.Conditions, commonconditions.ProcessingType) {
+		// the outcome was already recorded, or is not known yet
+		return
+	}
+	succeeded := meta.FindStatusCondition(current.Status.Conditions, commonconditions.SucceededType)
+	if succeeded == nil {
+		return
+	}
+	switch {
+	case succeeded.Status == metav1.ConditionTrue:
+		remediationsSucceeded.With(labels).Inc()
+	case strings.HasPrefix(succeeded.Reason, skippedReasonPrefix):
+		// dry runs are skipped too, although their Succeeded condition stays Unknown
+		remediationsSkipped.With(withReason(labels, succeeded.Reason)).Inc()
+	case succeeded.Status == metav1.ConditionFalse:
+		remediationsFailed.With(withReason(labels, succeeded.Reason)).Inc()
+	}
+}
+
+func withReason(labels prometheus.Labels, reason string) prometheus.Labels {
+	withReason := prometheus.Labels{reasonLabel: reason}
+	for name, value := range labels {
+		withReason[name] = value
+	}
+	return withReason
+}
+
+// updateInFlightMetric counts the remediations which requested the deletion of their Machine and are not completed yet
+func (r *MachineDeletionRemediationReconciler) updateInFlightMetric(ctx context.Context) {
+	remediations := &v1alpha1.MachineDeletionRemediationList{}
+	if err := r.List(ctx, remediations); err != nil {
+		r.Log.Error(err, "could not list remediations to update the in flight metric")
+		return
+	}
+
+	remediationsInFlight.Reset()
+	for i := range remediations.Items {
+		remediation := &remediations.Items[i]
+		if remediation.Status.MachineDeletionRequestedTime == nil ||
+			meta.IsStatusConditionPresentAndEqual(remediation.Status.Conditions, commonconditions.ProcessingType, metav1.ConditionFalse) {
+			continue
+		}
+		remediationsInFlight.With(getMetricsLabels(remediation)).Inc()
+	}
+}
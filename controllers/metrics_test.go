@@ -0,0 +1,146 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"time"
+
+	commonconditions "github.com/medik8s/common/pkg/conditions"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/medik8s/machine-deletion-remediation/api/v1alpha1"
+)
+
+var _ = Describe("Metrics", func() {
+	var previous, current *v1alpha1.MachineDeletionRemediation
+	var labels prometheus.Labels
+
+	BeforeEach(func() {
+		previous = &v1alpha1.MachineDeletionRemediation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "metrics-test",
+				Namespace:         defaultNamespace,
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-10 * time.Minute)),
+			},
+		}
+		previous.Status.MachineOwner = &v1alpha1.ObjectReference{Kind: "MachineSet", Name: "metrics-test-ms"}
+		previous.Status.ProviderID = "aws:///us-east-1a/i-0123456789"
+		previous.Status.Conditions = []metav1.Condition{
+			{Type: commonconditions.ProcessingType, Status: metav1.ConditionTrue, Reason: string(remediationStarted)},
+			{Type: commonconditions.SucceededType, Status: metav1.ConditionUnknown, Reason: string(remediationStarted)},
+		}
+		current = previous.DeepCopy()
+		labels = prometheus.Labels{ownerKindLabel: "MachineSet", providerTypeLabel: "aws"}
+	})
+
+	It("registers the collectors in the controller-runtime registry", func() {
+		recordRemediationStarted(current)
+		families, err := metrics.Registry.Gather()
+		Expect(err).ToNot(HaveOccurred())
+		var names []string
+		for _, family := range families {
+			names = append(names, family.GetName())
+		}
+		Expect(names).To(ContainElement("machine_deletion_remediation_remediations_started_total"))
+	})
+
+	DescribeTable("provider type",
+		func(providerID, expected string) {
+			Expect(getProviderType(providerID)).To(Equal(expected))
+		},
+		Entry("cloud provider", "aws:///us-east-1a/i-0123456789", "aws"),
+		Entry("bare metal provider", "baremetalhost:///openshift-machine-api/worker-0/uid", "baremetalhost"),
+		Entry("empty providerID", "", unknownLabelValue),
+		Entry("providerID without scheme", "i-0123456789", unknownLabelValue),
+	)
+
+	It("uses unknown labels when the Machine data is not known", func() {
+		current.Status.MachineOwner = nil
+		current.Status.ProviderID = ""
+		Expect(getMetricsLabels(current)).To(Equal(prometheus.Labels{ownerKindLabel: unknownLabelValue, providerTypeLabel: unknownLabelValue}))
+	})
+
+	It("records a started remediation", func() {
+		before := getCounterValue(remediationsStarted, labels)
+		recordRemediationStarted(current)
+		Expect(getCounterValue(remediationsStarted, labels)).To(Equal(before + 1))
+	})
+
+	It("records the Machine deletion and Node restoration durations once", func() {
+		deletionsBefore := getHistogramCount(machineDeletionDuration, labels)
+		restorationsBefore := getHistogramCount(nodeRestorationDuration, labels)
+
+		current.Status.MachineDeletedTime = &metav1.Time{Time: time.Now().Add(-5 * time.Minute)}
+		recordRemediationProgress(previous, current)
+		Expect(getHistogramCount(machineDeletionDuration, labels)).To(Equal(deletionsBefore + 1))
+		Expect(getHistogramCount(nodeRestorationDuration, labels)).To(Equal(restorationsBefore))
+
+		previous = current.DeepCopy()
+		current.Status.ReplacementNodeReadyTime = &metav1.Time{Time: time.Now()}
+		recordRemediationProgress(previous, current)
+		Expect(getHistogramCount(machineDeletionDuration, labels)).To(Equal(deletionsBefore + 1))
+		Expect(getHistogramCount(nodeRestorationDuration, labels)).To(Equal(restorationsBefore + 1))
+	})
+
+	DescribeTable("remediation outcome",
+		func(status metav1.ConditionStatus, reason conditionChangeReason, counter *prometheus.CounterVec, withReasonLabel bool) {
+			expectedLabels := labels
+			if withReasonLabel {
+				expectedLabels = withReason(labels, string(reason))
+			}
+			before := getCounterValue(counter, expectedLabels)
+
+			current.Status.Conditions[0] = metav1.Condition{Type: commonconditions.ProcessingType, Status: metav1.ConditionFalse, Reason: string(reason)}
+			current.Status.Conditions[1] = metav1.Condition{Type: commonconditions.SucceededType, Status: status, Reason: string(reason)}
+			recordRemediationProgress(previous, current)
+			Expect(getCounterValue(counter, expectedLabels)).To(Equal(before + 1))
+
+			By("not recording the outcome again")
+			recordRemediationProgress(current.DeepCopy(), current)
+			Expect(getCounterValue(counter, expectedLabels)).To(Equal(before + 1))
+		},
+		Entry("succeeded", metav1.ConditionTrue, remediationFinishedMachineDeleted, remediationsSucceeded, false),
+		Entry("failed", metav1.ConditionFalse, remediationNodeRestorationTimedOut, remediationsFailed, true),
+		Entry("stopped by NHC", metav1.ConditionFalse, remediationTimedOutByNhc, remediationsFailed, true),
+		Entry("skipped", metav1.ConditionFalse, remediationSkippedNoControllerOwner, remediationsSkipped, true),
+		Entry("dry run", metav1.ConditionUnknown, remediationSkippedDryRun, remediationsSkipped, true),
+	)
+})
+
+func getCounterValue(counter *prometheus.CounterVec, labels prometheus.Labels) float64 {
+	metric := &dto.Metric{}
+	ExpectWithOffset(1, counter.With(labels).Write(metric)).To(Succeed())
+	return metric.GetCounter().GetValue()
+}
+
+func getGaugeValue(gauge *prometheus.GaugeVec, labels prometheus.Labels) float64 {
+	metric := &dto.Metric{}
+	ExpectWithOffset(1, gauge.With(labels).Write(metric)).To(Succeed())
+	return metric.GetGauge().GetValue()
+}
+
+func getHistogramCount(histogram *prometheus.HistogramVec, labels prometheus.Labels) uint64 {
+	metric := &dto.Metric{}
+	ExpectWithOffset(1, histogram.With(labels).(prometheus.Metric).Write(metric)).To(Succeed())
+	return metric.GetHistogram().GetSampleCount()
+}
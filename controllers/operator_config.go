@@ -0,0 +1,215 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/medik8s/machine-deletion-remediation/api/v1alpha1"
+)
+
+const (
+	// DefaultLeaderElectionID is the leader election ID used when the MachineDeletionRemediationConfig does not set it
+	DefaultLeaderElectionID = "285d4098.example.com"
+
+	defaultPollInterval                = 30 * time.Second
+	defaultStatusUpdateRequeueInterval = time.Second
+	// configNotLoadedRequeueInterval is the interval between two checks of the configuration being loaded
+	configNotLoadedRequeueInterval = time.Second
+)
+
+var (
+	// supportedOwnerKinds are the Kinds of the built-in Machine owners, see builtinOwnerResolvers
+	supportedOwnerKinds = []string{"MachineSet", "ControlPlaneMachineSet", "MachineDeployment", "KubeadmControlPlane"}
+
+	defaultBareMetalProviderIDPrefixes = []string{"baremetal"}
+)
+
+// OperatorConfig holds the effective operator configuration. It is updated by the MachineDeletionRemediationConfig
+// controller, and read by the other controllers at every reconciliation, so that configuration changes are applied
+// without restarting the operator.
+type OperatorConfig struct {
+	mutex sync.RWMutex
+	spec  v1alpha1.MachineDeletionRemediationConfigSpec
+	// loaded is set once the configuration of the MachineDeletionRemediationConfig, or its absence, is applied
+	loaded bool
+	// ownerResolvers are the resolvers of the owner Kinds of the configuration, built when it changes
+	ownerResolvers []*machineOwnerResolver
+}
+
+// NewOperatorConfig returns an OperatorConfig with the default values
+func NewOperatorConfig() *OperatorConfig {
+	spec, _ := getEffectiveConfig(v1alpha1.MachineDeletionRemediationConfigSpec{})
+	return &OperatorConfig{spec: spec}
+}
+
+// Get returns a copy of the effective configuration
+func (c *OperatorConfig) Get() v1alpha1.MachineDeletionRemediationConfigSpec {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return *c.spec.DeepCopy()
+}
+
+// IsLoaded checks if the configuration was loaded from the MachineDeletionRemediationConfig. Until then, the defaults
+// are returned, which may be less restrictive than the configured values, e.g. dry run.
+func (c *OperatorConfig) IsLoaded() bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.loaded
+}
+
+// Load applies the effective configuration of the given MachineDeletionRemediationConfig, or the default one if it is
+// nil. It is used to load the configuration before the manager and the MachineDeletionRemediationConfig controller
+// are started.
+func (c *OperatorConfig) Load(config *v1alpha1.MachineDeletionRemediationConfig) {
+	spec := v1alpha1.MachineDeletionRemediationConfigSpec{}
+	if config != nil {
+		spec = config.Spec
+	}
+	effective, _ := getEffectiveConfig(spec)
+	c.set(effective)
+}
+
+func (c *OperatorConfig) set(spec v1alpha1.MachineDeletionRemediationConfigSpec) {
+	var ownerResolvers []*machineOwnerResolver
+	for _, ownerKind := range spec.OwnerKinds {
+		// the owner Kinds of the effective configuration are validated already
+		if resolver, err := newMachineOwnerResolver(ownerKind); err == nil {
+			ownerResolvers = append(ownerResolvers, resolver)
+		}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.spec = *spec.DeepCopy()
+	c.ownerResolvers = ownerResolvers
+	c.loaded = true
+}
+
+// getOwnerResolvers returns the resolvers of the owner Kinds of the configuration. They must not be modified.
+func (c *OperatorConfig) getOwnerResolvers() []*machineOwnerResolver {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.ownerResolvers
+}
+
+// GetLeaderElectionID returns the leader election ID set in the given MachineDeletionRemediationConfig, or the default
+// one if it is not set or invalid
+func GetLeaderElectionID(config *v1alpha1.MachineDeletionRemediationConfig) string {
+	if config == nil {
+		return DefaultLeaderElectionID
+	}
+	spec, _ := getEffectiveConfig(config.Spec)
+	return spec.LeaderElectionID
+}
+
+// getEffectiveConfig validates the given spec and returns it with the defaults applied to missing and invalid fields,
+// together with the validation errors
+func getEffectiveConfig(spec v1alpha1.MachineDeletionRemediationConfigSpec) (v1alpha1.MachineDeletionRemediationConfigSpec, []string) {
+	effective := *spec.DeepCopy()
+	var validationErrors []string
+
+	if effective.DefaultPollInterval == nil {
+		effective.DefaultPollInterval = &metav1.Duration{Duration: defaultPollInterval}
+	} else if effective.DefaultPollInterval.Duration <= 0 {
+		validationErrors = append(validationErrors, fmt.Sprintf("defaultPollInterval: must be positive, got %s", effective.DefaultPollInterval.Duration))
+		effective.DefaultPollInterval = &metav1.Duration{Duration: defaultPollInterval}
+	}
+
+	if effective.StatusUpdateRequeueInterval == nil {
+		effective.StatusUpdateRequeueInterval = &metav1.Duration{Duration: defaultStatusUpdateRequeueInterval}
+	} else if effective.StatusUpdateRequeueInterval.Duration <= 0 {
+		validationErrors = append(validationErrors, fmt.Sprintf("statusUpdateRequeueInterval: must be positive, got %s", effective.StatusUpdateRequeueInterval.Duration))
+		effective.StatusUpdateRequeueInterval = &metav1.Duration{Duration: defaultStatusUpdateRequeueInterval}
+	}
+
+	if effective.LeaderElectionID == "" {
+		effective.LeaderElectionID = DefaultLeaderElectionID
+	} else if errs := validation.IsDNS1123Subdomain(effective.LeaderElectionID); len(errs) > 0 {
+		validationErrors = append(validationErrors, fmt.Sprintf("leaderElectionID: %v", errs))
+		effective.LeaderElectionID = DefaultLeaderElectionID
+	}
+
+	if effective.MaxInFlight != nil {
+		if _, err := ParseMaxInFlight(effective.MaxInFlight.String()); err != nil {
+			validationErrors = append(validationErrors, fmt.Sprintf("maxInFlight: %v", err))
+			effective.MaxInFlight = nil
+		}
+	}
+
+	supported := append([]string{}, supportedOwnerKinds...)
+	if effective.OwnerKinds != nil {
+		valid := []v1alpha1.MachineOwnerKind{}
+		for i, ownerKind := range effective.OwnerKinds {
+			resolver, err := newMachineOwnerResolver(ownerKind)
+			if err != nil {
+				validationErrors = append(validationErrors, fmt.Sprintf("ownerKinds[%d].%v", i, err))
+				continue
+			}
+			ownerKind.MachineAPIGroup, ownerKind.ReplicasPath = resolver.machineAPIGroup, resolver.replicasPath
+			valid = append(valid, ownerKind)
+			if !slices.Contains(supported, ownerKind.Kind) {
+				supported = append(supported, ownerKind.Kind)
+			}
+		}
+		effective.OwnerKinds = valid
+	}
+
+	if effective.EnabledOwnerKinds == nil {
+		effective.EnabledOwnerKinds = supported
+	} else {
+		enabled := []string{}
+		for _, kind := range effective.EnabledOwnerKinds {
+			if !slices.Contains(supported, kind) {
+				validationErrors = append(validationErrors, fmt.Sprintf("enabledOwnerKinds: unsupported Kind %q, supported Kinds are %v", kind, supported))
+				continue
+			}
+			enabled = append(enabled, kind)
+		}
+		effective.EnabledOwnerKinds = enabled
+	}
+
+	if effective.BareMetalProviderIDPrefixes == nil {
+		effective.BareMetalProviderIDPrefixes = append([]string{}, defaultBareMetalProviderIDPrefixes...)
+	}
+
+	if effective.MaintenanceWindows != nil {
+		valid := []v1alpha1.MachineOwnerMaintenanceWindow{}
+		for i, window := range effective.MaintenanceWindows {
+			if _, err := parseMaintenanceWindow(window.MaintenanceWindow); err != nil {
+				validationErrors = append(validationErrors, fmt.Sprintf("maintenanceWindows[%d]: %v", i, err))
+				continue
+			}
+			if window.MachineOwnerSelector != nil {
+				if _, err := metav1.LabelSelectorAsSelector(window.MachineOwnerSelector); err != nil {
+					validationErrors = append(validationErrors, fmt.Sprintf("maintenanceWindows[%d].machineOwnerSelector: %v", i, err))
+					continue
+				}
+			}
+			valid = append(valid, window)
+		}
+		effective.MaintenanceWindows = valid
+	}
+
+	return effective, validationErrors
+}
@@ -0,0 +1,265 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// infrastructureName is the name of the OpenShift Infrastructure CR describing the cluster's platform
+	infrastructureName = "cluster"
+)
+
+// PlatformInfo describes the platform a Machine runs on, as seen by the PlatformDetectors
+type PlatformInfo struct {
+	// ProviderID is the Machine's providerID, e.g. "aws:///us-east-1a/i-0123456789abcdef0"
+	ProviderID string
+	// ProviderSpecKind is the Kind of the Machine's providerSpec (e.g. "AWSMachineProviderConfig"), or of its
+	// infrastructure reference for Cluster API Machines (e.g. "AWSMachine")
+	ProviderSpecKind string
+	// PlatformType is the platform type of the cluster's Infrastructure CR on OpenShift, e.g. "AWS"
+	PlatformType string
+	// BareMetalHost is the Metal3 BareMetalHost of the Machine, as namespace/name, from its metal3.io/BareMetalHost
+	// annotation
+	BareMetalHost string
+	// BareMetalHostExternallyProvisioned is true if the Machine's BareMetalHost is provisioned outside of Metal3
+	BareMetalHostExternallyProvisioned bool
+	// MachineName is the name of the Machine
+	MachineName string
+	// NodeName is the name of the Machine's Node, or an empty string if the Machine has no Node
+	NodeName string
+}
+
+// NodeNameExpectation tells whether the Node of a Machine replacing a deleted one is expected to keep the name of the
+// deleted Machine's Node
+type NodeNameExpectation string
+
+const (
+	// NodeNameKept means that the replacement Node is expected to keep the name of the deleted one
+	NodeNameKept NodeNameExpectation = "Kept"
+	// NodeNameChanged means that the replacement Node is expected to get a new name
+	NodeNameChanged NodeNameExpectation = "Changed"
+	// NodeNameUnknown means that the name of the replacement Node cannot be predicted
+	NodeNameUnknown NodeNameExpectation = "Unknown"
+)
+
+// PlatformDetector detects a platform, and tells if the Node of a Machine replacing a deleted one is expected to keep
+// the name of the deleted Machine's Node on that platform
+type PlatformDetector interface {
+	// Name returns the name of the platform
+	Name() string
+	// Detect checks if any of the given platform info identifies the detector's platform
+	Detect(platform PlatformInfo) bool
+	// GetNodeNameExpectation tells if the replacement Node keeps the name of the deleted one on the detected platform
+	GetNodeNameExpectation(platform PlatformInfo) NodeNameExpectation
+}
+
+// platformDetector detects a platform by the scheme of the providerID, the Kind of the providerSpec and the platform
+// type of the Infrastructure CR. The replacement Nodes get a new name on the detected platform.
+type platformDetector struct {
+	name              string
+	providerIDSchemes []string
+	providerSpecKinds []string
+	platformTypes     []configv1.PlatformType
+}
+
+func (d *platformDetector) Name() string {
+	return d.name
+}
+
+func (d *platformDetector) Detect(platform PlatformInfo) bool {
+	return slices.Contains(d.providerIDSchemes, getProviderType(platform.ProviderID)) ||
+		slices.Contains(d.providerSpecKinds, platform.ProviderSpecKind) ||
+		slices.Contains(d.platformTypes, configv1.PlatformType(platform.PlatformType))
+}
+
+func (d *platformDetector) GetNodeNameExpectation(_ PlatformInfo) NodeNameExpectation {
+	return NodeNameChanged
+}
+
+// machineNamedPlatformDetector detects the platforms naming the VMs after their Machine, e.g. vSphere, Nutanix and
+// OpenStack, where the Node is named after the VM's hostname. The replacement Machine, and so its Node, gets a new
+// name, unless the Node is not named after its Machine: its hostname is then set outside of the Machine, e.g. by a
+// static hostname on vSphere or a hostname hint on OpenStack, and the name of the replacement Node cannot be predicted.
+type machineNamedPlatformDetector struct {
+	platformDetector
+}
+
+func (d *machineNamedPlatformDetector) GetNodeNameExpectation(platform PlatformInfo) NodeNameExpectation {
+	if platform.NodeName == "" || platform.MachineName == "" {
+		return NodeNameChanged
+	}
+	// the Node can be named after the FQDN of the VM
+	if hostname, _, _ := strings.Cut(platform.NodeName, "."); hostname == platform.MachineName {
+		return NodeNameChanged
+	}
+	return NodeNameUnknown
+}
+
+// bareMetalPlatformDetector detects the bare metal platforms, i.e. Metal3 and OpenShift's bare metal, whose hosts are
+// re-provisioned with the same name. The providerID prefixes of the operator configuration, and the Machines
+// referencing a BareMetalHost, are detected as bare metal too. Metal3 does not deprovision nor re-provision externally
+// provisioned hosts, so the name of their replacement Node cannot be predicted.
+type bareMetalPlatformDetector struct {
+	platformDetector
+	providerIDPrefixes []string
+}
+
+func (d *bareMetalPlatformDetector) Detect(platform PlatformInfo) bool {
+	return d.platformDetector.Detect(platform) || platform.BareMetalHost != "" ||
+		(platform.ProviderID != "" && hasAnyPrefix(platform.ProviderID, d.providerIDPrefixes))
+}
+
+func (d *bareMetalPlatformDetector) GetNodeNameExpectation(platform PlatformInfo) NodeNameExpectation {
+	if platform.BareMetalHostExternallyProvisioned {
+		return NodeNameUnknown
+	}
+	return NodeNameKept
+}
+
+// newPlatformDetectors returns the built-in detectors, using the bare metal providerID prefixes of the operator
+// configuration
+func newPlatformDetectors(bareMetalProviderIDPrefixes []string) []PlatformDetector {
+	return []PlatformDetector{
+		&bareMetalPlatformDetector{
+			platformDetector: platformDetector{
+				name:              "BareMetal",
+				providerIDSchemes: []string{"baremetalhost", "metal3"},
+				providerSpecKinds: []string{"BareMetalMachineProviderSpec", "Metal3Machine"},
+				platformTypes:     []configv1.PlatformType{configv1.BareMetalPlatformType},
+			},
+			providerIDPrefixes: bareMetalProviderIDPrefixes,
+		},
+		&machineNamedPlatformDetector{platformDetector{
+			name:              "VSphere",
+			providerIDSchemes: []string{"vsphere"},
+			providerSpecKinds: []string{"VSphereMachineProviderSpec", "VSphereMachine"},
+			platformTypes:     []configv1.PlatformType{configv1.VSpherePlatformType},
+		}},
+		&machineNamedPlatformDetector{platformDetector{
+			name:              "Nutanix",
+			providerIDSchemes: []string{"nutanix"},
+			providerSpecKinds: []string{"NutanixMachineProviderConfig", "NutanixMachine"},
+			platformTypes:     []configv1.PlatformType{configv1.NutanixPlatformType},
+		}},
+		&machineNamedPlatformDetector{platformDetector{
+			name:              "OpenStack",
+			providerIDSchemes: []string{"openstack"},
+			providerSpecKinds: []string{"OpenstackProviderSpec", "OpenStackMachine"},
+			platformTypes:     []configv1.PlatformType{configv1.OpenStackPlatformType},
+		}},
+		&platformDetector{
+			name:              "AWS",
+			providerIDSchemes: []string{"aws"},
+			providerSpecKinds: []string{"AWSMachineProviderConfig", "AWSMachine"},
+			platformTypes:     []configv1.PlatformType{configv1.AWSPlatformType},
+		},
+		&platformDetector{
+			name:              "Azure",
+			providerIDSchemes: []string{"azure"},
+			providerSpecKinds: []string{"AzureMachineProviderSpec", "AzureMachine"},
+			platformTypes:     []configv1.PlatformType{configv1.AzurePlatformType},
+		},
+		&platformDetector{
+			name:              "GCP",
+			providerIDSchemes: []string{"gce"},
+			providerSpecKinds: []string{"GCPMachineProviderSpec", "GCPMachine"},
+			platformTypes:     []configv1.PlatformType{configv1.GCPPlatformType},
+		},
+		&platformDetector{
+			name:              "IBMCloud",
+			providerIDSchemes: []string{"ibm"},
+			providerSpecKinds: []string{"IBMCloudMachineProviderSpec", "IBMVPCMachine"},
+			platformTypes:     []configv1.PlatformType{configv1.IBMCloudPlatformType},
+		},
+		&platformDetector{
+			name:              "PowerVS",
+			providerIDSchemes: []string{"ibmpowervs"},
+			providerSpecKinds: []string{"PowerVSMachineProviderConfig", "IBMPowerVSMachine"},
+			platformTypes:     []configv1.PlatformType{configv1.PowerVSPlatformType},
+		},
+		&platformDetector{
+			name:              "AlibabaCloud",
+			providerIDSchemes: []string{"alicloud"},
+			providerSpecKinds: []string{"AlibabaCloudMachineProviderConfig"},
+			platformTypes:     []configv1.PlatformType{configv1.AlibabaCloudPlatformType},
+		},
+		&platformDetector{
+			name:              "KubeVirt",
+			providerIDSchemes: []string{"kubevirt"},
+			providerSpecKinds: []string{"KubevirtMachine"},
+			platformTypes:     []configv1.PlatformType{configv1.KubevirtPlatformType},
+		},
+		&platformDetector{
+			name:              "oVirt",
+			providerIDSchemes: []string{"ovirt"},
+			providerSpecKinds: []string{"OvirtMachineProviderSpec"},
+			platformTypes:     []configv1.PlatformType{configv1.OvirtPlatformType},
+		},
+	}
+}
+
+// detectPlatform returns the first detector detecting the platform. The most specific platform info is used first:
+// the providerID, then the BareMetalHost, then the providerSpec, and then the cluster's platform type.
+func detectPlatform(detectors []PlatformDetector, platform PlatformInfo) (PlatformDetector, bool) {
+	for _, info := range []PlatformInfo{
+		{ProviderID: platform.ProviderID},
+		{BareMetalHost: platform.BareMetalHost},
+		{ProviderSpecKind: platform.ProviderSpecKind},
+		{PlatformType: platform.PlatformType},
+	} {
+		if info == (PlatformInfo{}) {
+			continue
+		}
+		for _, detector := range detectors {
+			if detector.Detect(info) {
+				return detector, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// getPlatformDetectors returns the detectors set on the reconciler, followed by the built-in ones
+func (r *MachineDeletionRemediationReconciler) getPlatformDetectors(bareMetalProviderIDPrefixes []string) []PlatformDetector {
+	return append(slices.Clone(r.PlatformDetectors), newPlatformDetectors(bareMetalProviderIDPrefixes)...)
+}
+
+// getPlatformType returns the platform type of the cluster's Infrastructure CR, or an empty string if the cluster has
+// none, e.g. because it is not an OpenShift cluster
+func (r *MachineDeletionRemediationReconciler) getPlatformType(ctx context.Context) (string, error) {
+	infrastructure := &configv1.Infrastructure{}
+	if err := r.Get(ctx, client.ObjectKey{Name: infrastructureName}, infrastructure); err != nil {
+		if meta.IsNoMatchError(err) || apiErrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	if status := infrastructure.Status.PlatformStatus; status != nil && status.Type != "" {
+		return string(status.Type), nil
+	}
+	return string(infrastructure.Status.Platform), nil
+}
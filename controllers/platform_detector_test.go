@@ -0,0 +1,94 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// testPlatformDetector detects the platform of the providerIDs with the "test" scheme
+type testPlatformDetector struct{}
+
+func (d *testPlatformDetector) Name() string {
+	return "Test"
+}
+
+func (d *testPlatformDetector) Detect(platform PlatformInfo) bool {
+	return getProviderType(platform.ProviderID) == "test"
+}
+
+func (d *testPlatformDetector) GetNodeNameExpectation(_ PlatformInfo) NodeNameExpectation {
+	return NodeNameKept
+}
+
+var _ = Describe("Platform detection", func() {
+	DescribeTable("detects the platform",
+		func(detectors []PlatformDetector, platform PlatformInfo, expectedName string, expectedNodeName NodeNameExpectation) {
+			detector, detected := detectPlatform(append(detectors, newPlatformDetectors(defaultBareMetalProviderIDPrefixes)...), platform)
+			Expect(detected).To(BeTrue())
+			Expect(detector.Name()).To(Equal(expectedName))
+			Expect(detector.GetNodeNameExpectation(platform)).To(Equal(expectedNodeName))
+		},
+		Entry("AWS providerID", nil, PlatformInfo{ProviderID: "aws:///us-east-1a/i-0123456789"}, "AWS", NodeNameChanged),
+		Entry("vSphere providerID", nil, PlatformInfo{ProviderID: "vsphere://42156a2f-1c1d-4c3e-8f7a-0123456789ab"}, "VSphere", NodeNameChanged),
+		Entry("Nutanix providerID", nil, PlatformInfo{ProviderID: "nutanix://0123456789ab"}, "Nutanix", NodeNameChanged),
+		Entry("OpenStack providerID", nil, PlatformInfo{ProviderID: "openstack:///0123456789ab"}, "OpenStack", NodeNameChanged),
+		Entry("BareMetalHost providerID", nil, PlatformInfo{ProviderID: "baremetalhost:///openshift-machine-api/worker-0/uid"}, "BareMetal", NodeNameKept),
+		Entry("Metal3 providerID", nil, PlatformInfo{ProviderID: "metal3://openshift-machine-api/worker-0/uid"}, "BareMetal", NodeNameKept),
+		Entry("bare metal providerID prefix of the configuration", nil, PlatformInfo{ProviderID: "baremetal-0123456789ab"}, "BareMetal", NodeNameKept),
+		Entry("providerSpec Kind", nil, PlatformInfo{ProviderSpecKind: "BareMetalMachineProviderSpec"}, "BareMetal", NodeNameKept),
+		Entry("Cluster API infrastructure Kind", nil, PlatformInfo{ProviderSpecKind: "Metal3Machine"}, "BareMetal", NodeNameKept),
+		Entry("platform type", nil, PlatformInfo{PlatformType: "GCP"}, "GCP", NodeNameChanged),
+		Entry("providerSpec Kind before the platform type", nil,
+			PlatformInfo{ProviderSpecKind: "VSphereMachineProviderSpec", PlatformType: "BareMetal"}, "VSphere", NodeNameChanged),
+		Entry("providerID before the providerSpec Kind and the platform type", nil,
+			PlatformInfo{ProviderID: "baremetalhost:///openshift-machine-api/worker-0/uid", ProviderSpecKind: "AWSMachineProviderConfig", PlatformType: "AWS"}, "BareMetal", NodeNameKept),
+		Entry("unknown providerID scheme falls back to the providerSpec Kind", nil,
+			PlatformInfo{ProviderID: "example:///0123456789ab", ProviderSpecKind: "AzureMachineProviderSpec"}, "Azure", NodeNameChanged),
+		Entry("additional detector before the built-in ones", []PlatformDetector{&testPlatformDetector{}},
+			PlatformInfo{ProviderID: "test:///0123456789ab", PlatformType: "AWS"}, "Test", NodeNameKept),
+		Entry("vSphere Node named after its Machine", nil,
+			PlatformInfo{ProviderID: "vsphere://42156a2f", MachineName: "worker-abcde", NodeName: "worker-abcde"}, "VSphere", NodeNameChanged),
+		Entry("vSphere Node named after the FQDN of its Machine", nil,
+			PlatformInfo{ProviderID: "vsphere://42156a2f", MachineName: "worker-abcde", NodeName: "worker-abcde.example.com"}, "VSphere", NodeNameChanged),
+		Entry("vSphere Node with a static name", nil,
+			PlatformInfo{ProviderID: "vsphere://42156a2f", MachineName: "worker-abcde", NodeName: "static-worker-0"}, "VSphere", NodeNameUnknown),
+		Entry("Nutanix Node with a static name", nil,
+			PlatformInfo{ProviderSpecKind: "NutanixMachineProviderConfig", MachineName: "worker-abcde", NodeName: "static-worker-0"}, "Nutanix", NodeNameUnknown),
+		Entry("OpenStack Node named by a hostname hint", nil,
+			PlatformInfo{ProviderID: "openstack:///0123456789ab", MachineName: "worker-abcde", NodeName: "hinted-worker-0"}, "OpenStack", NodeNameUnknown),
+		Entry("Metal3 Machine with a BareMetalHost and without providerID", nil,
+			PlatformInfo{BareMetalHost: "openshift-machine-api/worker-0", PlatformType: "None"}, "BareMetal", NodeNameKept),
+		Entry("Metal3 Machine with a BareMetalHost before the providerSpec Kind", nil,
+			PlatformInfo{BareMetalHost: "openshift-machine-api/worker-0", ProviderSpecKind: "ExampleMachine"}, "BareMetal", NodeNameKept),
+		Entry("Metal3 externally provisioned BareMetalHost", nil,
+			PlatformInfo{ProviderID: "metal3://openshift-machine-api/worker-0/uid", BareMetalHost: "openshift-machine-api/worker-0",
+				BareMetalHostExternallyProvisioned: true}, "BareMetal", NodeNameUnknown),
+	)
+
+	DescribeTable("does not detect unknown platforms",
+		func(platform PlatformInfo) {
+			_, detected := detectPlatform(newPlatformDetectors(defaultBareMetalProviderIDPrefixes), platform)
+			Expect(detected).To(BeFalse())
+		},
+		Entry("no platform info", PlatformInfo{}),
+		Entry("unknown providerID scheme", PlatformInfo{ProviderID: "example:///0123456789ab"}),
+		Entry("providerID without scheme", PlatformInfo{ProviderID: "i-0123456789"}),
+		Entry("unknown providerSpec Kind and platform type", PlatformInfo{ProviderSpecKind: "ExampleMachine", PlatformType: "None"}),
+	)
+})
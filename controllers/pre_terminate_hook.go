@@ -0,0 +1,163 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	commonevents "github.com/medik8s/common/pkg/events"
+
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/medik8s/machine-deletion-remediation/api/v1alpha1"
+)
+
+const (
+	// PreTerminateHookName is the name of the preTerminate lifecycle hook MDR adds to the Machine. On Cluster API
+	// Machines, the hook is the annotation with this name and the pre-terminate.delete.hook.machine.cluster.x-k8s.io
+	// prefix.
+	PreTerminateHookName  = "machine-deletion-remediation"
+	preTerminateHookOwner = "machine-deletion-remediation.medik8s.io"
+	// ReleasePreTerminateHookAnnotation can be set on the remediation by external systems, once done with the drained
+	// Machine, to let MDR remove its preTerminate hook before the hook's ReleaseTimeout expires
+	ReleasePreTerminateHookAnnotation = "machine-deletion-remediation.medik8s.io/release-pre-terminate-hook"
+
+	preTerminateHookAddedReason   = "PreTerminateHookAdded"
+	machineDrainedReason          = "MachineDrained"
+	preTerminateHookRemovedReason = "PreTerminateHookRemoved"
+
+	preTerminateHookAddedMsg          = "added preTerminate hook to machine %s"
+	machineDrainedMsg                 = "machine %s was drained, its termination is held by the preTerminate hook"
+	preTerminateHookRemovedMsg        = "removed preTerminate hook from machine %s"
+	preTerminateHookReleaseTimeoutMsg = "the preTerminate hook was not released within %s, removing it"
+)
+
+// isPreTerminateHookEnabled checks if MDR has to add its preTerminate hook to the Machine before deleting it
+func isPreTerminateHookEnabled(remediation *v1alpha1.MachineDeletionRemediation) bool {
+	return remediation.Spec.PreTerminateHook != nil
+}
+
+// addPreTerminateHook adds MDR's preTerminate hook to the Machine
+func (r *MachineDeletionRemediationReconciler) addPreTerminateHook(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation, backend machineBackend, machine client.Object) error {
+	if backend.hasPreTerminateHook(machine) {
+		return nil
+	}
+	patch := client.MergeFromWithOptions(machine.DeepCopyObject().(client.Object), client.MergeFromWithOptimisticLock{})
+	backend.setPreTerminateHook(machine, true)
+	if err := r.Patch(ctx, machine, patch); err != nil {
+		r.Log.Error(err, "could not add preTerminate hook", "machine", machine.GetName())
+		return err
+	}
+
+	now := metav1.Now()
+	remediation.Status.PreTerminateHook = &v1alpha1.PreTerminateHookStatus{AddedTime: &now}
+	msg := fmt.Sprintf(preTerminateHookAddedMsg, machine.GetName())
+	r.Log.Info(msg)
+	commonevents.NormalEvent(r.Recorder, remediation, preTerminateHookAddedReason, msg)
+	return nil
+}
+
+// handlePreTerminateHook follows the deletion of a Machine held by MDR's preTerminate hook. Once the Machine is
+// drained, MDR notifies it with an event, and removes the hook when it is released. The hook is removed anyway when
+// its ReleaseTimeout expires, even if the Machine is not drained.
+// It returns the time to wait before checking the Machine again.
+func (r *MachineDeletionRemediationReconciler) handlePreTerminateHook(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation, backend machineBackend, machine client.Object) (time.Duration, error) {
+	pollInterval := r.getPollInterval(remediation)
+	expired, remaining := isPreTerminateHookReleaseTimeoutExpired(remediation)
+	if expired {
+		r.Log.Info(fmt.Sprintf(preTerminateHookReleaseTimeoutMsg, remediation.Spec.PreTerminateHook.ReleaseTimeout.Duration), "machine", machine.GetName())
+		return pollInterval, r.removePreTerminateHook(ctx, remediation, backend, machine)
+	}
+	requeueAfter := pollInterval
+	if remaining > 0 && remaining < pollInterval {
+		requeueAfter = remaining
+	}
+
+	if !backend.isDrained(machine) {
+		r.Log.Info("waiting for the machine to be drained before running the preTerminate hook", "machine", machine.GetName())
+		return requeueAfter, nil
+	}
+
+	if remediation.Status.PreTerminateHook == nil {
+		remediation.Status.PreTerminateHook = &v1alpha1.PreTerminateHookStatus{}
+	}
+	status := remediation.Status.PreTerminateHook
+	if status.MachineDrainedTime == nil {
+		now := metav1.Now()
+		status.MachineDrainedTime = &now
+		msg := fmt.Sprintf(machineDrainedMsg, machine.GetName())
+		r.Log.Info(msg)
+		commonevents.NormalEvent(r.Recorder, remediation, machineDrainedReason, msg)
+	}
+
+	if !isPreTerminateHookReleased(remediation) {
+		r.Log.Info("waiting for the preTerminate hook to be released", "machine", machine.GetName(), "annotation", ReleasePreTerminateHookAnnotation)
+		return requeueAfter, nil
+	}
+
+	return pollInterval, r.removePreTerminateHook(ctx, remediation, backend, machine)
+}
+
+// isPreTerminateHookReleased checks if MDR can remove its preTerminate hook from the drained Machine, either because
+// the hook has no ReleaseTimeout or because it was released with the ReleasePreTerminateHookAnnotation
+func isPreTerminateHookReleased(remediation *v1alpha1.MachineDeletionRemediation) bool {
+	if spec := remediation.Spec.PreTerminateHook; spec == nil || spec.ReleaseTimeout == nil {
+		return true
+	}
+	_, released := remediation.GetAnnotations()[ReleasePreTerminateHookAnnotation]
+	return released
+}
+
+// isPreTerminateHookReleaseTimeoutExpired checks if the hook's ReleaseTimeout, measured from the time the hook was
+// added, expired. It also returns the time left before it expires.
+func isPreTerminateHookReleaseTimeoutExpired(remediation *v1alpha1.MachineDeletionRemediation) (bool, time.Duration) {
+	spec, status := remediation.Spec.PreTerminateHook, remediation.Status.PreTerminateHook
+	if spec == nil || spec.ReleaseTimeout == nil || status == nil || status.AddedTime == nil {
+		return false, 0
+	}
+	remaining := spec.ReleaseTimeout.Duration - time.Since(status.AddedTime.Time)
+	return remaining <= 0, remaining
+}
+
+// removePreTerminateHook removes MDR's preTerminate hook from the Machine, allowing its termination
+func (r *MachineDeletionRemediationReconciler) removePreTerminateHook(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation, backend machineBackend, machine client.Object) error {
+	patch := client.MergeFromWithOptions(machine.DeepCopyObject().(client.Object), client.MergeFromWithOptimisticLock{})
+	if !backend.setPreTerminateHook(machine, false) {
+		return nil
+	}
+	if err := r.Patch(ctx, machine, patch); err != nil {
+		if apiErrors.IsNotFound(err) {
+			return nil
+		}
+		r.Log.Error(err, "could not remove preTerminate hook", "machine", machine.GetName())
+		return err
+	}
+
+	if remediation.Status.PreTerminateHook == nil {
+		remediation.Status.PreTerminateHook = &v1alpha1.PreTerminateHookStatus{}
+	}
+	now := metav1.Now()
+	remediation.Status.PreTerminateHook.RemovedTime = &now
+	msg := fmt.Sprintf(preTerminateHookRemovedMsg, machine.GetName())
+	r.Log.Info(msg)
+	commonevents.NormalEvent(r.Recorder, remediation, preTerminateHookRemovedReason, msg)
+	return nil
+}
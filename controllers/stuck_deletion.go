@@ -0,0 +1,232 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	commonevents "github.com/medik8s/common/pkg/events"
+
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/medik8s/machine-deletion-remediation/api/v1alpha1"
+)
+
+const (
+	machineDeletionEscalatedReason = "MachineDeletionEscalated"
+
+	machineDeletionStuckMsg     = "deletion of machine %s did not complete within %s"
+	machineDeletionEscalatedMsg = "applied the %s escalation to machine %s"
+	machineDeletionCompletedMsg = "the machine deletion completed"
+)
+
+// isStuckDeletionDetectionEnabled checks if MDR has to detect a stuck Machine deletion
+func isStuckDeletionDetectionEnabled(remediation *v1alpha1.MachineDeletionRemediation) bool {
+	return remediation.Spec.StuckDeletion != nil
+}
+
+// handleStuckDeletion detects a Machine deletion which did not complete within the stuck deletion timeout. Once stuck,
+// what holds the Machine is reported in the remediation's status and MachineDeletionStuck condition, and the
+// escalation steps are applied one at a time, one stuck deletion timeout apart. It returns the time left before the
+// deletion is considered stuck, or before the next escalation step is due.
+func (r *MachineDeletionRemediationReconciler) handleStuckDeletion(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation, backend machineBackend, machine client.Object) (time.Duration, error) {
+	if !isStuckDeletionDetectionEnabled(remediation) {
+		return 0, nil
+	}
+	spec := remediation.Spec.StuckDeletion
+	if remaining := spec.Timeout.Duration - time.Since(machine.GetDeletionTimestamp().Time); remaining > 0 {
+		return remaining, nil
+	}
+
+	status := remediation.Status.StuckDeletion
+	if status == nil {
+		now := metav1.Now()
+		status = &v1alpha1.StuckDeletionStatus{DetectedTime: &now}
+		remediation.Status.StuckDeletion = status
+	}
+	status.Finalizers = machine.GetFinalizers()
+	status.LifecycleHooks = backend.getLifecycleHooks(machine)
+	drainBlockers, err := r.getDrainBlockers(ctx, backend, machine)
+	if err != nil {
+		r.Log.Error(err, "could not get the pods blocking the drain", "machine", machine.GetName())
+		return 0, err
+	}
+	status.DrainBlockers = drainBlockers
+
+	msg := getStuckDeletionMessage(machine, spec.Timeout.Duration, status)
+	stuck := meta.IsStatusConditionTrue(remediation.Status.Conditions, v1alpha1.MachineDeletionStuckConditionType)
+	setMachineDeletionStuckCondition(remediation, metav1.ConditionTrue, v1alpha1.MachineDeletionTimedOutReason, msg)
+	if !stuck {
+		r.Log.Info(msg)
+		commonevents.WarningEvent(r.Recorder, remediation, v1alpha1.MachineDeletionStuckConditionType, msg)
+	}
+
+	escalation, remaining := getNextDeletionEscalation(spec, status)
+	if escalation == "" || remaining > 0 {
+		return remaining, nil
+	}
+	if err := r.applyDeletionEscalation(ctx, remediation, backend, machine, escalation); err != nil {
+		r.Log.Error(err, "could not apply escalation", "escalation", escalation, "machine", machine.GetName())
+		return 0, err
+	}
+	now := metav1.Now()
+	status.Escalations = append(status.Escalations, escalation)
+	status.LastEscalationTime = &now
+	msg = fmt.Sprintf(machineDeletionEscalatedMsg, escalation, machine.GetName())
+	r.Log.Info(msg)
+	commonevents.NormalEvent(r.Recorder, remediation, machineDeletionEscalatedReason, msg)
+
+	// the next escalation, if any, waits for another stuck deletion timeout
+	_, remaining = getNextDeletionEscalation(spec, status)
+	return remaining, nil
+}
+
+// getNextDeletionEscalation returns the first escalation step not applied yet, and the time left before it is due.
+// The first step is due as soon as the deletion is stuck, each of the next ones one stuck deletion timeout after the
+// previous one was applied.
+func getNextDeletionEscalation(spec *v1alpha1.StuckDeletionSpec, status *v1alpha1.StuckDeletionStatus) (v1alpha1.DeletionEscalation, time.Duration) {
+	for _, escalation := range spec.Escalations {
+		if slices.Contains(status.Escalations, escalation) {
+			continue
+		}
+		if status.LastEscalationTime == nil {
+			return escalation, 0
+		}
+		return escalation, spec.Timeout.Duration - time.Since(status.LastEscalationTime.Time)
+	}
+	return "", 0
+}
+
+// completeStuckDeletion sets the MachineDeletionStuck condition to False once the stuck Machine is gone
+func completeStuckDeletion(remediation *v1alpha1.MachineDeletionRemediation) {
+	if !meta.IsStatusConditionTrue(remediation.Status.Conditions, v1alpha1.MachineDeletionStuckConditionType) {
+		return
+	}
+	setMachineDeletionStuckCondition(remediation, metav1.ConditionFalse, v1alpha1.MachineDeletionCompletedReason, machineDeletionCompletedMsg)
+}
+
+func setMachineDeletionStuckCondition(remediation *v1alpha1.MachineDeletionRemediation, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&remediation.Status.Conditions, metav1.Condition{
+		Type:    v1alpha1.MachineDeletionStuckConditionType,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+}
+
+// getStuckDeletionMessage describes what holds the Machine whose deletion is stuck
+func getStuckDeletionMessage(machine client.Object, timeout time.Duration, status *v1alpha1.StuckDeletionStatus) string {
+	msg := fmt.Sprintf(machineDeletionStuckMsg, machine.GetName(), timeout)
+	var blockers []string
+	if len(status.Finalizers) > 0 {
+		blockers = append(blockers, "finalizers: "+strings.Join(status.Finalizers, ", "))
+	}
+	if len(status.LifecycleHooks) > 0 {
+		blockers = append(blockers, "lifecycle hooks: "+strings.Join(status.LifecycleHooks, ", "))
+	}
+	if len(status.DrainBlockers) > 0 {
+		blockers = append(blockers, "pods blocking the drain: "+strings.Join(status.DrainBlockers, ", "))
+	}
+	if len(blockers) > 0 {
+		msg += "; " + strings.Join(blockers, "; ")
+	}
+	return msg
+}
+
+// getDrainBlockers returns the Pods, as namespace/name, whose eviction from the Machine's Node is blocked by a
+// PodDisruptionBudget, while the Node is not drained yet
+func (r *MachineDeletionRemediationReconciler) getDrainBlockers(ctx context.Context, backend machineBackend, machine client.Object) ([]string, error) {
+	if backend.isDrained(machine) {
+		return nil, nil
+	}
+	node, err := r.getMachineNode(ctx, backend, machine)
+	if err != nil || node == nil {
+		return nil, err
+	}
+	pods, err := r.getPodsToEvict(ctx, node)
+	if err != nil {
+		return nil, err
+	}
+
+	budgets := make(map[string][]policyv1.PodDisruptionBudget)
+	var blockers []string
+	for i := range pods {
+		pod := &pods[i]
+		if !pod.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+		if _, listed := budgets[pod.Namespace]; !listed {
+			list := &policyv1.PodDisruptionBudgetList{}
+			if err := r.List(ctx, list, client.InNamespace(pod.Namespace)); err != nil && !apiErrors.IsNotFound(err) {
+				return nil, err
+			}
+			budgets[pod.Namespace] = list.Items
+		}
+		if isEvictionBlocked(pod, budgets[pod.Namespace]) {
+			blockers = append(blockers, client.ObjectKeyFromObject(pod).String())
+		}
+	}
+	return blockers, nil
+}
+
+// isEvictionBlocked checks if any of the PodDisruptionBudgets selecting the Pod allows no disruption
+func isEvictionBlocked(pod *v1.Pod, budgets []policyv1.PodDisruptionBudget) bool {
+	for i := range budgets {
+		budget := &budgets[i]
+		selector, err := metav1.LabelSelectorAsSelector(budget.Spec.Selector)
+		if err != nil || selector.Empty() {
+			continue
+		}
+		if selector.Matches(labels.Set(pod.Labels)) && budget.Status.DisruptionsAllowed <= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// applyDeletionEscalation applies the escalation step to the Machine whose deletion is stuck
+func (r *MachineDeletionRemediationReconciler) applyDeletionEscalation(ctx context.Context, remediation *v1alpha1.MachineDeletionRemediation, backend machineBackend, machine client.Object, escalation v1alpha1.DeletionEscalation) error {
+	switch escalation {
+	case v1alpha1.DeletionEscalationExcludeNodeDraining:
+		annotation := backend.excludeNodeDrainingAnnotation()
+		if _, exists := machine.GetAnnotations()[annotation]; exists {
+			return nil
+		}
+		patch := client.MergeFrom(machine.DeepCopyObject().(client.Object))
+		annotations := machine.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[annotation] = ""
+		machine.SetAnnotations(annotations)
+		return r.Patch(ctx, machine, patch)
+	case v1alpha1.DeletionEscalationRemovePreTerminateHook:
+		return r.removePreTerminateHook(ctx, remediation, backend, machine)
+	default:
+		return fmt.Errorf("unknown escalation %s", escalation)
+	}
+}
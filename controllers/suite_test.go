@@ -34,6 +34,7 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
+	configv1 "github.com/openshift/api/config/v1"
 	machinev1 "github.com/openshift/api/machine/v1"
 	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
 
@@ -52,8 +53,12 @@ var (
 	cancel       context.CancelFunc
 	plogs        *peekLogger
 	fakeRecorder *record.FakeRecorder
+	// operatorConfig is shared by the controllers, as in the operator
+	operatorConfig *OperatorConfig
 )
 
+const operatorNamespace = "default"
+
 // peekLogger allows to inspect operator's log for testing purpose.
 type peekLogger struct {
 	logs []string
@@ -85,6 +90,14 @@ func (p *peekLogger) Clear() {
 type customClient struct {
 	client.Client
 	onDeleteError error
+	onCreateError error
+}
+
+func (c *customClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if c.onCreateError != nil {
+		return c.onCreateError
+	}
+	return c.Client.Create(ctx, obj, opts...)
 }
 
 func (c *customClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
@@ -110,6 +123,11 @@ var _ = BeforeSuite(func() {
 			filepath.Join("..", "config", "crd", "bases"),
 			filepath.Join("..", "vendor", "github.com", "openshift", "api", "machine", "v1"),
 			filepath.Join("..", "vendor", "github.com", "openshift", "api", "machine", "v1beta1"),
+			filepath.Join("testdata", "crds", "cluster-api"),
+			filepath.Join("testdata", "crds", "custom-owner"),
+			filepath.Join("testdata", "crds", "openshift-config"),
+			filepath.Join("testdata", "crds", "metal3"),
+			filepath.Join("testdata", "crds", "fallback-remediator"),
 		},
 		ErrorIfCRDPathMissing: true,
 	}
@@ -137,12 +155,28 @@ var _ = BeforeSuite(func() {
 
 	cclient = customClient{Client: k8sClient}
 
-	fakeRecorder = record.NewFakeRecorder(30)
+	fakeRecorder = record.NewFakeRecorder(100)
+
+	operatorConfig = NewOperatorConfig()
+	err = (&MachineDeletionRemediationConfigReconciler{
+		Client:    k8sClient,
+		Log:       ctrl.Log.WithName("controllers").WithName("machine-deletion-config-controller"),
+		Namespace: operatorNamespace,
+		Config:    operatorConfig,
+	}).SetupWithManager(k8sManager)
+	Expect(err).ToNot(HaveOccurred())
 
 	err = (&MachineDeletionRemediationReconciler{
 		Client:   &cclient,
 		Log:      ctrl.Log.WithName("controllers").WithName("machine-deletion-controller"),
 		Recorder: fakeRecorder,
+		Config:   operatorConfig,
+	}).SetupWithManager(k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
+	err = (&MachineDeletionRemediationTemplateReconciler{
+		Client: k8sClient,
+		Log:    ctrl.Log.WithName("controllers").WithName("machine-deletion-template-controller"),
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
@@ -153,6 +187,7 @@ var _ = BeforeSuite(func() {
 		Expect(err).ToNot(HaveOccurred())
 	}()
 
+	Expect(configv1.AddToScheme(cclient.Scheme())).ToNot(HaveOccurred())
 	Expect(machinev1.AddToScheme(cclient.Scheme())).ToNot(HaveOccurred())
 	Expect(machinev1beta1.AddToScheme(cclient.Scheme())).ToNot(HaveOccurred())
 })
@@ -17,26 +17,34 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
 	"fmt"
 	"os"
 	"runtime"
+	// embed the time zone database, used by the maintenance windows
+	_ "time/tzdata"
 
 	"go.uber.org/zap/zapcore"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	pkgruntime "k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
+	configv1 "github.com/openshift/api/config/v1"
 	machinev1 "github.com/openshift/api/machine/v1"
 	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
 
@@ -46,6 +54,15 @@ import (
 	//+kubebuilder:scaffold:imports
 )
 
+const (
+	// deploymentNamespaceEnv is set to the operator's namespace by the Deployment
+	deploymentNamespaceEnv = "DEPLOYMENT_NAMESPACE"
+	// deploymentServiceAccountEnv is set to the operator's service account by the Deployment
+	deploymentServiceAccountEnv = "DEPLOYMENT_SERVICE_ACCOUNT"
+	// enableWebhooksEnv can be set to "false" to run the operator without webhooks, e.g. locally
+	enableWebhooksEnv = "ENABLE_WEBHOOKS"
+)
+
 var (
 	scheme   = pkgruntime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
@@ -54,6 +71,7 @@ var (
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(appv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(configv1.Install(scheme))
 	utilruntime.Must(machinev1.Install(scheme))
 	utilruntime.Must(machinev1beta1.Install(scheme))
 	//+kubebuilder:scaffold:scheme
@@ -63,11 +81,15 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var maxInFlight string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&maxInFlight, "max-in-flight", "", "Deprecated: use the maxInFlight field of the MachineDeletionRemediationConfig instead, "+
+		"which takes precedence. The maximum number of Machines being deleted at the same time, either an absolute number or "+
+		"a percentage of the cluster's Nodes (e.g. 10%). There is no limit if not set.")
 	opts := zap.Options{
 		Development: true,
 		TimeEncoder: zapcore.RFC3339NanoTimeEncoder,
@@ -92,27 +114,107 @@ func main() {
 		TLSOpts:     []func(*tls.Config){disableHTTP2},
 	}
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	namespace, err := getDeploymentNamespace()
+	if err != nil {
+		setupLog.Error(err, "unable to get the operator namespace")
+		os.Exit(1)
+	}
+
+	parsedMaxInFlight, err := controllers.ParseMaxInFlight(maxInFlight)
+	if err != nil {
+		setupLog.Error(err, "invalid max-in-flight flag")
+		os.Exit(1)
+	}
+	if parsedMaxInFlight != nil {
+		setupLog.Info("the max-in-flight flag is deprecated, set the maxInFlight field of the operator configuration instead")
+	}
+
+	restConfig := ctrl.GetConfigOrDie()
+	configCR, err := getOperatorConfig(restConfig, namespace)
+	if err != nil {
+		setupLog.Error(err, "unable to get the operator configuration")
+		os.Exit(1)
+	}
+	// the configuration is loaded before the controllers start, so that its restrictions apply from the first
+	// reconciliation
+	operatorConfig := controllers.NewOperatorConfig()
+	operatorConfig.Load(configCR)
+
+	webhookServer := webhook.NewServer(webhook.Options{
+		TLSOpts: []func(*tls.Config){disableHTTP2},
+	})
+
+	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
 		Scheme:                 scheme,
 		Metrics:                metricsOpts,
+		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "285d4098.example.com",
+		LeaderElectionID:       controllers.GetLeaderElectionID(configCR),
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
-	if err = (&controllers.MachineDeletionRemediationReconciler{
-		Client:   mgr.GetClient(),
-		Log:      ctrl.Log.WithName("controllers").WithName("MachineDeletionRemediation"),
-		Scheme:   mgr.GetScheme(),
-		Recorder: mgr.GetEventRecorderFor("MachineDeletionRemediation"),
+	if err = (&controllers.MachineDeletionRemediationConfigReconciler{
+		Client:    mgr.GetClient(),
+		Log:       ctrl.Log.WithName("controllers").WithName("MachineDeletionRemediationConfig"),
+		Scheme:    mgr.GetScheme(),
+		Namespace: namespace,
+		Config:    operatorConfig,
 	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "MachineDeletionRemediationConfig")
+		os.Exit(1)
+	}
+	remediationReconciler := &controllers.MachineDeletionRemediationReconciler{
+		Client:      mgr.GetClient(),
+		Log:         ctrl.Log.WithName("controllers").WithName("MachineDeletionRemediation"),
+		Scheme:      mgr.GetScheme(),
+		Recorder:    mgr.GetEventRecorderFor("MachineDeletionRemediation"),
+		Config:      operatorConfig,
+		MaxInFlight: parsedMaxInFlight,
+	}
+	if err = remediationReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "MachineDeletionRemediation")
 		os.Exit(1)
 	}
+	if serviceAccount := os.Getenv(deploymentServiceAccountEnv); serviceAccount != "" {
+		if err = (&controllers.FallbackRemediationBinder{
+			Client:         mgr.GetClient(),
+			Reader:         mgr.GetAPIReader(),
+			Log:            ctrl.Log.WithName("controllers").WithName("FallbackRemediationBinder"),
+			Namespace:      namespace,
+			ServiceAccount: serviceAccount,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to bind the fallback remediation ClusterRole")
+			os.Exit(1)
+		}
+	} else {
+		setupLog.Info("the operator's service account is not known, not binding the fallback remediation ClusterRole", "env", deploymentServiceAccountEnv)
+	}
+	if err = (&controllers.MachineDeletionRemediationTemplateReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("MachineDeletionRemediationTemplate"),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "MachineDeletionRemediationTemplate")
+		os.Exit(1)
+	}
+	if os.Getenv(enableWebhooksEnv) != "false" {
+		if err = (&appv1alpha1.MachineDeletionRemediation{}).SetupWebhookWithManager(mgr, remediationReconciler); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "MachineDeletionRemediation")
+			os.Exit(1)
+		}
+		if err = (&appv1alpha1.MachineDeletionRemediationTemplate{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "MachineDeletionRemediationTemplate")
+			os.Exit(1)
+		}
+		if err = (&appv1alpha1.MachineDeletionRemediationConfig{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "MachineDeletionRemediationConfig")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -131,6 +233,33 @@ func main() {
 	}
 }
 
+// getDeploymentNamespace returns the namespace the operator is deployed in
+func getDeploymentNamespace() (string, error) {
+	namespace, found := os.LookupEnv(deploymentNamespaceEnv)
+	if !found || namespace == "" {
+		return "", fmt.Errorf("%s environment variable is not set", deploymentNamespaceEnv)
+	}
+	return namespace, nil
+}
+
+// getOperatorConfig reads the MachineDeletionRemediationConfig, before the manager and its cache are started. It returns
+// nil if it does not exist.
+func getOperatorConfig(restConfig *rest.Config, namespace string) (*appv1alpha1.MachineDeletionRemediationConfig, error) {
+	c, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, err
+	}
+	config := &appv1alpha1.MachineDeletionRemediationConfig{}
+	key := client.ObjectKey{Name: appv1alpha1.ConfigCRName, Namespace: namespace}
+	if err := c.Get(context.Background(), key, config); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		return nil, nil
+	}
+	return config, nil
+}
+
 func printVersion() {
 	setupLog.Info(fmt.Sprintf("Go Version: %s", runtime.Version()))
 	setupLog.Info(fmt.Sprintf("Go OS/Arch: %s/%s", runtime.GOOS, runtime.GOARCH))